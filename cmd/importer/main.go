@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/azhengyongqin/asynq-hub/sdk"
+)
+
+// importer 从 NDJSON 文件导入任务（回填、迁移旧队列中的任务）。
+//
+//	go run ./cmd/importer -server http://localhost:28080 -file tasks.ndjson -report report.ndjson
+//
+// 每行一个任务定义（worker_name、queue、priority、payload、run_at、task_id 等，task_id 必填）。
+// 中断后使用相同文件重新执行即可：已导入的 task_id 会被跳过。
+func main() {
+	server := flag.String("server", envOr("ASYNQ_HUB_URL", "http://localhost:28080"), "控制面地址")
+	file := flag.String("file", "-", "NDJSON 文件路径，- 表示标准输入")
+	report := flag.String("report", "", "逐行结果输出文件（NDJSON），为空时只输出失败行")
+	flag.Parse()
+
+	var in io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			log.Fatalf("打开文件失败: %v", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var out *json.Encoder
+	if *report != "" {
+		f, err := os.Create(*report)
+		if err != nil {
+			log.Fatalf("创建结果文件失败: %v", err)
+		}
+		defer f.Close()
+		out = json.NewEncoder(f)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	counts := make(map[string]int, 3)
+	err := sdk.NewClient(*server).ImportTasks(ctx, in, func(r sdk.ImportTaskResult) error {
+		counts[r.Status]++
+		if r.Status == sdk.ImportStatusFailed {
+			log.Printf("第 %d 行导入失败 task_id=%s: %s", r.Line, r.TaskID, r.Error)
+		}
+		if out != nil {
+			return out.Encode(r)
+		}
+		return nil
+	})
+
+	fmt.Printf("created=%d skipped=%d failed=%d\n",
+		counts[sdk.ImportStatusCreated], counts[sdk.ImportStatusSkipped], counts[sdk.ImportStatusFailed])
+	if err != nil {
+		log.Fatalf("导入中断（可重新执行以继续）: %v", err)
+	}
+	if counts[sdk.ImportStatusFailed] > 0 {
+		os.Exit(1)
+	}
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -13,13 +13,22 @@ import (
 	"github.com/hibiken/asynq"
 
 	_ "github.com/azhengyongqin/asynq-hub/docs" // Swagger docs
+	"github.com/azhengyongqin/asynq-hub/internal/bulkjob"
+	"github.com/azhengyongqin/asynq-hub/internal/cache"
 	"github.com/azhengyongqin/asynq-hub/internal/config"
 	"github.com/azhengyongqin/asynq-hub/internal/healthcheck"
 	"github.com/azhengyongqin/asynq-hub/internal/logger"
+	"github.com/azhengyongqin/asynq-hub/internal/middleware"
+	"github.com/azhengyongqin/asynq-hub/internal/outbox"
 	asynqx "github.com/azhengyongqin/asynq-hub/internal/queue"
+	"github.com/azhengyongqin/asynq-hub/internal/reconciler"
 	"github.com/azhengyongqin/asynq-hub/internal/repository"
+	"github.com/azhengyongqin/asynq-hub/internal/retention"
+	"github.com/azhengyongqin/asynq-hub/internal/scheduler"
 	httpserver "github.com/azhengyongqin/asynq-hub/internal/server"
+	"github.com/azhengyongqin/asynq-hub/internal/server/handler"
 	"github.com/azhengyongqin/asynq-hub/internal/storage/postgres"
+	"github.com/azhengyongqin/asynq-hub/internal/watchdog"
 	workers "github.com/azhengyongqin/asynq-hub/internal/worker"
 )
 
@@ -77,8 +86,11 @@ func main() {
 	workerStore := workers.NewStore()
 
 	var (
-		workerRepo *repository.WorkerRepo
-		taskRepo   *repository.TaskRepo
+		workerRepo   *repository.WorkerRepo
+		taskRepo     *repository.TaskRepo
+		workflowRepo *repository.WorkflowRepo
+		scheduleRepo *repository.ScheduleRepo
+		bulkJobRepo  *repository.BulkJobRepo
 	)
 
 	// 使用配置的连接池参数
@@ -97,6 +109,9 @@ func main() {
 
 	workerRepo = repository.NewWorkerRepo(db.DB)
 	taskRepo = repository.NewTaskRepo(db.DB)
+	workflowRepo = repository.NewWorkflowRepo(db.DB)
+	scheduleRepo = repository.NewScheduleRepo(db.DB)
+	bulkJobRepo = repository.NewBulkJobRepo(db.DB)
 
 	// 加载已注册的 workers
 	cfgs, err := workerRepo.List(context.Background())
@@ -137,18 +152,113 @@ func main() {
 	asynqClient := asynq.NewClient(redisOpt)
 	defer asynqClient.Close()
 
+	// Asynq inspector：用于取消/删除 Redis 中的任务
+	asynqInspector := asynq.NewInspector(redisOpt)
+	defer asynqInspector.Close()
+
+	// 幂等记录存储：Redis 不可用时不启用 Idempotency-Key
+	var idempotencyStore middleware.IdempotencyStore
+	redisCache, err := cache.NewRedisCache(redisAddr)
+	if err != nil {
+		logger.L.Warn().Err(err).Msg("连接 Redis 缓存失败，Idempotency-Key 将不生效")
+	} else {
+		defer redisCache.Close()
+		idempotencyStore = redisCache
+	}
+
+	// TaskHandler 由 HTTP 路由与周期任务调度器共用
+	taskHandler := handler.NewTaskHandler(asynqClient, asynqInspector, taskRepo, workerRepo, workflowRepo, workerStore)
+
+	// 周期任务调度器：触发时与 POST /tasks 走同一条创建路径
+	if cfg.Scheduler.Enabled {
+		sched, err := scheduler.New(redisOpt, scheduleRepo, taskHandler, cfg.Scheduler.SyncInterval)
+		if err != nil {
+			logger.L.Fatal().Err(err).Msg("创建周期任务调度器失败")
+		}
+		if err := sched.Start(); err != nil {
+			logger.L.Fatal().Err(err).Msg("启动周期任务调度器失败")
+		}
+		defer sched.Shutdown()
+		logger.L.Info().Dur("sync_interval", cfg.Scheduler.SyncInterval).Msg("周期任务调度器已启动")
+	}
+
+	// 任务历史保留策略：始终维护执行尝试月分区，启用时按状态/worker 分批清理过期任务
+	retentionJob := retention.New(taskRepo, cfg.Retention)
+	retentionJob.Start()
+	defer retentionJob.Stop()
+	logger.L.Info().
+		Dur("interval", cfg.Retention.Interval).
+		Bool("purge_enabled", cfg.Retention.Enabled).
+		Bool("archive", cfg.Retention.Archive).
+		Dur("attempt_age", cfg.Retention.AttemptAge).
+		Msg("任务保留策略已启动")
+
+	// Postgres/Redis 状态对账：按 Redis 中的实际状态修正未结束任务的状态
+	if cfg.Reconcile.Enabled {
+		reconcileJob := reconciler.New(taskRepo, asynqInspector, taskHandler, cfg.Reconcile)
+		reconcileJob.Start()
+		defer reconcileJob.Stop()
+		logger.L.Info().
+			Dur("interval", cfg.Reconcile.Interval).
+			Dur("min_age", cfg.Reconcile.MinAge).
+			Msg("任务状态对账已启动")
+	}
+
+	// 失联执行检测：处理 worker 退出后一直停留在 running 的任务
+	if cfg.Watchdog.Enabled {
+		watchdogJob := watchdog.New(taskRepo, asynqInspector, taskHandler, cfg.Watchdog)
+		watchdogJob.Start()
+		defer watchdogJob.Stop()
+		logger.L.Info().
+			Dur("interval", cfg.Watchdog.Interval).
+			Dur("grace", cfg.Watchdog.Grace).
+			Dur("heartbeat_timeout", cfg.Watchdog.HeartbeatTimeout).
+			Bool("requeue", cfg.Watchdog.Requeue).
+			Msg("失联执行检测已启动")
+	}
+
+	// 任务出箱：任务记录与出箱记录同事务写入，由投递协程入队到 asynq
+	if cfg.Outbox.Enabled {
+		taskHandler.SetOutboxEnabled(true)
+		relay := outbox.New(taskRepo, taskHandler, cfg.Outbox)
+		relay.Start()
+		defer relay.Stop()
+		logger.L.Info().
+			Dur("poll_interval", cfg.Outbox.PollInterval).
+			Int("batch_size", cfg.Outbox.BatchSize).
+			Msg("任务出箱投递已启动")
+	}
+
+	// 批量操作：在后台分批执行 POST /bulk-jobs 创建的任务，多副本通过行锁抢占
+	if cfg.BulkJob.Enabled {
+		bulkRunner := bulkjob.New(bulkJobRepo, taskRepo, taskHandler, cfg.BulkJob)
+		bulkRunner.Start()
+		defer bulkRunner.Stop()
+		logger.L.Info().
+			Dur("poll_interval", cfg.BulkJob.PollInterval).
+			Int("batch_size", cfg.BulkJob.BatchSize).
+			Msg("批量操作执行器已启动")
+	}
+
 	// 创建健康检查器
 	healthChecker := healthcheck.NewHealthChecker(db.DB, asynqClient, redisAddr)
 
 	httpSrv := &http.Server{
 		Addr: httpAddr,
 		Handler: httpserver.NewRouter(httpserver.Deps{
-			WorkerStore:   workerStore,
-			AsynqClient:   asynqClient,
-			WorkerRepo:    workerRepo,
-			TaskRepo:      taskRepo,
-			HealthChecker: healthChecker,
-			WebFS:         &WebFS,
+			WorkerStore:      workerStore,
+			AsynqClient:      asynqClient,
+			AsynqInspector:   asynqInspector,
+			WorkerRepo:       workerRepo,
+			TaskRepo:         taskRepo,
+			WorkflowRepo:     workflowRepo,
+			ScheduleRepo:     scheduleRepo,
+			BulkJobRepo:      bulkJobRepo,
+			IdempotencyStore: idempotencyStore,
+			IdempotencyTTL:   cfg.Idempotency.TTL,
+			TaskHandler:      taskHandler,
+			HealthChecker:    healthChecker,
+			WebFS:            &WebFS,
 		}),
 		ReadHeaderTimeout: 5 * time.Second,
 	}
@@ -169,5 +279,6 @@ func main() {
 	defer cancel()
 
 	_ = httpSrv.Shutdown(shutdownCtx)
+	taskHandler.Close()
 	logger.L.Info().Msg("服务已优雅关闭")
 }
@@ -205,6 +205,16 @@ const docTemplate = `{
                         "name": "worker_name",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "标签选择器，格式 key=value，可重复（需同时满足）",
+                        "name": "label",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -214,6 +224,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.WorkerStatsResponse"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -253,6 +269,16 @@ const docTemplate = `{
                         "description": "统计小时数",
                         "name": "hours",
                         "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "标签选择器，格式 key=value，可重复（需同时满足）",
+                        "name": "label",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -262,6 +288,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.WorkerTimeSeriesResponse"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
                     "501": {
                         "description": "Not Implemented",
                         "schema": {
@@ -271,29 +303,48 @@ const docTemplate = `{
                 }
             }
         },
-        "/healthz": {
+        "/bulk-jobs": {
             "get": {
-                "description": "服务存活检查，用于 Kubernetes liveness probe",
+                "description": "按创建时间倒序返回批量操作及其进度",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Health"
+                    "BulkJobs"
+                ],
+                "summary": "查询批量操作列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "状态：pending, running, completed, failed, canceled",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "返回数量（最多 200）",
+                        "name": "limit",
+                        "in": "query",
+                        "default": 50
+                    }
                 ],
-                "summary": "Liveness 检查",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/healthcheck.CheckResult"
+                            "$ref": "#/definitions/dto.BulkJobListResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/queues/clear": {
+            },
             "post": {
-                "description": "清空指定 Worker 的队列（删除所有待处理任务）",
+                "description": "按过滤条件对任务批量执行 retry（重放已结束的任务）、cancel（取消未结束的任务）、delete（删除任务及其执行历史）或 change_priority（将尚未执行的任务移动到其他优先级队列）。\n操作在后台按 task.id 分批执行，只处理开始执行时已存在的任务；返回的 job_id 用于查询进度、明细或取消。dry_run 为 true 时只统计会被操作与被跳过的任务。",
                 "consumes": [
                     "application/json"
                 ],
@@ -301,25 +352,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Queues"
+                    "BulkJobs"
                 ],
-                "summary": "清空队列",
+                "summary": "创建批量操作",
                 "parameters": [
                     {
-                        "description": "清空队列请求",
+                        "description": "批量操作",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.ClearQueueRequest"
+                            "$ref": "#/definitions/dto.CreateBulkJobRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/dto.ClearQueueResponse"
+                            "$ref": "#/definitions/dto.BulkJobResponse"
                         }
                     },
                     "400": {
@@ -328,6 +379,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
                     "503": {
                         "description": "Service Unavailable",
                         "schema": {
@@ -337,68 +394,61 @@ const docTemplate = `{
                 }
             }
         },
-        "/queues/clear-dead": {
-            "post": {
-                "description": "清空指定 Worker 的死信队列（已达最大重试次数的失败任务）",
-                "consumes": [
-                    "application/json"
-                ],
+        "/bulk-jobs/{job_id}": {
+            "get": {
+                "description": "返回批量操作的状态与进度（total 为开始执行时匹配的任务数，processed = succeeded + skipped + failed）",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Queues"
+                    "BulkJobs"
                 ],
-                "summary": "清空死信队列",
+                "summary": "获取批量操作进度",
                 "parameters": [
                     {
-                        "description": "清空死信队列请求",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.ClearDeadQueueRequest"
-                        }
+                        "type": "string",
+                        "description": "批量操作 ID",
+                        "name": "job_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.ClearDeadQueueResponse"
+                            "$ref": "#/definitions/dto.BulkJobResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
-                    "503": {
-                        "description": "Service Unavailable",
+                    "501": {
+                        "description": "Not Implemented",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/queues/stats": {
-            "get": {
-                "description": "获取指定 Worker 的所有队列状态统计",
+            },
+            "delete": {
+                "description": "取消等待执行或执行中的批量操作：执行中的批次结束后停止，已处理的任务不回滚",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Queues"
+                    "BulkJobs"
                 ],
-                "summary": "查询队列状态",
+                "summary": "取消批量操作",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Worker 名称",
-                        "name": "worker_name",
-                        "in": "query",
+                        "description": "批量操作 ID",
+                        "name": "job_id",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -406,13 +456,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.QueueStatsResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/dto.ErrorResponse"
+                            "$ref": "#/definitions/dto.BulkJobResponse"
                         }
                     },
                     "404": {
@@ -421,90 +465,59 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
-                    "503": {
-                        "description": "Service Unavailable",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/readyz": {
-            "get": {
-                "description": "服务就绪检查，检查依赖服务（PostgreSQL、Redis）状态",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Health"
-                ],
-                "summary": "Readiness 检查",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/healthcheck.CheckResult"
-                        }
                     },
-                    "503": {
-                        "description": "Service Unavailable",
+                    "501": {
+                        "description": "Not Implemented",
                         "schema": {
-                            "$ref": "#/definitions/healthcheck.CheckResult"
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/tasks": {
+        "/bulk-jobs/{job_id}/items": {
             "get": {
-                "description": "分页查询任务列表，支持多条件过滤",
+                "description": "按处理顺序返回被跳过或处理失败的任务及原因，通过 after_id 翻页",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tasks"
+                    "BulkJobs"
                 ],
-                "summary": "查询任务列表",
+                "summary": "查询批量操作明细",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Worker 名称",
-                        "name": "worker_name",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "任务状态",
-                        "name": "status",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "队列名称",
-                        "name": "queue",
-                        "in": "query"
+                        "description": "批量操作 ID",
+                        "name": "job_id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "integer",
-                        "default": 50,
-                        "description": "每页数量",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "只返回 id 大于该值的明细",
+                        "name": "after_id",
+                        "in": "query",
+                        "default": 0
                     },
                     {
                         "type": "integer",
-                        "default": 0,
-                        "description": "偏移量",
-                        "name": "offset",
-                        "in": "query"
+                        "description": "返回数量（最多 1000）",
+                        "name": "limit",
+                        "in": "query",
+                        "default": 100
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.TaskListResponse"
+                            "$ref": "#/definitions/dto.BulkJobItemListResponse"
                         }
                     },
                     "501": {
@@ -514,9 +527,31 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "服务存活检查，用于 Kubernetes liveness probe",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Liveness 检查",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/healthcheck.CheckResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/queues/clear": {
             "post": {
-                "description": "创建新的异步任务并入队到 Asynq",
+                "description": "清空指定 Worker 的队列（删除所有待处理任务）",
                 "consumes": [
                     "application/json"
                 ],
@@ -524,17 +559,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Tasks"
+                    "Queues"
                 ],
-                "summary": "创建任务",
+                "summary": "清空队列",
                 "parameters": [
                     {
-                        "description": "任务创建请求",
+                        "description": "清空队列请求",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.CreateTaskRequest"
+                            "$ref": "#/definitions/dto.ClearQueueRequest"
                         }
                     }
                 ],
@@ -542,7 +577,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.CreateTaskResponse"
+                            "$ref": "#/definitions/dto.ClearQueueResponse"
                         }
                     },
                     "400": {
@@ -560,9 +595,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tasks/batch-retry": {
+        "/queues/clear-dead": {
             "post": {
-                "description": "批量重试指定条件的失败任务",
+                "description": "清空指定 Worker 的死信队列（已达最大重试次数的失败任务）",
                 "consumes": [
                     "application/json"
                 ],
@@ -570,17 +605,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Tasks"
+                    "Queues"
                 ],
-                "summary": "批量重试失败任务",
+                "summary": "清空死信队列",
                 "parameters": [
                     {
-                        "description": "重试条件",
+                        "description": "清空死信队列请求",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.BatchRetryRequest"
+                            "$ref": "#/definitions/dto.ClearDeadQueueRequest"
                         }
                     }
                 ],
@@ -588,7 +623,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.BatchRetryResponse"
+                            "$ref": "#/definitions/dto.ClearDeadQueueResponse"
                         }
                     },
                     "400": {
@@ -597,8 +632,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
-                    "501": {
-                        "description": "Not Implemented",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -606,22 +641,22 @@ const docTemplate = `{
                 }
             }
         },
-        "/tasks/{task_id}": {
+        "/queues/stats": {
             "get": {
-                "description": "根据 task_id 获取任务详细信息及执行历史",
+                "description": "获取指定 Worker 的所有队列状态统计",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tasks"
+                    "Queues"
                 ],
-                "summary": "获取任务详情",
+                "summary": "查询队列状态",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "任务 ID",
-                        "name": "task_id",
-                        "in": "path",
+                        "description": "Worker 名称",
+                        "name": "worker_name",
+                        "in": "query",
                         "required": true
                     }
                 ],
@@ -629,7 +664,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.TaskResponse"
+                            "$ref": "#/definitions/dto.QueueStatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "404": {
@@ -638,8 +679,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
-                    "501": {
-                        "description": "Not Implemented",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -647,33 +688,1485 @@ const docTemplate = `{
                 }
             }
         },
-        "/tasks/{task_id}/replay": {
-            "post": {
-                "description": "将已完成或失败的任务重新入队执行",
-                "consumes": [
-                    "application/json"
-                ],
+        "/queues/{full_queue}/tasks": {
+            "get": {
+                "description": "分页列出 Redis 中指定完整队列、指定状态下的任务，按任务信封解析出 task_id 与 payload，并附带 Postgres 中对应的任务记录（存在时）",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tasks"
+                    "Queues"
                 ],
-                "summary": "重放任务",
+                "summary": "查询队列中的任务",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "任务 ID",
-                        "name": "task_id",
+                        "description": "完整队列名（worker:队列组:优先级）",
+                        "name": "full_queue",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "重放参数",
-                        "name": "request",
+                        "type": "string",
+                        "description": "状态：pending, active, scheduled, retry, archived, completed",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码（从 1 开始）",
+                        "name": "page",
+                        "in": "query",
+                        "default": 1
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量（最多 100）",
+                        "name": "page_size",
+                        "in": "query",
+                        "default": 20
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.QueueTaskListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "服务就绪检查，检查依赖服务（PostgreSQL、Redis）状态",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Readiness 检查",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/healthcheck.CheckResult"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/healthcheck.CheckResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules": {
+            "get": {
+                "description": "返回全部周期任务，包含最近一次与下一次触发时间",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "查询周期任务列表",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "只返回启用的周期任务",
+                        "name": "enabled",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ScheduleListResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "按 cron 表达式（支持时区）定时创建任务，每次触发都会写入 task 表",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "创建周期任务",
+                "parameters": [
+                    {
+                        "description": "周期任务定义",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ScheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ScheduleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules/{schedule_id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "获取周期任务详情",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "周期任务 ID",
+                        "name": "schedule_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ScheduleResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "整体替换周期任务定义，并重新计算下次触发时间；调度器在下一次同步时生效",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "更新周期任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "周期任务 ID",
+                        "name": "schedule_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "周期任务定义",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ScheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ScheduleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "删除周期任务及其触发历史（已创建的任务不受影响）",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "删除周期任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "周期任务 ID",
+                        "name": "schedule_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules/{schedule_id}/runs": {
+            "get": {
+                "description": "按触发时间倒序返回每次触发创建的 task_id 或失败原因",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "查询周期任务触发历史",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "周期任务 ID",
+                        "name": "schedule_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "返回条数（最大 200）",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ScheduleRunListResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules/{schedule_id}/trigger": {
+            "post": {
+                "description": "手动触发一次，结果记录到触发历史并刷新 last_run_at；距离下一次定时触发不足半个周期时，该次定时触发会被合并",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "立即触发周期任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "周期任务 ID",
+                        "name": "schedule_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/repository.ScheduleRun"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks": {
+            "get": {
+                "description": "查询任务列表，支持按时间范围、优先级、标签、错误信息、payload 过滤及排序。\n按 created_at 排序时使用 (created_at, id) 游标分页：响应中的 next_cursor/prev_cursor 作为 cursor 参数翻页；传 offset 时使用偏移分页。",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "查询任务列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Worker 名称",
+                        "name": "worker_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "任务状态",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "队列名称",
+                        "name": "queue",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "标签选择器，格式 key=value，可重复（需同时满足）",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "优先级：critical, default, low",
+                        "name": "priority",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间下限（含，RFC3339）",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间上限（不含，RFC3339）",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "更新时间下限（含，RFC3339）",
+                        "name": "updated_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "更新时间上限（不含，RFC3339）",
+                        "name": "updated_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "last_error 子串（不区分大小写）",
+                        "name": "error",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "payload 需包含的 JSON 对象（JSONB 包含查询）",
+                        "name": "payload",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "payload 需满足的 jsonpath 谓词，如 $.amount > 100（只支持谓词表达式，语法错误返回 400；$.a ? (@ == 1) 这类路径表达式不会匹配任何任务）",
+                        "name": "payload_path",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序字段：created_at, updated_at, priority",
+                        "name": "sort_by",
+                        "in": "query",
+                        "default": "created_at"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序方向：asc, desc",
+                        "name": "order",
+                        "in": "query",
+                        "default": "desc"
+                    },
+                    {
+                        "type": "string",
+                        "description": "分页游标（上一次响应的 next_cursor 或 prev_cursor）",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "总数：exact（精确计数）, estimate（按查询计划估算）, none（不返回）",
+                        "name": "total",
+                        "in": "query",
+                        "default": "exact"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "每页数量",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "偏移量（与 cursor 互斥）",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TaskListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "创建新的异步任务并入队到 Asynq\n出箱模式（OUTBOX_ENABLED=true）下任务记录与出箱记录在同一事务中写入后即返回（status 为 accepted），由后台投递协程入队；task_id 已有任务记录时返回 409。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "创建任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "幂等键：窗口内重复提交直接返回首次响应",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "任务创建请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateTaskRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateTaskResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/batch": {
+            "post": {
+                "description": "一次提交多个任务，逐条返回结果；worker/队列组只校验一次，数据库记录单次批量写入\n出箱模式下任务记录与出箱记录在同一事务中写入，由后台投递协程入队；task_id 已有任务记录的条目返回错误。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "批量创建任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "幂等键：窗口内重复提交直接返回首次响应",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "批量创建请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.BatchCreateTaskRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BatchCreateTaskResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/batch-retry": {
+            "post": {
+                "description": "批量重试指定条件的失败任务\n已废弃：同步执行且最多处理 1000 个任务，请使用 POST /bulk-jobs（operation=retry）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "批量重试失败任务",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "description": "重试条件",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.BatchRetryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BatchRetryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/export": {
+            "get": {
+                "description": "按与任务列表相同的过滤条件流式导出全部匹配任务（不分页），可附带每个任务的执行尝试。\nCSV 附带执行尝试时每次尝试输出一行；NDJSON 每行一个任务，执行尝试位于 attempts 字段。",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "导出任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "导出格式：csv, ndjson",
+                        "name": "format",
+                        "in": "query",
+                        "default": "csv"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "附带执行尝试",
+                        "name": "include_attempts",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Worker 名称",
+                        "name": "worker_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "任务状态",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "队列名称",
+                        "name": "queue",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "标签选择器，格式 key=value，可重复（需同时满足）",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "优先级：critical, default, low",
+                        "name": "priority",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间下限（含，RFC3339）",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间上限（不含，RFC3339）",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "更新时间下限（含，RFC3339）",
+                        "name": "updated_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "更新时间上限（不含，RFC3339）",
+                        "name": "updated_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "last_error 子串（不区分大小写）",
+                        "name": "error",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "payload 需包含的 JSON 对象（JSONB 包含查询）",
+                        "name": "payload",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "payload 需满足的 jsonpath 谓词，如 $.amount > 100（只支持谓词表达式，语法错误返回 400；$.a ? (@ == 1) 这类路径表达式不会匹配任何任务）",
+                        "name": "payload_path",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序字段：created_at, updated_at, priority",
+                        "name": "sort_by",
+                        "in": "query",
+                        "default": "created_at"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序方向：asc, desc",
+                        "name": "order",
+                        "in": "query",
+                        "default": "desc"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "CSV 或 NDJSON 数据流",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/import": {
+            "post": {
+                "description": "请求体为 NDJSON，每行一个任务定义（字段与创建任务相同，task_id 必填），按与创建任务相同的规则逐行校验并分批入队。\n响应为 NDJSON，按行返回处理结果（created / skipped / failed）。task_id 已存在的行返回 skipped 且不会重复入队，中断的导入可以原样重新提交。\n出箱模式下每批任务记录与出箱记录在同一事务中写入，由后台投递协程入队。",
+                "consumes": [
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "导入任务",
+                "parameters": [
+                    {
+                        "description": "NDJSON 任务定义，每行一个 dto.CreateTaskRequest",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.ImportTaskResult"
+                            }
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{task_id}": {
+            "get": {
+                "description": "根据 task_id 获取任务详细信息、执行历史、最近一次尝试的执行进度及重放链（被重放的原任务与重放产生的任务）",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "获取任务详情",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "任务 ID",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TaskResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{task_id}/attempts": {
+            "get": {
+                "description": "按 (started_at, id) 游标分页查询任务的执行尝试记录（最新在前）",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "查询任务执行历史",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "任务 ID",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "分页游标（上一次响应的 next_cursor 或 prev_cursor）",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "总数：exact（精确计数）, none（不返回）",
+                        "name": "total",
+                        "in": "query",
+                        "default": "exact"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量（最多 200）",
+                        "name": "limit",
+                        "in": "query",
+                        "default": 50
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AttemptListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{task_id}/cancel": {
+            "post": {
+                "description": "删除 pending/scheduled/retry 状态的任务，或中断正在执行的任务，并将任务标记为 canceled",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "取消任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "任务 ID",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "取消参数",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CancelTaskRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CancelTaskResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{task_id}/logs": {
+            "get": {
+                "description": "按写入顺序查询任务执行日志，可按尝试过滤，通过 after_id 翻页",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "查询任务日志",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "任务 ID",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "尝试序号（为空返回所有尝试）",
+                        "name": "attempt",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "只返回 id 大于该值的日志",
+                        "name": "after_id",
+                        "in": "query",
+                        "default": 0
+                    },
+                    {
+                        "type": "integer",
+                        "description": "返回数量（最多 1000）",
+                        "name": "limit",
+                        "in": "query",
+                        "default": 500
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TaskLogListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Worker 分批上报任务执行过程中记录的日志（每批最多 500 行）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "上报任务日志",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "任务 ID",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "日志",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AppendTaskLogsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{task_id}/progress": {
+            "post": {
+                "description": "Worker 在任务执行过程中上报进度，每次尝试只保存最新一条",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "上报任务执行进度",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "任务 ID",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "执行进度",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReportProgressRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{task_id}/replay": {
+            "post": {
+                "description": "将已完成或失败的任务以新的 task_id 重新入队执行，新任务的 replayed_from 指向原任务。\n可修改 payload、优先级、队列组（同一 worker 内）及重试/超时参数，未传的字段沿用原任务。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "重放任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "任务 ID",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "重放参数",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReplayTaskRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReplayTaskResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{task_id}/report-attempt": {
+            "post": {
+                "description": "Worker 上报任务执行的详细状态（running、success、fail、dead、expired）。expired 表示任务到过期时间仍未开始执行，worker 已丢弃",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "上报任务执行状态",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "任务 ID",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "执行状态",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReportAttemptRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{task_id}/result": {
+            "get": {
+                "description": "获取任务结果；wait>0 时长轮询，直到任务结束或等待超时（最长 60 秒）",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "获取任务结果",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "任务 ID",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "最长等待秒数（0-60）",
+                        "name": "wait",
+                        "in": "query",
+                        "default": 0
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TaskResultResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workflows": {
+            "get": {
+                "description": "分页查询工作流，可按状态过滤",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workflows"
+                ],
+                "summary": "查询工作流列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "状态：running/success/failed",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "每页数量",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "偏移量",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WorkflowListResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "提交一组通过 depends_on 组成 DAG 的任务；无依赖的任务立即入队，其余任务在上游全部成功后入队，上游失败时跳过",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workflows"
+                ],
+                "summary": "创建工作流",
+                "parameters": [
+                    {
+                        "description": "工作流创建请求",
+                        "name": "request",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.ReplayTaskRequest"
+                            "$ref": "#/definitions/dto.CreateWorkflowRequest"
                         }
                     }
                 ],
@@ -681,7 +2174,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.ReplayTaskResponse"
+                            "$ref": "#/definitions/dto.WorkflowResponse"
                         }
                     },
                     "400": {
@@ -690,8 +2183,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "501": {
+                        "description": "Not Implemented",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -705,52 +2198,93 @@ const docTemplate = `{
                 }
             }
         },
-        "/tasks/{task_id}/report-attempt": {
-            "post": {
-                "description": "Worker 上报任务执行的详细状态（running、success、fail）",
-                "consumes": [
-                    "application/json"
-                ],
+        "/workflows/{workflow_id}": {
+            "get": {
+                "description": "返回工作流状态、全部任务（含 depends_on）及各状态任务数",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tasks"
+                    "Workflows"
                 ],
-                "summary": "上报任务执行状态",
+                "summary": "获取工作流详情",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "任务 ID",
-                        "name": "task_id",
+                        "description": "工作流 ID",
+                        "name": "workflow_id",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WorkflowResponse"
+                        }
                     },
-                    {
-                        "description": "执行状态",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/dto.ReportAttemptRequest"
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
+                }
+            }
+        },
+        "/workflows/{workflow_id}/replay": {
+            "post": {
+                "description": "以相同的任务与依赖关系创建一个新的工作流（任务 ID 重新生成），原工作流必须已结束",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workflows"
+                ],
+                "summary": "重放工作流",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "工作流 ID",
+                        "name": "workflow_id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.SuccessResponse"
+                            "$ref": "#/definitions/dto.WorkflowResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -760,9 +2294,110 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "dto.AppendTaskLogsRequest": {
+            "type": "object",
+            "required": [
+                "attempt",
+                "lines"
+            ],
+            "properties": {
+                "attempt": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                },
+                "lines": {
+                    "type": "array",
+                    "maxItems": 500,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/dto.TaskLogLine"
+                    }
+                }
+            }
+        },
+        "dto.AttemptListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {},
+                "next_cursor": {
+                    "type": "string"
+                },
+                "prev_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.BatchCreateTaskRequest": {
+            "type": "object",
+            "required": [
+                "items"
+            ],
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/dto.CreateTaskRequest"
+                    }
+                }
+            }
+        },
+        "dto.BatchCreateTaskResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.BatchCreateTaskResult"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer",
+                    "example": 98
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 100
+                }
+            }
+        },
+        "dto.BatchCreateTaskResult": {
+            "type": "object",
+            "properties": {
+                "asynq_task_id": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "index": {
+                    "description": "对应请求 items 中的下标",
+                    "type": "integer",
+                    "example": 0
+                },
+                "task_id": {
+                    "type": "string"
+                }
+            }
+        },
         "dto.BatchRetryRequest": {
             "type": "object",
             "properties": {
+                "labels": {
+                    "description": "标签选择器：需包含全部键值对",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
                 "limit": {
                     "type": "integer",
                     "example": 100
@@ -802,6 +2437,118 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.BulkJobItemListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {}
+            }
+        },
+        "dto.BulkJobListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {}
+            }
+        },
+        "dto.BulkJobResponse": {
+            "type": "object",
+            "properties": {
+                "item": {}
+            }
+        },
+        "dto.BulkTaskFilter": {
+            "type": "object",
+            "properties": {
+                "created_after": {
+                    "description": "创建时间下限（含）",
+                    "type": "string"
+                },
+                "created_before": {
+                    "description": "创建时间上限（不含）",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "last_error 子串（不区分大小写）",
+                    "type": "string",
+                    "example": "timeout"
+                },
+                "labels": {
+                    "description": "标签选择器：需包含全部键值对",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "payload": {
+                    "description": "payload 需包含的 JSON 对象",
+                    "type": "object"
+                },
+                "payload_path": {
+                    "description": "payload 需满足的 jsonpath 谓词（只支持谓词表达式，语法错误返回 400）",
+                    "type": "string",
+                    "example": "$.amount > 100"
+                },
+                "priority": {
+                    "description": "critical/default/low",
+                    "type": "string",
+                    "example": "low"
+                },
+                "queue": {
+                    "description": "完整队列名",
+                    "type": "string",
+                    "example": "my-worker:default:default"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "fail"
+                },
+                "task_ids": {
+                    "description": "只匹配这些 task_id",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_after": {
+                    "description": "更新时间下限（含）",
+                    "type": "string"
+                },
+                "updated_before": {
+                    "description": "更新时间上限（不含）",
+                    "type": "string"
+                },
+                "worker_name": {
+                    "type": "string",
+                    "example": "my-worker"
+                }
+            }
+        },
+        "dto.CancelTaskRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "误提交"
+                }
+            }
+        },
+        "dto.CancelTaskResponse": {
+            "type": "object",
+            "properties": {
+                "redis_state": {
+                    "description": "取消前任务在 asynq 中的状态（not_found 表示已不存在）",
+                    "type": "string",
+                    "example": "pending"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "canceled"
+                },
+                "task_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
         "dto.ClearDeadQueueRequest": {
             "type": "object",
             "required": [
@@ -866,17 +2613,47 @@ const docTemplate = `{
                         "type": "string"
                     }
                 },
-                "status": {
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                },
+                "total_deleted": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "worker_name": {
+                    "type": "string",
+                    "example": "my-worker"
+                }
+            }
+        },
+        "dto.CreateBulkJobRequest": {
+            "type": "object",
+            "required": [
+                "operation"
+            ],
+            "properties": {
+                "dry_run": {
+                    "description": "只统计会被操作与被跳过的任务，不实际执行",
+                    "type": "boolean"
+                },
+                "filter": {
+                    "$ref": "#/definitions/dto.BulkTaskFilter"
+                },
+                "operation": {
+                    "description": "retry/cancel/delete/change_priority",
                     "type": "string",
-                    "example": "ok"
+                    "example": "retry"
                 },
-                "total_deleted": {
-                    "type": "integer",
-                    "example": 100
+                "priority": {
+                    "description": "change_priority 的目标优先级",
+                    "type": "string",
+                    "example": "critical"
                 },
-                "worker_name": {
+                "reason": {
+                    "description": "cancel 的取消原因",
                     "type": "string",
-                    "example": "my-worker"
+                    "example": "bad deploy"
                 }
             }
         },
@@ -889,11 +2666,16 @@ const docTemplate = `{
                 "asynq_task_id": {
                     "type": "string"
                 },
-                "queue": {
+                "priority": {
                     "type": "string",
                     "example": "default"
                 },
+                "queue": {
+                    "type": "string",
+                    "example": "web_crawl"
+                },
                 "status": {
+                    "description": "enqueued：已入队；accepted：出箱模式下已写入数据库，等待投递入队",
                     "type": "string",
                     "example": "pending"
                 },
@@ -910,8 +2692,7 @@ const docTemplate = `{
         "dto.CreateWorkerRequest": {
             "type": "object",
             "required": [
-                "concurrency",
-                "queues",
+                "queue_groups",
                 "worker_name"
             ],
             "properties": {
@@ -919,11 +2700,6 @@ const docTemplate = `{
                     "type": "string",
                     "example": "http://localhost:8080"
                 },
-                "concurrency": {
-                    "type": "integer",
-                    "minimum": 1,
-                    "example": 10
-                },
                 "default_delay": {
                     "type": "integer",
                     "example": 0
@@ -936,10 +2712,11 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 30
                 },
-                "queues": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "integer"
+                "queue_groups": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/dto.QueueGroupRequest"
                     }
                 },
                 "redis_addr": {
@@ -952,6 +2729,9 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.CreateWorkflowRequest": {
+            "type": "object"
+        },
         "dto.ErrorResponse": {
             "type": "object",
             "properties": {
@@ -977,6 +2757,53 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.ImportTaskResult": {
+            "type": "object",
+            "properties": {
+                "asynq_task_id": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "line": {
+                    "description": "对应请求体中的行号（从 1 开始）",
+                    "type": "integer",
+                    "example": 1
+                },
+                "status": {
+                    "description": "created, skipped, failed",
+                    "type": "string",
+                    "example": "created"
+                },
+                "task_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.QueueGroupRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "concurrency": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "name": {
+                    "type": "string",
+                    "example": "web_crawl"
+                },
+                "priorities": {
+                    "description": "可选，默认为 critical=50, default=30, low=10",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
         "dto.QueueStatsResponse": {
             "type": "object",
             "properties": {
@@ -990,11 +2817,97 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.QueueTaskItem": {
+            "type": "object",
+            "properties": {
+                "asynq_task_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "completed_at": {
+                    "type": "string"
+                },
+                "is_orphaned": {
+                    "description": "active 任务的 worker 已失联",
+                    "type": "boolean"
+                },
+                "last_error": {
+                    "type": "string",
+                    "example": "timeout"
+                },
+                "last_failed_at": {
+                    "type": "string"
+                },
+                "max_retry": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "next_process_at": {
+                    "description": "scheduled/retry 任务的下次执行时间",
+                    "type": "string"
+                },
+                "payload": {
+                    "description": "信封中的业务 payload",
+                    "type": "object"
+                },
+                "raw_payload": {
+                    "description": "无法按任务信封解析时的原始 payload",
+                    "type": "string"
+                },
+                "retried": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "state": {
+                    "type": "string",
+                    "example": "retry"
+                },
+                "task": {
+                    "description": "Postgres 中对应的任务记录，不存在时为空"
+                },
+                "task_id": {
+                    "description": "从任务信封解析，非本系统投递的任务为空",
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
+        "dto.QueueTaskListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.QueueTaskItem"
+                    }
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "queue": {
+                    "type": "string",
+                    "example": "my-worker:default:default"
+                },
+                "state": {
+                    "type": "string",
+                    "example": "retry"
+                },
+                "total": {
+                    "description": "该状态下的任务总数",
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
         "dto.RegisterWorkerRequest": {
             "type": "object",
             "required": [
-                "concurrency",
-                "queues",
+                "queue_groups",
                 "worker_name"
             ],
             "properties": {
@@ -1002,11 +2915,6 @@ const docTemplate = `{
                     "type": "string",
                     "example": "http://localhost:8080"
                 },
-                "concurrency": {
-                    "type": "integer",
-                    "minimum": 1,
-                    "example": 10
-                },
                 "default_delay": {
                     "type": "integer",
                     "example": 0
@@ -1019,10 +2927,11 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 30
                 },
-                "queues": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "integer"
+                "queue_groups": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/dto.QueueGroupRequest"
                     }
                 },
                 "redis_addr": {
@@ -1036,13 +2945,7 @@ const docTemplate = `{
             }
         },
         "dto.ReplayTaskRequest": {
-            "type": "object",
-            "properties": {
-                "delay": {
-                    "type": "integer",
-                    "example": 0
-                }
-            }
+            "type": "object"
         },
         "dto.ReplayTaskResponse": {
             "type": "object",
@@ -1051,6 +2954,15 @@ const docTemplate = `{
                     "type": "string",
                     "example": "550e8400-e29b-41d4-a716-446655440000"
                 },
+                "queue": {
+                    "description": "新任务的完整队列名",
+                    "type": "string",
+                    "example": "my-worker:web_crawl:critical"
+                },
+                "replayed_from": {
+                    "type": "string",
+                    "example": "0b8c6d3e-7f2a-4c1b-9e5d-2a6f8b4c1d3e"
+                },
                 "status": {
                     "type": "string",
                     "example": "ok"
@@ -1060,6 +2972,54 @@ const docTemplate = `{
         "dto.ReportAttemptRequest": {
             "type": "object"
         },
+        "dto.ReportProgressRequest": {
+            "type": "object",
+            "required": [
+                "attempt"
+            ],
+            "properties": {
+                "attempt": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                },
+                "message": {
+                    "type": "string",
+                    "maxLength": 1024,
+                    "example": "已导入 4200/10000 行"
+                },
+                "percent": {
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 0,
+                    "example": 42
+                }
+            }
+        },
+        "dto.ScheduleListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {},
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ScheduleRequest": {
+            "type": "object"
+        },
+        "dto.ScheduleResponse": {
+            "type": "object",
+            "properties": {
+                "item": {}
+            }
+        },
+        "dto.ScheduleRunListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {}
+            }
+        },
         "dto.SuccessResponse": {
             "type": "object",
             "properties": {
@@ -1078,17 +3038,67 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "items": {},
+                "next_cursor": {
+                    "description": "为空表示没有下一页",
+                    "type": "string"
+                },
+                "prev_cursor": {
+                    "description": "为空表示没有上一页",
+                    "type": "string"
+                },
                 "total": {
+                    "description": "total=none 时不返回",
                     "type": "integer"
+                },
+                "total_estimated": {
+                    "description": "total=estimate 时为 true（按查询计划估算）",
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.TaskLogLine": {
+            "type": "object",
+            "required": [
+                "level",
+                "message",
+                "time"
+            ],
+            "properties": {
+                "level": {
+                    "type": "string",
+                    "enum": [
+                        "debug",
+                        "info",
+                        "warn",
+                        "error"
+                    ],
+                    "example": "info"
+                },
+                "message": {
+                    "type": "string",
+                    "maxLength": 8192,
+                    "example": "已导入 4200/10000 行"
+                },
+                "time": {
+                    "type": "string"
                 }
             }
         },
+        "dto.TaskLogListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {}
+            }
+        },
         "dto.TaskResponse": {
             "type": "object",
             "properties": {
                 "task": {}
             }
         },
+        "dto.TaskResultResponse": {
+            "type": "object"
+        },
         "dto.WorkerListResponse": {
             "type": "object",
             "properties": {
@@ -1120,6 +3130,29 @@ const docTemplate = `{
                 "timeseries": {}
             }
         },
+        "dto.WorkflowListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {},
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.WorkflowResponse": {
+            "type": "object",
+            "properties": {
+                "item": {},
+                "progress": {
+                    "description": "各状态的任务数",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "tasks": {}
+            }
+        },
         "healthcheck.CheckResult": {
             "type": "object",
             "properties": {
@@ -1138,15 +3171,33 @@ const docTemplate = `{
                 }
             }
         },
+        "repository.ScheduleRun": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "fired_at": {
+                    "type": "string"
+                },
+                "schedule_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "enqueued/failed",
+                    "type": "string"
+                },
+                "task_id": {
+                    "type": "string"
+                }
+            }
+        },
         "workers.Config": {
             "type": "object",
             "properties": {
                 "base_url": {
                     "type": "string"
                 },
-                "concurrency": {
-                    "type": "integer"
-                },
                 "default_delay": {
                     "description": "seconds",
                     "type": "integer"
@@ -1164,11 +3215,11 @@ const docTemplate = `{
                 "last_heartbeat_at": {
                     "type": "string"
                 },
-                "queues": {
-                    "description": "queue_name -\u003e weight",
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "integer"
+                "queue_groups": {
+                    "description": "队列组配置",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/workers.QueueGroupConfig"
                     }
                 },
                 "redis_addr": {
@@ -1178,6 +3229,26 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "workers.QueueGroupConfig": {
+            "type": "object",
+            "properties": {
+                "concurrency": {
+                    "description": "并发数",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "队列组名称",
+                    "type": "string"
+                },
+                "priorities": {
+                    "description": "优先级权重",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                }
+            }
         }
     }
 }`
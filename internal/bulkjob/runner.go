@@ -0,0 +1,204 @@
+package bulkjob
+
+import (
+	"context"
+	"time"
+
+	"github.com/azhengyongqin/asynq-hub/internal/config"
+	"github.com/azhengyongqin/asynq-hub/internal/logger"
+	"github.com/azhengyongqin/asynq-hub/internal/metrics"
+	"github.com/azhengyongqin/asynq-hub/internal/model"
+	"github.com/azhengyongqin/asynq-hub/internal/periodic"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+)
+
+// JobStore 执行器用到的批量操作任务存储（repository.BulkJobRepository 满足该接口）
+type JobStore interface {
+	ClaimBulkJob(ctx context.Context, staleBefore time.Time) (*repository.BulkJob, error)
+	SaveBulkJobProgress(ctx context.Context, job repository.BulkJob, prevCursorID int64, items []repository.BulkJobItem) (bool, error)
+	FinishBulkJob(ctx context.Context, jobID, status, errMsg string) (bool, error)
+}
+
+// TaskStore 执行器用到的任务查询（repository.TaskRepository 满足该接口）
+type TaskStore interface {
+	ListTasksAfterID(ctx context.Context, filter repository.ListTasksFilter, afterID int64, limit int) ([]repository.Task, error)
+	MaxTaskID(ctx context.Context) (int64, error)
+	CountTasks(ctx context.Context, filter repository.ListTasksFilter) (int, error)
+}
+
+// Executor 对单个任务执行批量操作，由 handler.TaskHandler 实现。
+// 任务不满足操作条件时返回跳过原因；dry_run 时只做该判断，不实际执行。
+type Executor interface {
+	ApplyBulkOperation(ctx context.Context, job *repository.BulkJob, t *repository.Task) (skipped string, err error)
+}
+
+// Runner 批量操作执行器：按 PollInterval 抢占待执行的批量操作任务，按 task.id 升序分批处理匹配的任务，
+// 每批保存一次进度、游标与明细。保存进度时发现任务已被取消则停止；执行副本退出后，
+// 超过 StaleTimeout 未保存进度的任务由其他副本从游标处接管（最后一批可能被重复处理）。
+type Runner struct {
+	jobs     JobStore
+	tasks    TaskStore
+	executor Executor
+	cfg      config.BulkJobConfig
+	now      func() time.Time
+
+	loop periodic.Loop
+}
+
+// New 创建批量操作执行器
+func New(jobs JobStore, tasks TaskStore, executor Executor, cfg config.BulkJobConfig) *Runner {
+	return &Runner{jobs: jobs, tasks: tasks, executor: executor, cfg: cfg, now: time.Now}
+}
+
+// Start 启动后台执行
+func (r *Runner) Start() {
+	r.loop.Start(r.cfg.PollInterval, func(ctx context.Context) { _, _ = r.RunOnce(ctx) })
+}
+
+// Stop 停止后台执行并等待当前批次结束（未完成的任务由重启后的实例或其他副本接管）
+func (r *Runner) Stop() {
+	r.loop.Stop()
+}
+
+// RunOnce 依次执行全部可抢占的批量操作任务，返回执行的任务数
+func (r *Runner) RunOnce(ctx context.Context) (int, error) {
+	n := 0
+	for ctx.Err() == nil {
+		job, err := r.jobs.ClaimBulkJob(ctx, r.now().Add(-r.cfg.StaleTimeout))
+		if err != nil {
+			if ctx.Err() == nil {
+				metrics.RecordError("bulk_job", "claim")
+				logger.L.Error().Err(err).Msg("抢占批量操作任务失败")
+			}
+			return n, err
+		}
+		if job == nil {
+			return n, nil
+		}
+		n++
+		r.run(ctx, job)
+	}
+	return n, ctx.Err()
+}
+
+// run 执行一个批量操作任务直到完成、被取消或出错
+func (r *Runner) run(ctx context.Context, job *repository.BulkJob) {
+	log := logger.L.With().Str("job_id", job.JobID).Str("operation", job.Operation).Bool("dry_run", job.DryRun).Logger()
+	log.Info().Int64("cursor_id", job.CursorID).Msg("开始执行批量操作")
+
+	status, err := r.process(ctx, job)
+	switch {
+	case ctx.Err() != nil:
+		// 服务关闭：保持 running，由其他副本超时后接管
+		return
+	case err != nil:
+		metrics.RecordError("bulk_job", "process")
+		log.Error().Err(err).Msg("批量操作中止")
+		status = model.BulkJobStatusFailed
+	case status == "":
+		log.Info().Msg("批量操作已被取消或由其他副本接管，停止执行")
+		return
+	}
+
+	errMsg := ""
+	if err != nil {
+		errMsg = err.Error()
+	}
+	finished, err := r.jobs.FinishBulkJob(ctx, job.JobID, string(status), errMsg)
+	if err != nil {
+		metrics.RecordError("bulk_job", "finish")
+		log.Error().Err(err).Msg("保存批量操作结果失败")
+		return
+	}
+	if finished {
+		metrics.BulkJobsTotal.WithLabelValues(job.Operation, string(status)).Inc()
+		log.Info().
+			Int("total", job.Total).
+			Int("succeeded", job.Succeeded).
+			Int("skipped", job.Skipped).
+			Int("failed", job.Failed).
+			Msg("批量操作结束")
+	}
+}
+
+// process 分批处理匹配的任务，返回应设置的最终状态；返回空状态表示任务已被取消或接管
+func (r *Runner) process(ctx context.Context, job *repository.BulkJob) (model.BulkJobStatus, error) {
+	filter := job.Filter
+
+	// 首次执行：固定任务 id 上限（重试生成的新任务等之后创建的任务不参与）并统计总数
+	if job.MaxTaskID == 0 {
+		maxID, err := r.tasks.MaxTaskID(ctx)
+		if err != nil {
+			return "", err
+		}
+		if maxID == 0 {
+			return model.BulkJobStatusCompleted, nil
+		}
+		filter.MaxID = maxID
+		total, err := r.tasks.CountTasks(ctx, filter)
+		if err != nil {
+			return "", err
+		}
+		job.MaxTaskID, job.Total = maxID, total
+		if ok, err := r.jobs.SaveBulkJobProgress(ctx, *job, job.CursorID, nil); err != nil || !ok {
+			return "", err
+		}
+	}
+	filter.MaxID = job.MaxTaskID
+
+	for {
+		tasks, err := r.tasks.ListTasksAfterID(ctx, filter, job.CursorID, r.cfg.BatchSize)
+		if err != nil {
+			return "", err
+		}
+		if len(tasks) == 0 {
+			return model.BulkJobStatusCompleted, nil
+		}
+
+		prevCursor := job.CursorID
+		var items []repository.BulkJobItem
+		for i := range tasks {
+			if ctx.Err() != nil {
+				break
+			}
+			if item, ok := r.apply(ctx, job, &tasks[i]); ok {
+				items = append(items, item)
+			}
+			job.CursorID = tasks[i].ID
+		}
+
+		// 服务关闭时也保存已处理的部分，减少接管后重复处理的任务
+		saved, err := r.jobs.SaveBulkJobProgress(context.WithoutCancel(ctx), *job, prevCursor, items)
+		if err != nil || !saved || ctx.Err() != nil {
+			return "", err
+		}
+		if len(tasks) < r.cfg.BatchSize {
+			return model.BulkJobStatusCompleted, nil
+		}
+	}
+}
+
+// apply 对单个任务执行操作并累计进度，跳过或失败时返回需要写入的明细
+func (r *Runner) apply(ctx context.Context, job *repository.BulkJob, t *repository.Task) (repository.BulkJobItem, bool) {
+	skipped, err := r.executor.ApplyBulkOperation(ctx, job, t)
+	job.Processed++
+
+	outcome, message := model.BulkItemSucceeded, ""
+	switch {
+	case err != nil:
+		outcome, message = model.BulkItemFailed, err.Error()
+		job.Failed++
+		logger.L.Warn().Err(err).Str("job_id", job.JobID).Str("task_id", t.TaskID).Msg("批量操作任务失败")
+	case skipped != "":
+		outcome, message = model.BulkItemSkipped, skipped
+		job.Skipped++
+	default:
+		job.Succeeded++
+	}
+	metrics.BulkJobTasksTotal.WithLabelValues(job.Operation, string(outcome)).Inc()
+
+	if outcome == model.BulkItemSucceeded {
+		return repository.BulkJobItem{}, false
+	}
+	return repository.BulkJobItem{TaskID: t.TaskID, Outcome: string(outcome), Message: message, CreatedAt: r.now()}, true
+}
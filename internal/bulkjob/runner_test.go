@@ -0,0 +1,162 @@
+package bulkjob
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/azhengyongqin/asynq-hub/internal/config"
+	"github.com/azhengyongqin/asynq-hub/internal/model"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+)
+
+// fakeJobStore 内存批量操作存储：pending 为待抢占的任务，cancelAfter 次保存后模拟任务被取消
+type fakeJobStore struct {
+	pending     []*repository.BulkJob
+	saves       int
+	cancelAfter int
+	items       []repository.BulkJobItem
+	saved       repository.BulkJob
+	finished    map[string]string
+}
+
+func (s *fakeJobStore) ClaimBulkJob(_ context.Context, _ time.Time) (*repository.BulkJob, error) {
+	if len(s.pending) == 0 {
+		return nil, nil
+	}
+	job := s.pending[0]
+	s.pending = s.pending[1:]
+	job.Status = string(model.BulkJobStatusRunning)
+	return job, nil
+}
+
+func (s *fakeJobStore) SaveBulkJobProgress(_ context.Context, job repository.BulkJob, _ int64, items []repository.BulkJobItem) (bool, error) {
+	if s.cancelAfter > 0 && s.saves >= s.cancelAfter {
+		return false, nil
+	}
+	s.saves++
+	s.saved = job
+	s.items = append(s.items, items...)
+	return true, nil
+}
+
+func (s *fakeJobStore) FinishBulkJob(_ context.Context, jobID, status, _ string) (bool, error) {
+	s.finished[jobID] = status
+	return true, nil
+}
+
+// fakeTaskStore 按 id 升序保存的任务，只按 MaxID 过滤
+type fakeTaskStore struct {
+	tasks []repository.Task
+}
+
+func (s *fakeTaskStore) ListTasksAfterID(_ context.Context, filter repository.ListTasksFilter, afterID int64, limit int) ([]repository.Task, error) {
+	var out []repository.Task
+	for _, t := range s.tasks {
+		if t.ID > afterID && (filter.MaxID == 0 || t.ID <= filter.MaxID) && len(out) < limit {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (s *fakeTaskStore) MaxTaskID(_ context.Context) (int64, error) {
+	if len(s.tasks) == 0 {
+		return 0, nil
+	}
+	return s.tasks[len(s.tasks)-1].ID, nil
+}
+
+func (s *fakeTaskStore) CountTasks(ctx context.Context, filter repository.ListTasksFilter) (int, error) {
+	tasks, _ := s.ListTasksAfterID(ctx, filter, 0, len(s.tasks))
+	return len(tasks), nil
+}
+
+// fakeExecutor 按 task_id 返回跳过或失败，执行时追加一个新任务以验证 id 上限
+type fakeExecutor struct {
+	tasks   *fakeTaskStore
+	skip    map[string]bool
+	fail    map[string]bool
+	applied []string
+}
+
+func (e *fakeExecutor) ApplyBulkOperation(_ context.Context, _ *repository.BulkJob, t *repository.Task) (string, error) {
+	e.applied = append(e.applied, t.TaskID)
+	switch {
+	case e.skip[t.TaskID]:
+		return "skip", nil
+	case e.fail[t.TaskID]:
+		return "", errors.New("boom")
+	}
+	e.tasks.tasks = append(e.tasks.tasks, repository.Task{ID: int64(len(e.tasks.tasks) + 1), TaskID: "new-" + t.TaskID})
+	return "", nil
+}
+
+func newTestRunner(ids ...string) (*Runner, *fakeJobStore, *fakeExecutor) {
+	tasks := &fakeTaskStore{}
+	for i, id := range ids {
+		tasks.tasks = append(tasks.tasks, repository.Task{ID: int64(i + 1), TaskID: id})
+	}
+	jobs := &fakeJobStore{finished: make(map[string]string)}
+	executor := &fakeExecutor{tasks: tasks, skip: map[string]bool{}, fail: map[string]bool{}}
+	return New(jobs, tasks, executor, config.BulkJobConfig{BatchSize: 2, StaleTimeout: time.Minute}), jobs, executor
+}
+
+func TestRunOnce(t *testing.T) {
+	r, jobs, executor := newTestRunner("a", "b", "c", "d", "e")
+	executor.skip["b"] = true
+	executor.fail["d"] = true
+	jobs.pending = []*repository.BulkJob{{JobID: "job-1", Operation: "retry"}}
+
+	n, err := r.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, executor.applied, "执行中新建的任务不参与")
+	assert.Equal(t, "completed", jobs.finished["job-1"])
+	assert.Equal(t, 5, jobs.saved.Total)
+	assert.Equal(t, 5, jobs.saved.Processed)
+	assert.Equal(t, 3, jobs.saved.Succeeded)
+	assert.Equal(t, 1, jobs.saved.Skipped)
+	assert.Equal(t, 1, jobs.saved.Failed)
+	assert.Equal(t, int64(5), jobs.saved.CursorID)
+	assert.Equal(t, int64(5), jobs.saved.MaxTaskID)
+
+	require.Len(t, jobs.items, 2, "只记录跳过与失败的任务")
+	assert.Equal(t, repository.BulkJobItem{TaskID: "b", Outcome: "skipped", Message: "skip", CreatedAt: jobs.items[0].CreatedAt}, jobs.items[0])
+	assert.Equal(t, "d", jobs.items[1].TaskID)
+	assert.Equal(t, "failed", jobs.items[1].Outcome)
+	assert.Equal(t, "boom", jobs.items[1].Message)
+}
+
+func TestRunOnce_Canceled(t *testing.T) {
+	r, jobs, executor := newTestRunner("a", "b", "c", "d", "e")
+	// 第 1 次保存为初始化统计，第 2 次为第一批，之后任务被取消
+	jobs.cancelAfter = 2
+	jobs.pending = []*repository.BulkJob{{JobID: "job-1", Operation: "delete"}}
+
+	_, err := r.RunOnce(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"a", "b", "c", "d"}, executor.applied, "取消后处理完当前批次即停止")
+	assert.Equal(t, 2, jobs.saved.Processed)
+	assert.NotContains(t, jobs.finished, "job-1", "已取消的任务不再标记结束状态")
+}
+
+func TestRunOnce_Resume(t *testing.T) {
+	r, jobs, executor := newTestRunner("a", "b", "c", "d", "e", "f")
+	// 由其他副本接管：从游标处继续，保留原 id 上限与进度
+	jobs.pending = []*repository.BulkJob{{JobID: "job-1", Operation: "retry", Total: 5, Processed: 2, Succeeded: 2, CursorID: 2, MaxTaskID: 5}}
+
+	_, err := r.RunOnce(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"c", "d", "e"}, executor.applied)
+	assert.Equal(t, 5, jobs.saved.Processed)
+	assert.Equal(t, 5, jobs.saved.Succeeded)
+	assert.Equal(t, "completed", jobs.finished["job-1"])
+}
@@ -2,20 +2,31 @@ package config
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
+
+	"github.com/azhengyongqin/asynq-hub/internal/model"
 )
 
 // Config 应用配置
 type Config struct {
-	HTTP       HTTPConfig
-	GRPC       GRPCConfig
-	Redis      RedisConfig
-	Postgres   PostgresConfig
-	DBPool     DBPoolConfig
-	Asynq      AsynqConfig
-	Monitoring MonitoringConfig
+	HTTP        HTTPConfig
+	GRPC        GRPCConfig
+	Redis       RedisConfig
+	Postgres    PostgresConfig
+	DBPool      DBPoolConfig
+	Asynq       AsynqConfig
+	Monitoring  MonitoringConfig
+	Idempotency IdempotencyConfig
+	Scheduler   SchedulerConfig
+	Retention   RetentionConfig
+	Reconcile   ReconcileConfig
+	Watchdog    WatchdogConfig
+	Outbox      OutboxConfig
+	BulkJob     BulkJobConfig
 }
 
 // HTTPConfig HTTP 服务配置
@@ -60,6 +71,97 @@ type MonitoringConfig struct {
 	Port    int
 }
 
+// IdempotencyConfig 幂等配置
+type IdempotencyConfig struct {
+	// TTL 首次响应的保存时长，窗口内使用相同 Idempotency-Key 的重试直接返回该响应
+	TTL time.Duration
+}
+
+// SchedulerConfig 周期任务调度器配置
+type SchedulerConfig struct {
+	// Enabled 是否在本实例启动调度器（多副本时可全部启用，触发会按周期去重）
+	Enabled bool
+	// SyncInterval 从数据库同步周期任务定义的间隔
+	SyncInterval time.Duration
+}
+
+// RetentionConfig 任务历史保留策略配置。
+// 只清理终态（success/dead/canceled/skipped/expired/deleted/lost，fail 可能仍会被重试）且 updated_at 早于保留时长的任务；
+// 保留时长优先级：WorkerAges > StatusAges > DefaultAge，为 0 表示永久保留。
+type RetentionConfig struct {
+	// Enabled 是否在本实例运行清理任务（多副本同时运行时按行加锁，互不重复删除）
+	Enabled bool
+	// Interval 两次清理之间的间隔
+	Interval time.Duration
+	// BatchSize 每个事务最多清理的任务数
+	BatchSize int
+	// Archive 为 true 时先将任务及执行尝试写入 task_archive 再删除（RETENTION_MODE=archive）
+	Archive bool
+	// DefaultAge 未单独配置的状态与 worker 使用的保留时长
+	DefaultAge time.Duration
+	// StatusAges 按任务状态配置的保留时长，如 success=7d,dead=90d
+	StatusAges map[string]time.Duration
+	// WorkerAges 按 worker 配置的保留时长（覆盖该 worker 所有已结束状态），如 crawler=3d
+	WorkerAges map[string]time.Duration
+	// AttemptAge 执行尝试按 started_at 的保留时长，超出的整月分区直接删除（为 0 表示永久保留）
+	AttemptAge time.Duration
+	// PartitionsAhead 提前创建的执行尝试月分区数（当前月之后），无论 Enabled 与否都会维护
+	PartitionsAhead int
+}
+
+// ReconcileConfig Postgres/Redis 状态对账配置
+type ReconcileConfig struct {
+	// Enabled 是否在本实例运行对账任务（状态按条件更新，多副本同时运行也不会重复修正）
+	Enabled bool
+	// Interval 两轮对账之间的间隔
+	Interval time.Duration
+	// BatchSize 每批检查的任务数
+	BatchSize int
+	// MinAge 只检查 updated_at 早于该时长的任务，避开入队与落库之间、上报途中的正常时间差
+	MinAge time.Duration
+}
+
+// WatchdogConfig 卡住/失联执行检测配置
+type WatchdogConfig struct {
+	// Enabled 是否在本实例运行检测任务（状态按条件更新，多副本同时运行也不会重复处理）
+	Enabled bool
+	// Interval 两轮检测之间的间隔
+	Interval time.Duration
+	// BatchSize 每批检查的执行尝试数
+	BatchSize int
+	// Grace 宽限时长：开始不足该时长的执行不检查，超时判定也在超时时间之外再等待该时长，留给最终上报的重试
+	Grace time.Duration
+	// HeartbeatTimeout worker 心跳超过该时长视为失联，为 0 时不按心跳判断
+	// （worker 注册后需定期调用心跳接口，否则注册时间之后的所有执行都会被判定为失联）
+	HeartbeatTimeout time.Duration
+	// Requeue 任务已不在 Redis 中时是否重新入队（否则标记为 lost）
+	Requeue bool
+}
+
+// OutboxConfig 任务出箱（transactional outbox）配置
+type OutboxConfig struct {
+	// Enabled 是否启用出箱模式：创建/重放/批量重试时任务记录与出箱记录在同一事务中写入，由投递协程入队
+	Enabled bool
+	// PollInterval 投递协程扫描出箱的间隔
+	PollInterval time.Duration
+	// BatchSize 每个事务投递的记录数
+	BatchSize int
+	// Retention 已投递记录的保留时长
+	Retention time.Duration
+}
+
+// BulkJobConfig 异步批量操作执行器配置
+type BulkJobConfig struct {
+	// Enabled 是否在本实例执行批量操作任务（多副本时可全部启用，每个任务只由一个副本执行）
+	Enabled bool
+	// PollInterval 检查待执行任务的间隔
+	PollInterval time.Duration
+	// BatchSize 每批处理的任务数（每批保存一次进度）
+	BatchSize int
+	// StaleTimeout 执行中的任务超过该时长未保存进度时视为执行副本已退出，由其他副本从游标处接管
+	StaleTimeout time.Duration
+}
+
 // Load 加载配置
 func Load() (*Config, error) {
 	v := viper.New()
@@ -74,6 +176,10 @@ func Load() (*Config, error) {
 	// 允许从环境变量读取（优先级最高）
 	v.AutomaticEnv()
 
+	v.SetDefault("SCHEDULER_ENABLED", true)
+	v.SetDefault("BULK_JOB_ENABLED", true)
+	v.SetDefault("RETENTION_MODE", "delete")
+
 	// 读取配置文件（如果存在）
 	_ = v.ReadInConfig() // 忽略错误，因为可能只使用环境变量
 
@@ -141,9 +247,173 @@ func Load() (*Config, error) {
 		cfg.Monitoring.Port = 29091
 	}
 
+	// 幂等配置
+	cfg.Idempotency.TTL = v.GetDuration("IDEMPOTENCY_TTL")
+	if cfg.Idempotency.TTL <= 0 {
+		cfg.Idempotency.TTL = 24 * time.Hour
+	}
+
+	// 调度器配置
+	cfg.Scheduler.Enabled = v.GetBool("SCHEDULER_ENABLED")
+	cfg.Scheduler.SyncInterval = v.GetDuration("SCHEDULER_SYNC_INTERVAL")
+	if cfg.Scheduler.SyncInterval <= 0 {
+		cfg.Scheduler.SyncInterval = 10 * time.Second
+	}
+
+	// 保留策略配置
+	if err := loadRetention(v, &cfg.Retention); err != nil {
+		return nil, err
+	}
+
+	// 状态对账配置
+	cfg.Reconcile.Enabled = v.GetBool("RECONCILE_ENABLED")
+	cfg.Reconcile.Interval = v.GetDuration("RECONCILE_INTERVAL")
+	if cfg.Reconcile.Interval <= 0 {
+		cfg.Reconcile.Interval = 10 * time.Minute
+	}
+	cfg.Reconcile.BatchSize = v.GetInt("RECONCILE_BATCH_SIZE")
+	if cfg.Reconcile.BatchSize <= 0 {
+		cfg.Reconcile.BatchSize = 200
+	}
+	cfg.Reconcile.MinAge = v.GetDuration("RECONCILE_MIN_AGE")
+	if cfg.Reconcile.MinAge <= 0 {
+		cfg.Reconcile.MinAge = 10 * time.Minute
+	}
+
+	// 卡住/失联执行检测配置
+	cfg.Watchdog.Enabled = v.GetBool("WATCHDOG_ENABLED")
+	cfg.Watchdog.Interval = v.GetDuration("WATCHDOG_INTERVAL")
+	if cfg.Watchdog.Interval <= 0 {
+		cfg.Watchdog.Interval = time.Minute
+	}
+	cfg.Watchdog.BatchSize = v.GetInt("WATCHDOG_BATCH_SIZE")
+	if cfg.Watchdog.BatchSize <= 0 {
+		cfg.Watchdog.BatchSize = 200
+	}
+	cfg.Watchdog.Grace = v.GetDuration("WATCHDOG_GRACE")
+	if cfg.Watchdog.Grace <= 0 {
+		cfg.Watchdog.Grace = 2 * time.Minute
+	}
+	cfg.Watchdog.HeartbeatTimeout = v.GetDuration("WATCHDOG_HEARTBEAT_TIMEOUT")
+	cfg.Watchdog.Requeue = v.GetBool("WATCHDOG_REQUEUE")
+
+	// 任务出箱配置
+	cfg.Outbox.Enabled = v.GetBool("OUTBOX_ENABLED")
+	cfg.Outbox.PollInterval = v.GetDuration("OUTBOX_POLL_INTERVAL")
+	if cfg.Outbox.PollInterval <= 0 {
+		cfg.Outbox.PollInterval = time.Second
+	}
+	cfg.Outbox.BatchSize = v.GetInt("OUTBOX_BATCH_SIZE")
+	if cfg.Outbox.BatchSize <= 0 {
+		cfg.Outbox.BatchSize = 100
+	}
+	cfg.Outbox.Retention = v.GetDuration("OUTBOX_RETENTION")
+	if cfg.Outbox.Retention <= 0 {
+		cfg.Outbox.Retention = 24 * time.Hour
+	}
+
+	// 批量操作配置
+	cfg.BulkJob.Enabled = v.GetBool("BULK_JOB_ENABLED")
+	cfg.BulkJob.PollInterval = v.GetDuration("BULK_JOB_POLL_INTERVAL")
+	if cfg.BulkJob.PollInterval <= 0 {
+		cfg.BulkJob.PollInterval = 2 * time.Second
+	}
+	cfg.BulkJob.BatchSize = v.GetInt("BULK_JOB_BATCH_SIZE")
+	if cfg.BulkJob.BatchSize <= 0 {
+		cfg.BulkJob.BatchSize = 100
+	}
+	cfg.BulkJob.StaleTimeout = v.GetDuration("BULK_JOB_STALE_TIMEOUT")
+	if cfg.BulkJob.StaleTimeout <= 0 {
+		cfg.BulkJob.StaleTimeout = 5 * time.Minute
+	}
+
 	return cfg, nil
 }
 
+// loadRetention 解析保留策略配置
+func loadRetention(v *viper.Viper, c *RetentionConfig) error {
+	c.Enabled = v.GetBool("RETENTION_ENABLED")
+	c.Interval = v.GetDuration("RETENTION_INTERVAL")
+	if c.Interval <= 0 {
+		c.Interval = time.Hour
+	}
+	c.BatchSize = v.GetInt("RETENTION_BATCH_SIZE")
+	if c.BatchSize <= 0 {
+		c.BatchSize = 500
+	}
+
+	switch mode := v.GetString("RETENTION_MODE"); mode {
+	case "delete":
+	case "archive":
+		c.Archive = true
+	default:
+		return fmt.Errorf("RETENTION_MODE 必须是 delete 或 archive: %s", mode)
+	}
+
+	c.PartitionsAhead = v.GetInt("RETENTION_PARTITIONS_AHEAD")
+	if c.PartitionsAhead <= 0 {
+		c.PartitionsAhead = 3
+	}
+
+	var err error
+	if s := v.GetString("RETENTION_ATTEMPT_AGE"); s != "" {
+		if c.AttemptAge, err = ParseAge(s); err != nil {
+			return fmt.Errorf("RETENTION_ATTEMPT_AGE: %w", err)
+		}
+	}
+	if s := v.GetString("RETENTION_DEFAULT_AGE"); s != "" {
+		if c.DefaultAge, err = ParseAge(s); err != nil {
+			return fmt.Errorf("RETENTION_DEFAULT_AGE: %w", err)
+		}
+	}
+	if c.StatusAges, err = parseAgeMap(v.GetString("RETENTION_STATUS_AGES")); err != nil {
+		return fmt.Errorf("RETENTION_STATUS_AGES: %w", err)
+	}
+	if c.WorkerAges, err = parseAgeMap(v.GetString("RETENTION_WORKER_AGES")); err != nil {
+		return fmt.Errorf("RETENTION_WORKER_AGES: %w", err)
+	}
+	return nil
+}
+
+// ParseAge 解析保留时长：支持 time.ParseDuration 的格式，另支持以 d 结尾的天数（如 7d）
+func ParseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("无效的保留时长: %s", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("无效的保留时长: %s", s)
+	}
+	return d, nil
+}
+
+// parseAgeMap 解析 key=age 逗号分隔列表，如 success=7d,dead=90d
+func parseAgeMap(s string) (map[string]time.Duration, error) {
+	ages := make(map[string]time.Duration)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(item, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("格式应为 key=age: %s", item)
+		}
+		age, err := ParseAge(value)
+		if err != nil {
+			return nil, err
+		}
+		ages[key] = age
+	}
+	return ages, nil
+}
+
 // Validate 验证配置
 func (c *Config) Validate() error {
 	if c.Postgres.DSN == "" {
@@ -152,5 +422,11 @@ func (c *Config) Validate() error {
 	if c.Redis.Addr == "" {
 		return fmt.Errorf("Redis address is required")
 	}
+	// 保留策略只能清理终态的任务（fail 可能仍会被 asynq 重试）
+	for status := range c.Retention.StatusAges {
+		if !model.TaskStatus(status).IsTerminal() {
+			return fmt.Errorf("RETENTION_STATUS_AGES 只能配置终态（%s）: %s", strings.Join(model.TerminalStatuses(), "/"), status)
+		}
+	}
 	return nil
 }
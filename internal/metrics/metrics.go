@@ -96,6 +96,123 @@ var (
 		},
 	)
 
+	// 保留策略指标
+	RetentionPurgedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "asynqhub_retention_purged_rows_total",
+			Help: "Total number of rows deleted or archived by the retention job",
+		},
+		[]string{"table", "mode"},
+	)
+
+	RetentionRunDuration = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "asynqhub_retention_run_duration_seconds",
+			Help:    "Duration of a retention run in seconds",
+			Buckets: []float64{0.1, 0.5, 1, 5, 10, 30, 60, 300, 900, 1800},
+		},
+	)
+
+	RetentionPartitionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "asynqhub_retention_partitions_total",
+			Help: "Total number of task_attempt partitions created or dropped by the retention job",
+		},
+		[]string{"action"},
+	)
+
+	RetentionLastSuccess = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "asynqhub_retention_last_success_timestamp_seconds",
+			Help: "Unix timestamp of the last successful retention run",
+		},
+	)
+
+	// 状态对账指标
+	ReconcileCheckedTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "asynqhub_reconcile_checked_tasks_total",
+			Help: "Total number of tasks checked against Redis by the reconciler",
+		},
+	)
+
+	ReconcileCorrectedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "asynqhub_reconcile_corrected_tasks_total",
+			Help: "Total number of task statuses corrected by the reconciler",
+		},
+		[]string{"from", "to"},
+	)
+
+	ReconcileLastSuccess = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "asynqhub_reconcile_last_success_timestamp_seconds",
+			Help: "Unix timestamp of the last successful reconcile run",
+		},
+	)
+
+	// 失联执行检测指标
+	WatchdogAbandonedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "asynqhub_watchdog_abandoned_attempts_total",
+			Help: "Total number of abandoned running attempts detected by the watchdog",
+		},
+		[]string{"reason", "action"},
+	)
+
+	WatchdogLastSuccess = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "asynqhub_watchdog_last_success_timestamp_seconds",
+			Help: "Unix timestamp of the last successful watchdog run",
+		},
+	)
+
+	// 任务出箱指标
+	OutboxDeliveredTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "asynqhub_outbox_delivered_total",
+			Help: "Total number of outbox records enqueued to asynq",
+		},
+	)
+
+	OutboxFailedTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "asynqhub_outbox_delivery_failures_total",
+			Help: "Total number of failed outbox delivery attempts",
+		},
+	)
+
+	OutboxPending = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "asynqhub_outbox_pending",
+			Help: "Number of outbox records not yet delivered",
+		},
+	)
+
+	OutboxOldestAge = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "asynqhub_outbox_oldest_pending_age_seconds",
+			Help: "Age of the oldest undelivered outbox record",
+		},
+	)
+
+	// 批量操作指标
+	BulkJobTasksTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "asynqhub_bulk_job_tasks_total",
+			Help: "Total number of tasks processed by bulk-operation jobs",
+		},
+		[]string{"operation", "outcome"},
+	)
+
+	BulkJobsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "asynqhub_bulk_jobs_total",
+			Help: "Total number of finished bulk-operation jobs",
+		},
+		[]string{"operation", "status"},
+	)
+
 	// 错误指标
 	ErrorsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -143,6 +260,17 @@ func UpdateDBPoolStats(inUse, idle, max int32) {
 	DBConnectionsMax.Set(float64(max))
 }
 
+// RecordRetentionPurged 记录保留策略清理的行数（mode 为 delete 或 archive）
+func RecordRetentionPurged(mode string, tasks, attempts int) {
+	RetentionPurgedTotal.WithLabelValues("task", mode).Add(float64(tasks))
+	RetentionPurgedTotal.WithLabelValues("task_attempt", mode).Add(float64(attempts))
+}
+
+// RecordRetentionPartitions 记录保留策略创建或删除的执行尝试分区数（action 为 created 或 dropped）
+func RecordRetentionPartitions(action string, n int) {
+	RetentionPartitionsTotal.WithLabelValues(action).Add(float64(n))
+}
+
 // RecordError 记录错误
 func RecordError(component, errorType string) {
 	ErrorsTotal.WithLabelValues(component, errorType).Inc()
@@ -0,0 +1,193 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/azhengyongqin/asynq-hub/internal/cache"
+	"github.com/azhengyongqin/asynq-hub/internal/logger"
+)
+
+const (
+	// IdempotencyKeyHeader 幂等键请求头
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotentReplayedHeader 响应头：标记该响应是重放的首次响应
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// maxIdempotencyKeyLength 幂等键最大长度
+	maxIdempotencyKeyLength = 255
+
+	// idempotencyLockTTL 处理中记录的过期时间（进程崩溃时自动释放）
+	idempotencyLockTTL = 30 * time.Second
+
+	// idempotencyLockRefresh 处理期间续期处理中记录的间隔
+	idempotencyLockRefresh = idempotencyLockTTL / 3
+)
+
+// IdempotencyStore 幂等记录存储（*cache.RedisCache 满足该接口）
+type IdempotencyStore interface {
+	Get(ctx context.Context, key string, dest interface{}) error
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// idempotencyRecord 幂等记录：Status 为 0 表示首个请求仍在处理中
+type idempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// idempotencyWriter 记录响应体，便于保存首次响应
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency 幂等中间件：
+// - 携带 Idempotency-Key 的请求在 ttl 内重复提交时，直接返回首次响应（不再执行 handler）
+// - 相同 key 但请求体不同，或首个请求仍在处理中时返回 409
+// - 首次响应为 5xx 时不保存，允许客户端用同一个 key 重试
+// store 为 nil 时不启用。
+func Idempotency(store IdempotencyStore, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idemKey := c.GetHeader(IdempotencyKeyHeader)
+		if store == nil || idemKey == "" {
+			c.Next()
+			return
+		}
+		if len(idemKey) > maxIdempotencyKeyLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key 过长，最大 255 字符"})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "读取请求体失败"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		sum := sha256.Sum256(body)
+		fingerprint := hex.EncodeToString(sum[:])
+		key := cache.CacheKey("idempotency", c.Request.Method, c.Request.URL.Path, idemKey)
+		ctx := c.Request.Context()
+
+		acquired, err := store.SetNX(ctx, key, idempotencyRecord{Fingerprint: fingerprint}, idempotencyLockTTL)
+		if err != nil {
+			logger.L.Error().Err(err).Str("idempotency_key", idemKey).Msg("写入幂等记录失败")
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "幂等存储不可用"})
+			c.Abort()
+			return
+		}
+
+		if !acquired {
+			var rec idempotencyRecord
+			if err := store.Get(ctx, key, &rec); err != nil {
+				if errors.Is(err, cache.ErrCacheMiss) {
+					// 记录恰好过期：让客户端重试
+					c.JSON(http.StatusConflict, gin.H{"error": "相同 Idempotency-Key 的请求正在处理中"})
+				} else {
+					logger.L.Error().Err(err).Str("idempotency_key", idemKey).Msg("读取幂等记录失败")
+					c.JSON(http.StatusServiceUnavailable, gin.H{"error": "幂等存储不可用"})
+				}
+				c.Abort()
+				return
+			}
+
+			switch {
+			case rec.Fingerprint != fingerprint:
+				c.JSON(http.StatusConflict, gin.H{"error": "Idempotency-Key 已被用于不同的请求体"})
+			case rec.Status == 0:
+				c.JSON(http.StatusConflict, gin.H{"error": "相同 Idempotency-Key 的请求正在处理中"})
+			default:
+				c.Header(IdempotentReplayedHeader, "true")
+				c.Data(rec.Status, rec.ContentType, rec.Body)
+			}
+			c.Abort()
+			return
+		}
+
+		w := &idempotencyWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+
+		// 处理期间持续续期，避免耗时请求（如批量创建）的记录过期后相同 key 的重试再次执行 handler
+		stopRefresh := refreshIdempotencyLock(store, key, idempotencyRecord{Fingerprint: fingerprint}, idemKey)
+		c.Next()
+		stopRefresh()
+
+		// 请求上下文可能已被取消，使用独立的上下文保存结果
+		saveCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		status := w.Status()
+		if status >= http.StatusInternalServerError {
+			if err := store.Delete(saveCtx, key); err != nil {
+				logger.L.Warn().Err(err).Str("idempotency_key", idemKey).Msg("释放幂等记录失败")
+			}
+			return
+		}
+
+		rec := idempotencyRecord{
+			Fingerprint: fingerprint,
+			Status:      status,
+			ContentType: w.Header().Get("Content-Type"),
+			Body:        w.body.Bytes(),
+		}
+		if err := store.Set(saveCtx, key, rec, ttl); err != nil {
+			logger.L.Warn().Err(err).Str("idempotency_key", idemKey).Msg("保存幂等响应失败")
+		}
+	}
+}
+
+// refreshIdempotencyLock 定期重写处理中记录以延长过期时间，返回的函数用于停止续期（等待续期协程退出）
+func refreshIdempotencyLock(store IdempotencyStore, key string, rec idempotencyRecord, idemKey string) func() {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(idempotencyLockRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			if err := store.Set(ctx, key, rec, idempotencyLockTTL); err != nil {
+				logger.L.Warn().Err(err).Str("idempotency_key", idemKey).Msg("续期幂等记录失败")
+			}
+			cancel()
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/azhengyongqin/asynq-hub/internal/cache"
+)
+
+// memIdempotencyStore 内存实现，仅用于测试
+type memIdempotencyStore struct {
+	mu    sync.Mutex
+	items map[string][]byte
+}
+
+func newMemIdempotencyStore() *memIdempotencyStore {
+	return &memIdempotencyStore{items: map[string][]byte{}}
+}
+
+func (s *memIdempotencyStore) Get(_ context.Context, key string, dest interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.items[key]
+	if !ok {
+		return cache.ErrCacheMiss
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (s *memIdempotencyStore) Set(_ context.Context, key string, value interface{}, _ time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items[key] = data
+	return nil
+}
+
+func (s *memIdempotencyStore) SetNX(_ context.Context, key string, value interface{}, _ time.Duration) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.items[key]; ok {
+		return false, nil
+	}
+	s.items[key] = data
+	return true, nil
+}
+
+func (s *memIdempotencyStore) Delete(_ context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, k := range keys {
+		delete(s.items, k)
+	}
+	return nil
+}
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	calls := 0
+	status := http.StatusOK
+	r := gin.New()
+	r.POST("/tasks", Idempotency(newMemIdempotencyStore(), time.Hour), func(c *gin.Context) {
+		calls++
+		c.JSON(status, gin.H{"call": calls})
+	})
+
+	do := func(key, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// 首次请求执行 handler
+	w := do("k1", `{"a":1}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"call":1}`, w.Body.String())
+
+	// 相同 key 与请求体：重放首次响应
+	w = do("k1", `{"a":1}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"call":1}`, w.Body.String())
+	assert.Equal(t, "true", w.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, 1, calls)
+
+	// 相同 key 不同请求体：409
+	w = do("k1", `{"a":2}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, 1, calls)
+
+	// 未携带 key：每次都执行
+	do("", `{"a":1}`)
+	assert.Equal(t, 2, calls)
+
+	// 5xx 响应不保存，允许重试
+	status = http.StatusInternalServerError
+	w = do("k2", `{"a":1}`)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	status = http.StatusOK
+	w = do("k2", `{"a":1}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 4, calls)
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"fmt"
 	"net/http"
 	"regexp"
 	"strings"
@@ -11,6 +12,11 @@ import (
 const (
 	// MaxPayloadSize 最大 payload 大小（2MB）
 	MaxPayloadSize = 2 * 1024 * 1024
+
+	// MaxLabels 单个任务最多标签数
+	MaxLabels = 32
+	// MaxLabelValueLength 标签值最大长度
+	MaxLabelValueLength = 256
 )
 
 var (
@@ -22,6 +28,9 @@ var (
 
 	// TaskIDRegex TaskID 正则（字母数字连字符，1-128字符）
 	TaskIDRegex = regexp.MustCompile(`^[a-zA-Z0-9-]{1,128}$`)
+
+	// LabelKeyRegex 标签键正则（字母数字下划线点斜杠连字符，1-63字符）
+	LabelKeyRegex = regexp.MustCompile(`^[a-zA-Z0-9_./-]{1,63}$`)
 )
 
 // PayloadSizeLimit Payload 大小限制中间件
@@ -53,6 +62,41 @@ func ValidateTaskID(taskID string) bool {
 	return TaskIDRegex.MatchString(taskID)
 }
 
+// ValidateLabels 验证任务标签
+func ValidateLabels(labels map[string]string) error {
+	if len(labels) > MaxLabels {
+		return fmt.Errorf("labels 最多 %d 个", MaxLabels)
+	}
+	for k, v := range labels {
+		if !LabelKeyRegex.MatchString(k) {
+			return fmt.Errorf("label 键 %q 格式无效，必须是1-63个字母、数字、下划线、点、斜杠或连字符", k)
+		}
+		if len(v) > MaxLabelValueLength {
+			return fmt.Errorf("label %q 的值过长，最大 %d 字节", k, MaxLabelValueLength)
+		}
+	}
+	return nil
+}
+
+// ParseLabelSelector 解析标签选择器（每项格式为 key=value，多项需同时满足）
+func ParseLabelSelector(selectors []string) (map[string]string, error) {
+	if len(selectors) == 0 {
+		return nil, nil
+	}
+	labels := make(map[string]string, len(selectors))
+	for _, sel := range selectors {
+		k, v, ok := strings.Cut(sel, "=")
+		if !ok {
+			return nil, fmt.Errorf("label 选择器 %q 格式无效，应为 key=value", sel)
+		}
+		labels[k] = v
+	}
+	if err := ValidateLabels(labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
 // SanitizeString 清理字符串（去除危险字符）
 func SanitizeString(s string) string {
 	// 去除前后空格
@@ -117,6 +161,54 @@ func ValidateTaskIDParam() gin.HandlerFunc {
 	}
 }
 
+// ValidateWorkflowIDParam Gin 中间件：验证路径参数中的 workflow_id（格式与 task_id 相同）
+func ValidateWorkflowIDParam() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workflowID := c.Param("workflow_id")
+		if workflowID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "workflow_id 参数缺失",
+			})
+			c.Abort()
+			return
+		}
+
+		if !ValidateTaskID(workflowID) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "workflow_id 格式无效，必须是1-128个字母、数字或连字符",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ValidateScheduleIDParam Gin 中间件：验证路径参数中的 schedule_id（格式与 task_id 相同）
+func ValidateScheduleIDParam() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scheduleID := c.Param("schedule_id")
+		if scheduleID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "schedule_id 参数缺失",
+			})
+			c.Abort()
+			return
+		}
+
+		if !ValidateTaskID(scheduleID) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "schedule_id 格式无效，必须是1-128个字母、数字或连字符",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // RateLimitSimple 简单的速率限制（基于 IP）
 // 注意：生产环境建议使用更完善的限流方案（如 Redis + Token Bucket）
 func RateLimitSimple(requestsPerMinute int) gin.HandlerFunc {
@@ -143,3 +235,27 @@ func CORSMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// ValidateBulkJobIDParam Gin 中间件：验证路径参数中的 job_id（格式与 task_id 相同）
+func ValidateBulkJobIDParam() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		jobID := c.Param("job_id")
+		if jobID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "job_id 参数缺失",
+			})
+			c.Abort()
+			return
+		}
+
+		if !ValidateTaskID(jobID) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "job_id 格式无效，必须是1-128个字母、数字或连字符",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -154,3 +154,33 @@ func TestRequestIDMiddleware(t *testing.T) {
 		assert.Equal(t, "test-123", w.Header().Get("X-Request-ID"))
 	})
 }
+
+func TestParseLabelSelector(t *testing.T) {
+	tests := []struct {
+		name      string
+		selectors []string
+		want      map[string]string
+		wantErr   bool
+	}{
+		{"empty", nil, nil, false},
+		{"single", []string{"tenant=acme"}, map[string]string{"tenant": "acme"}, false},
+		{"multiple", []string{"tenant=acme", "source=api"}, map[string]string{"tenant": "acme", "source": "api"}, false},
+		{"value with equals", []string{"expr=a=b"}, map[string]string{"expr": "a=b"}, false},
+		{"empty value", []string{"batch_id="}, map[string]string{"batch_id": ""}, false},
+		{"missing equals", []string{"tenant"}, nil, true},
+		{"invalid key", []string{"ten ant=acme"}, nil, true},
+		{"value too long", []string{"tenant=" + strings.Repeat("a", MaxLabelValueLength+1)}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLabelSelector(tt.selectors)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
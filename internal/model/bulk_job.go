@@ -0,0 +1,56 @@
+package model
+
+// BulkOperation 批量操作类型
+type BulkOperation string
+
+const (
+	BulkOperationRetry          BulkOperation = "retry"           // 重放已结束的任务（生成新任务）
+	BulkOperationCancel         BulkOperation = "cancel"          // 取消未结束的任务
+	BulkOperationDelete         BulkOperation = "delete"          // 从 Redis 与 Postgres 中删除任务
+	BulkOperationChangePriority BulkOperation = "change_priority" // 将尚未执行的任务移动到其他优先级队列
+)
+
+func (o BulkOperation) Valid() bool {
+	switch o {
+	case BulkOperationRetry, BulkOperationCancel, BulkOperationDelete, BulkOperationChangePriority:
+		return true
+	default:
+		return false
+	}
+}
+
+// BulkJobStatus 批量操作任务状态。
+// 约定：
+// - pending: 等待后台执行
+// - running: 执行中（progress 随批次更新）
+// - completed: 全部匹配的任务已处理（单个任务失败记录在明细中，不影响整体状态）
+// - failed: 因数据库错误中止
+// - canceled: 被取消，已处理的任务不回滚
+type BulkJobStatus string
+
+const (
+	BulkJobStatusPending   BulkJobStatus = "pending"
+	BulkJobStatusRunning   BulkJobStatus = "running"
+	BulkJobStatusCompleted BulkJobStatus = "completed"
+	BulkJobStatusFailed    BulkJobStatus = "failed"
+	BulkJobStatusCanceled  BulkJobStatus = "canceled"
+)
+
+// IsTerminal 是否已结束
+func (s BulkJobStatus) IsTerminal() bool {
+	switch s {
+	case BulkJobStatusCompleted, BulkJobStatusFailed, BulkJobStatusCanceled:
+		return true
+	default:
+		return false
+	}
+}
+
+// BulkItemOutcome 单个任务的批量操作结果（只有 skipped 与 failed 写入明细）
+type BulkItemOutcome string
+
+const (
+	BulkItemSucceeded BulkItemOutcome = "succeeded"
+	BulkItemSkipped   BulkItemOutcome = "skipped"
+	BulkItemFailed    BulkItemOutcome = "failed"
+)
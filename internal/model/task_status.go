@@ -2,26 +2,72 @@ package model
 
 // TaskStatus 统一任务状态枚举（用于 API/PG/前端筛选）。
 // 约定：
+// - waiting: 工作流中的任务，等待上游依赖全部成功后才入队
 // - pending: 已入队（等待被 worker 消费）
 // - running: worker 开始处理
 // - success: 成功
 // - fail: 本次尝试失败（可能会重试）
 // - dead: 超过最大重试或被判定为不可恢复失败
+// - canceled: 被用户通过 API 主动取消
+// - skipped: 工作流中上游任务失败，该任务不再执行
+// - expired: 到过期时间（expires_at）仍未开始执行，被 worker 丢弃
+// - deleted: 对账发现任务已不在 Redis 中且从未执行（如被清空队列）
+// - lost: 对账发现任务已执行但不在 Redis 中，且未收到最终执行结果；失联检测也用它标记 worker 已失联的执行尝试
+// - timeout: 执行尝试超过超时时间仍未上报结果（只用于执行尝试，由失联检测标记）
 type TaskStatus string
 
 const (
-	TaskStatusPending TaskStatus = "pending"
-	TaskStatusRunning TaskStatus = "running"
-	TaskStatusSuccess TaskStatus = "success"
-	TaskStatusFail    TaskStatus = "fail"
-	TaskStatusDead    TaskStatus = "dead"
+	TaskStatusWaiting  TaskStatus = "waiting"
+	TaskStatusPending  TaskStatus = "pending"
+	TaskStatusRunning  TaskStatus = "running"
+	TaskStatusSuccess  TaskStatus = "success"
+	TaskStatusFail     TaskStatus = "fail"
+	TaskStatusDead     TaskStatus = "dead"
+	TaskStatusCanceled TaskStatus = "canceled"
+	TaskStatusSkipped  TaskStatus = "skipped"
+	TaskStatusExpired  TaskStatus = "expired"
+	TaskStatusDeleted  TaskStatus = "deleted"
+	TaskStatusLost     TaskStatus = "lost"
+	TaskStatusTimeout  TaskStatus = "timeout"
 )
 
+// allTaskStatuses 全部任务状态
+var allTaskStatuses = []TaskStatus{
+	TaskStatusWaiting, TaskStatusPending, TaskStatusRunning, TaskStatusSuccess, TaskStatusFail,
+	TaskStatusDead, TaskStatusCanceled, TaskStatusSkipped, TaskStatusExpired, TaskStatusDeleted, TaskStatusLost,
+	TaskStatusTimeout,
+}
+
 func (s TaskStatus) Valid() bool {
 	switch s {
-	case TaskStatusPending, TaskStatusRunning, TaskStatusSuccess, TaskStatusFail, TaskStatusDead:
+	case TaskStatusWaiting, TaskStatusPending, TaskStatusRunning, TaskStatusSuccess, TaskStatusFail,
+		TaskStatusDead, TaskStatusCanceled, TaskStatusSkipped, TaskStatusExpired, TaskStatusDeleted, TaskStatusLost,
+		TaskStatusTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsTerminal 是否为终态（不会再被 worker 执行）。
+// 注意 fail 不是终态：asynq 可能仍会重试该任务。
+func (s TaskStatus) IsTerminal() bool {
+	switch s {
+	case TaskStatusSuccess, TaskStatusDead, TaskStatusCanceled, TaskStatusSkipped, TaskStatusExpired,
+		TaskStatusDeleted, TaskStatusLost:
 		return true
 	default:
 		return false
 	}
 }
+
+// TerminalStatuses 返回全部终态（IsTerminal 为 true 的状态）
+func TerminalStatuses() []string {
+	var out []string
+	for _, s := range allTaskStatuses {
+		if s.IsTerminal() {
+			out = append(out, string(s))
+		}
+	}
+	return out
+}
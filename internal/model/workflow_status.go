@@ -0,0 +1,23 @@
+package model
+
+// WorkflowStatus 工作流状态。
+// 约定：
+// - running: 仍有任务未结束
+// - success: 所有任务均成功
+// - failed: 所有任务均已结束，且至少一个任务 dead/canceled/skipped/expired/deleted/lost
+type WorkflowStatus string
+
+const (
+	WorkflowStatusRunning WorkflowStatus = "running"
+	WorkflowStatusSuccess WorkflowStatus = "success"
+	WorkflowStatusFailed  WorkflowStatus = "failed"
+)
+
+func (s WorkflowStatus) Valid() bool {
+	switch s {
+	case WorkflowStatusRunning, WorkflowStatusSuccess, WorkflowStatusFailed:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,152 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/azhengyongqin/asynq-hub/internal/config"
+	"github.com/azhengyongqin/asynq-hub/internal/logger"
+	"github.com/azhengyongqin/asynq-hub/internal/metrics"
+	"github.com/azhengyongqin/asynq-hub/internal/periodic"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+)
+
+const (
+	// maxRetryDelay 投递失败后的最长退避时间
+	maxRetryDelay = 5 * time.Minute
+	// purgeInterval 清理已投递记录的间隔
+	purgeInterval = time.Hour
+	// purgeBatchSize 每批清理的已投递记录数
+	purgeBatchSize = 1000
+)
+
+// Store 投递协程用到的出箱存储操作（repository.TaskRepository 满足该接口）
+type Store interface {
+	RelayOutbox(ctx context.Context, limit int, deliver func(ctx context.Context, e repository.OutboxEntry, t repository.Task) repository.OutboxDelivery) (repository.OutboxRelayResult, error)
+	GetOutboxStats(ctx context.Context) (repository.OutboxStats, error)
+	PurgeDeliveredOutbox(ctx context.Context, before time.Time, limit int) (int, error)
+}
+
+// Enqueuer 将任务记录入队到 asynq，由 handler.TaskHandler 实现。
+// 同一任务可能被投递多次（至少一次），任务已在 asynq 中时应视为成功。
+type Enqueuer interface {
+	DeliverTask(ctx context.Context, t repository.Task, processAt *time.Time) error
+}
+
+// Relay 出箱投递协程：按 PollInterval 扫描未投递的出箱记录，入队到 asynq 后标记为已投递。
+// 投递失败的记录按指数退避重试，直到成功。
+type Relay struct {
+	store    Store
+	enqueuer Enqueuer
+	cfg      config.OutboxConfig
+	now      func() time.Time
+
+	lastPurge time.Time
+
+	loop periodic.Loop
+}
+
+// New 创建出箱投递协程
+func New(store Store, enqueuer Enqueuer, cfg config.OutboxConfig) *Relay {
+	return &Relay{store: store, enqueuer: enqueuer, cfg: cfg, now: time.Now}
+}
+
+// Start 启动后台投递
+func (r *Relay) Start() {
+	r.loop.Start(r.cfg.PollInterval, func(ctx context.Context) { _, _ = r.RunOnce(ctx) })
+}
+
+// Stop 停止后台投递并等待当前批次结束
+func (r *Relay) Stop() {
+	r.loop.Stop()
+}
+
+// RunOnce 投递全部到期的出箱记录（按 BatchSize 分批，每批一个事务），并更新积压指标
+func (r *Relay) RunOnce(ctx context.Context) (repository.OutboxRelayResult, error) {
+	var total repository.OutboxRelayResult
+	for {
+		res, err := r.store.RelayOutbox(ctx, r.cfg.BatchSize, r.deliver)
+		if err != nil {
+			if ctx.Err() == nil {
+				metrics.RecordError("outbox", "relay")
+				logger.L.Error().Err(err).Msg("投递出箱记录失败")
+			}
+			return total, err
+		}
+		total.Delivered += res.Delivered
+		total.Failed += res.Failed
+		metrics.OutboxDeliveredTotal.Add(float64(res.Delivered))
+		metrics.OutboxFailedTotal.Add(float64(res.Failed))
+
+		// 本批有失败的记录时不再继续，等待退避后重试
+		if res.Delivered+res.Failed < r.cfg.BatchSize || res.Failed > 0 || ctx.Err() != nil {
+			break
+		}
+	}
+
+	r.updateStats(ctx)
+	r.purge(ctx)
+	return total, nil
+}
+
+// deliver 投递一条出箱记录，失败时按投递次数计算下次重试时间
+func (r *Relay) deliver(ctx context.Context, e repository.OutboxEntry, t repository.Task) repository.OutboxDelivery {
+	if err := r.enqueuer.DeliverTask(ctx, t, e.ProcessAt); err != nil {
+		logger.L.Warn().Err(err).Str("task_id", t.TaskID).Int("attempts", e.Attempts+1).Msg("出箱任务入队失败，稍后重试")
+		return repository.OutboxDelivery{Err: err, RetryAt: r.now().Add(retryDelay(e.Attempts))}
+	}
+	return repository.OutboxDelivery{}
+}
+
+// retryDelay 第 attempts+1 次投递失败后的退避时间：1s、2s、4s……最长 maxRetryDelay
+func retryDelay(attempts int) time.Duration {
+	if attempts >= 9 {
+		return maxRetryDelay
+	}
+	d := time.Second << attempts
+	if d > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return d
+}
+
+// updateStats 更新未投递记录数与最早积压时长指标
+func (r *Relay) updateStats(ctx context.Context) {
+	stats, err := r.store.GetOutboxStats(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			metrics.RecordError("outbox", "stats")
+		}
+		return
+	}
+	metrics.OutboxPending.Set(float64(stats.Pending))
+	age := 0.0
+	if stats.OldestCreatedAt != nil {
+		age = r.now().Sub(*stats.OldestCreatedAt).Seconds()
+	}
+	metrics.OutboxOldestAge.Set(age)
+}
+
+// purge 按 purgeInterval 清理超过保留时长的已投递记录
+func (r *Relay) purge(ctx context.Context) {
+	now := r.now()
+	if now.Sub(r.lastPurge) < purgeInterval {
+		return
+	}
+	r.lastPurge = now
+
+	before := now.Add(-r.cfg.Retention)
+	for ctx.Err() == nil {
+		n, err := r.store.PurgeDeliveredOutbox(ctx, before, purgeBatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				metrics.RecordError("outbox", "purge")
+				logger.L.Warn().Err(err).Msg("清理已投递出箱记录失败")
+			}
+			return
+		}
+		if n < purgeBatchSize {
+			return
+		}
+	}
+}
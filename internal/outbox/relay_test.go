@@ -0,0 +1,123 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/azhengyongqin/asynq-hub/internal/config"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+)
+
+// fakeStore 内存出箱存储：entries 为未投递的记录，按 RelayOutbox 的 limit 分批交给 deliver
+type fakeStore struct {
+	entries   []repository.OutboxEntry
+	delivered []string
+	retryAt   map[string]time.Time
+	purged    int
+	calls     int
+}
+
+func (s *fakeStore) RelayOutbox(ctx context.Context, limit int, deliver func(ctx context.Context, e repository.OutboxEntry, t repository.Task) repository.OutboxDelivery) (repository.OutboxRelayResult, error) {
+	s.calls++
+	var res repository.OutboxRelayResult
+	var rest []repository.OutboxEntry
+	for i, e := range s.entries {
+		if i >= limit {
+			rest = append(rest, e)
+			continue
+		}
+		d := deliver(ctx, e, repository.Task{TaskID: e.TaskID, Status: "pending"})
+		if d.Err != nil {
+			res.Failed++
+			s.retryAt[e.TaskID] = d.RetryAt
+			continue
+		}
+		res.Delivered++
+		s.delivered = append(s.delivered, e.TaskID)
+	}
+	s.entries = rest
+	return res, nil
+}
+
+func (s *fakeStore) GetOutboxStats(_ context.Context) (repository.OutboxStats, error) {
+	return repository.OutboxStats{Pending: len(s.entries)}, nil
+}
+
+func (s *fakeStore) PurgeDeliveredOutbox(_ context.Context, _ time.Time, _ int) (int, error) {
+	s.purged++
+	return 0, nil
+}
+
+type fakeEnqueuer struct {
+	failing map[string]bool
+	queued  []string
+}
+
+func (e *fakeEnqueuer) DeliverTask(_ context.Context, t repository.Task, _ *time.Time) error {
+	if e.failing[t.TaskID] {
+		return errors.New("redis down")
+	}
+	e.queued = append(e.queued, t.TaskID)
+	return nil
+}
+
+var now = time.Date(2026, 3, 12, 12, 0, 0, 0, time.UTC)
+
+func newTestRelay(store Store, enqueuer Enqueuer) *Relay {
+	r := New(store, enqueuer, config.OutboxConfig{BatchSize: 2, Retention: time.Hour})
+	r.now = func() time.Time { return now }
+	return r
+}
+
+func entries(ids ...string) []repository.OutboxEntry {
+	out := make([]repository.OutboxEntry, 0, len(ids))
+	for i, id := range ids {
+		out = append(out, repository.OutboxEntry{ID: int64(i + 1), TaskID: id})
+	}
+	return out
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, time.Second, retryDelay(0))
+	assert.Equal(t, 8*time.Second, retryDelay(3))
+	assert.Equal(t, maxRetryDelay, retryDelay(9))
+	assert.Equal(t, maxRetryDelay, retryDelay(100))
+}
+
+func TestRunOnce(t *testing.T) {
+	store := &fakeStore{entries: entries("a", "b", "c"), retryAt: make(map[string]time.Time)}
+	enqueuer := &fakeEnqueuer{}
+
+	res, err := newTestRelay(store, enqueuer).RunOnce(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, res.Delivered)
+	assert.Equal(t, 2, store.calls, "按 BatchSize 分 2 批投递")
+	assert.Equal(t, []string{"a", "b", "c"}, store.delivered)
+	assert.Equal(t, []string{"a", "b", "c"}, enqueuer.queued)
+	assert.Equal(t, 1, store.purged, "首轮执行清理")
+}
+
+func TestRunOnce_StopsOnFailure(t *testing.T) {
+	store := &fakeStore{entries: entries("a", "b", "c", "d"), retryAt: make(map[string]time.Time)}
+	enqueuer := &fakeEnqueuer{failing: map[string]bool{"b": true}}
+
+	relay := newTestRelay(store, enqueuer)
+	res, err := relay.RunOnce(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, res.Delivered)
+	assert.Equal(t, 1, res.Failed)
+	assert.Equal(t, 1, store.calls, "本批有失败时等待下一轮")
+	assert.Equal(t, now.Add(time.Second), store.retryAt["b"])
+
+	// 一小时内不重复清理
+	_, err = relay.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.purged)
+}
@@ -0,0 +1,43 @@
+package periodic
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Loop 后台周期执行的协程：启动时立即执行一次，之后按固定间隔执行，Stop 时取消并等待当前一轮结束。
+// 零值可用，保留策略、状态对账、失联检测、出箱投递与批量操作共用。
+type Loop struct {
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Start 启动后台协程（立即执行一次 fn，之后每隔 interval 执行一次）
+func (l *Loop) Start(interval time.Duration, fn func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			fn(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop 取消 fn 的上下文并等待当前一轮执行结束（未启动时直接返回）
+func (l *Loop) Stop() {
+	if l.cancel != nil {
+		l.cancel()
+	}
+	l.wg.Wait()
+}
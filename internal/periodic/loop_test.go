@@ -0,0 +1,51 @@
+package periodic
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoop(t *testing.T) {
+	var runs atomic.Int32
+	var l Loop
+	l.Start(10*time.Millisecond, func(ctx context.Context) {
+		runs.Add(1)
+	})
+
+	assert.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, 5*time.Millisecond, "立即执行一次，之后按间隔执行")
+
+	l.Stop()
+	n := runs.Load()
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, n, runs.Load(), "Stop 后不再执行")
+}
+
+func TestLoop_StopCancelsRun(t *testing.T) {
+	started := make(chan struct{})
+	var l Loop
+	l.Start(time.Hour, func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+	})
+
+	<-started
+	done := make(chan struct{})
+	go func() {
+		l.Stop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Stop 未取消正在执行的一轮")
+	}
+}
+
+func TestLoop_StopWithoutStart(t *testing.T) {
+	var l Loop
+	assert.NotPanics(t, l.Stop)
+}
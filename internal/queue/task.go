@@ -18,14 +18,16 @@ func NewTaskID() string {
 }
 
 type EnqueueParams struct {
-	TaskType       string
-	TaskKey        string
-	Queue          string
-	MaxRetry       int32
-	TimeoutSeconds int32
-	DelaySeconds   int32
-	RunAt          time.Time
-	Payload        json.RawMessage
+	TaskType         string
+	TaskKey          string
+	Queue            string
+	MaxRetry         int32 // 0 表示不重试
+	TimeoutSeconds   int32
+	DelaySeconds     int32
+	RunAt            time.Time
+	Deadline         time.Time
+	RetentionSeconds int32 // 完成后在 Redis 中保留的时长
+	Payload          json.RawMessage
 }
 
 func EnqueueOptions(p EnqueueParams) []asynq.Option {
@@ -34,12 +36,18 @@ func EnqueueOptions(p EnqueueParams) []asynq.Option {
 	if p.Queue != "" {
 		opts = append(opts, asynq.Queue(p.Queue))
 	}
-	if p.MaxRetry > 0 {
+	if p.MaxRetry >= 0 {
 		opts = append(opts, asynq.MaxRetry(int(p.MaxRetry)))
 	}
 	if p.TimeoutSeconds > 0 {
 		opts = append(opts, asynq.Timeout(time.Duration(p.TimeoutSeconds)*time.Second))
 	}
+	if !p.Deadline.IsZero() {
+		opts = append(opts, asynq.Deadline(p.Deadline))
+	}
+	if p.RetentionSeconds > 0 {
+		opts = append(opts, asynq.Retention(time.Duration(p.RetentionSeconds)*time.Second))
+	}
 	if p.DelaySeconds > 0 {
 		opts = append(opts, asynq.ProcessIn(time.Duration(p.DelaySeconds)*time.Second))
 	}
@@ -47,9 +55,10 @@ func EnqueueOptions(p EnqueueParams) []asynq.Option {
 		opts = append(opts, asynq.ProcessAt(p.RunAt))
 	}
 
-	// 幂等：同一个 task_key 24h 内只允许一次（避免误触发重复入队）
+	// 以 task_key 作为 asynq 任务 ID：同一任务仍在 Redis 中（排队/执行/重试/保留期内）时入队返回 ErrTaskIDConflict。
+	// 客户端重试的去重由 Idempotency-Key（IDEMPOTENCY_TTL）负责，这里不再使用固定窗口的 Unique。
 	if p.TaskKey != "" {
-		opts = append(opts, asynq.Unique(24*time.Hour))
+		opts = append(opts, asynq.TaskID(p.TaskKey))
 	}
 
 	return opts
@@ -0,0 +1,221 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/azhengyongqin/asynq-hub/internal/config"
+	"github.com/azhengyongqin/asynq-hub/internal/logger"
+	"github.com/azhengyongqin/asynq-hub/internal/metrics"
+	"github.com/azhengyongqin/asynq-hub/internal/model"
+	"github.com/azhengyongqin/asynq-hub/internal/periodic"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+)
+
+// batchPause 两批检查之间的停顿，避免集中访问 Redis 与数据库
+const batchPause = 100 * time.Millisecond
+
+// CheckedStatuses 对账检查的任务状态：已入队且未结束（waiting 尚未入队，不在 Redis 中）
+var CheckedStatuses = []string{
+	string(model.TaskStatusPending),
+	string(model.TaskStatusRunning),
+	string(model.TaskStatusFail),
+}
+
+// Store 对账用到的任务存储操作（repository.TaskRepository 满足该接口）
+type Store interface {
+	ListTasksForReconcile(ctx context.Context, statuses []string, before time.Time, afterID int64, limit int) ([]repository.Task, error)
+	TransitionTaskStatus(ctx context.Context, taskID, from, to, asynqTaskID, lastError string) (bool, error)
+	CountAttempts(ctx context.Context, taskID string) (int, error)
+	InsertTaskLogs(ctx context.Context, logs []repository.TaskLog) error
+}
+
+// Inspector 查询任务在 Redis 中的实际状态（*asynq.Inspector 满足该接口）
+type Inspector interface {
+	GetTaskInfo(queue, id string) (*asynq.TaskInfo, error)
+}
+
+// Finisher 任务被修正为终态后的后续处理（入队回调任务、推进工作流），由 handler.TaskHandler 实现
+type Finisher interface {
+	FinishTask(ctx context.Context, task *repository.Task)
+}
+
+// Result 一轮对账的结果
+type Result struct {
+	Checked   int // 检查的任务数
+	Corrected int // 修正状态的任务数
+}
+
+// correction 对单个任务状态的修正
+type correction struct {
+	to        model.TaskStatus
+	reason    string // 修正原因，写入任务日志
+	lastError string // 写入 last_error，为空时使用 reason（修正为 success 时清空）
+}
+
+// Job 对账后台任务：遍历未结束的任务记录，按 asynq Inspector 查询到的实际状态修正 Postgres 中的状态。
+// 状态通过 TransitionTaskStatus 条件更新，与 worker 上报并发时以先到者为准。
+type Job struct {
+	store     Store
+	inspector Inspector
+	finisher  Finisher
+	cfg       config.ReconcileConfig
+	now       func() time.Time
+
+	loop periodic.Loop
+}
+
+// New 创建对账任务，finisher 可以为 nil
+func New(store Store, inspector Inspector, finisher Finisher, cfg config.ReconcileConfig) *Job {
+	return &Job{store: store, inspector: inspector, finisher: finisher, cfg: cfg, now: time.Now}
+}
+
+// Start 启动后台对账（立即执行一次，之后按 Interval 执行）
+func (j *Job) Start() {
+	j.loop.Start(j.cfg.Interval, func(ctx context.Context) { _, _ = j.RunOnce(ctx) })
+}
+
+// Stop 停止后台对账并等待当前批次结束
+func (j *Job) Stop() {
+	j.loop.Stop()
+}
+
+// RunOnce 执行一轮对账。单个任务查询 Redis 失败时跳过该任务，数据库错误时结束本轮。
+func (j *Job) RunOnce(ctx context.Context) (Result, error) {
+	var res Result
+	before := j.now().Add(-j.cfg.MinAge)
+
+	var afterID int64
+	for {
+		tasks, err := j.store.ListTasksForReconcile(ctx, CheckedStatuses, before, afterID, j.cfg.BatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				metrics.RecordError("reconciler", "list")
+				logger.L.Error().Err(err).Msg("查询待对账任务失败")
+			}
+			return res, err
+		}
+
+		for _, t := range tasks {
+			afterID = t.ID
+			res.Checked++
+			metrics.ReconcileCheckedTotal.Inc()
+
+			c, ok, err := j.check(ctx, t)
+			if err != nil {
+				metrics.RecordError("reconciler", "inspect")
+				logger.L.Warn().Err(err).Str("task_id", t.TaskID).Msg("查询任务 Redis 状态失败")
+				continue
+			}
+			if !ok {
+				continue
+			}
+			corrected, err := j.apply(ctx, t, c)
+			if err != nil {
+				if ctx.Err() == nil {
+					metrics.RecordError("reconciler", "update")
+					logger.L.Error().Err(err).Str("task_id", t.TaskID).Msg("修正任务状态失败")
+				}
+				return res, err
+			}
+			if corrected {
+				res.Corrected++
+			}
+		}
+
+		if len(tasks) < j.cfg.BatchSize {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return res, ctx.Err()
+		case <-time.After(batchPause):
+		}
+	}
+
+	metrics.ReconcileLastSuccess.Set(float64(j.now().Unix()))
+	if res.Corrected > 0 {
+		logger.L.Info().Int("checked", res.Checked).Int("corrected", res.Corrected).Msg("任务状态对账完成")
+	}
+	return res, nil
+}
+
+// check 查询任务在 Redis 中的状态，返回需要的修正
+func (j *Job) check(ctx context.Context, t repository.Task) (correction, bool, error) {
+	info, err := j.inspector.GetTaskInfo(t.Queue, t.AsynqID())
+	switch {
+	case errors.Is(err, asynq.ErrTaskNotFound), errors.Is(err, asynq.ErrQueueNotFound):
+		n, err := j.store.CountAttempts(ctx, t.TaskID)
+		if err != nil {
+			return correction{}, false, err
+		}
+		return goneCorrection(n > 0), true, nil
+	case err != nil:
+		return correction{}, false, err
+	}
+	c, ok := stateCorrection(model.TaskStatus(t.Status), info)
+	return c, ok, nil
+}
+
+// goneCorrection Redis 中已不存在任务时的修正：从未执行过视为被删除（如清空队列），
+// 执行过则是结果丢失（完成后未保留、上报失败等）
+func goneCorrection(executed bool) correction {
+	if executed {
+		return correction{to: model.TaskStatusLost, reason: "Redis 中已不存在该任务，且未收到最终执行结果"}
+	}
+	return correction{to: model.TaskStatusDeleted, reason: "Redis 中已不存在该任务且从未执行（可能被清空队列或手动删除）"}
+}
+
+// stateCorrection 根据任务在 Redis 中的状态判断是否需要修正：
+// 已归档说明不会再执行（重试耗尽或不再重试），已完成说明成功上报丢失，
+// 等待重试而记录仍为 running 说明本次执行的失败上报丢失；其余状态仍在正常流转，不修正
+func stateCorrection(status model.TaskStatus, info *asynq.TaskInfo) (correction, bool) {
+	switch info.State {
+	case asynq.TaskStateArchived:
+		return correction{to: model.TaskStatusDead, reason: "任务已在 Redis 中归档，不会再执行", lastError: info.LastErr}, true
+	case asynq.TaskStateCompleted:
+		return correction{to: model.TaskStatusSuccess, reason: "任务已在 Redis 中完成，但未收到成功上报"}, true
+	case asynq.TaskStateRetry:
+		if status == model.TaskStatusRunning {
+			return correction{to: model.TaskStatusFail, reason: "任务在 Redis 中等待重试，但未收到失败上报", lastError: info.LastErr}, true
+		}
+	}
+	return correction{}, false
+}
+
+// apply 按条件更新任务状态并记录原因，返回是否修正成功（状态已被并发修改时返回 false）
+func (j *Job) apply(ctx context.Context, t repository.Task, c correction) (bool, error) {
+	lastError := c.lastError
+	if lastError == "" && c.to != model.TaskStatusSuccess {
+		lastError = c.reason
+	}
+
+	ok, err := j.store.TransitionTaskStatus(ctx, t.TaskID, t.Status, string(c.to), "", lastError)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	metrics.ReconcileCorrectedTotal.WithLabelValues(t.Status, string(c.to)).Inc()
+	message := fmt.Sprintf("对账修正任务状态 %s → %s：%s", t.Status, c.to, c.reason)
+	logger.L.Warn().Str("task_id", t.TaskID).Str("from", t.Status).Str("to", string(c.to)).Msg(message)
+	if err := j.store.InsertTaskLogs(ctx, []repository.TaskLog{{
+		TaskID:   t.TaskID,
+		Attempt:  t.LastAttempt,
+		Level:    "warn",
+		Message:  message,
+		LoggedAt: j.now(),
+	}}); err != nil {
+		logger.L.Warn().Err(err).Str("task_id", t.TaskID).Msg("记录对账日志失败")
+	}
+
+	if c.to.IsTerminal() && j.finisher != nil {
+		t.Status = string(c.to)
+		t.LastError = lastError
+		j.finisher.FinishTask(ctx, &t)
+	}
+	return true, nil
+}
@@ -0,0 +1,165 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/azhengyongqin/asynq-hub/internal/config"
+	"github.com/azhengyongqin/asynq-hub/internal/model"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+)
+
+// fakeStore 内存任务存储，记录状态修正与对账日志
+type fakeStore struct {
+	tasks    []repository.Task
+	attempts map[string]int
+	logs     []repository.TaskLog
+	stolen   map[string]bool // 模拟状态已被并发上报修改，条件更新不命中
+}
+
+func (s *fakeStore) ListTasksForReconcile(_ context.Context, statuses []string, _ time.Time, afterID int64, limit int) ([]repository.Task, error) {
+	var out []repository.Task
+	for _, t := range s.tasks {
+		if t.ID > afterID && len(out) < limit {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (s *fakeStore) TransitionTaskStatus(_ context.Context, taskID, from, to, _, lastError string) (bool, error) {
+	if s.stolen[taskID] {
+		return false, nil
+	}
+	for i := range s.tasks {
+		if s.tasks[i].TaskID == taskID && s.tasks[i].Status == from {
+			s.tasks[i].Status = to
+			s.tasks[i].LastError = lastError
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *fakeStore) CountAttempts(_ context.Context, taskID string) (int, error) {
+	return s.attempts[taskID], nil
+}
+
+func (s *fakeStore) InsertTaskLogs(_ context.Context, logs []repository.TaskLog) error {
+	s.logs = append(s.logs, logs...)
+	return nil
+}
+
+func (s *fakeStore) status(taskID string) string {
+	for _, t := range s.tasks {
+		if t.TaskID == taskID {
+			return t.Status
+		}
+	}
+	return ""
+}
+
+// fakeInspector 按 asynq 任务 ID 返回状态，未配置的返回 ErrTaskNotFound
+type fakeInspector struct {
+	infos map[string]*asynq.TaskInfo
+	errs  map[string]error
+}
+
+func (i *fakeInspector) GetTaskInfo(_, id string) (*asynq.TaskInfo, error) {
+	if err := i.errs[id]; err != nil {
+		return nil, err
+	}
+	if info, ok := i.infos[id]; ok {
+		return info, nil
+	}
+	return nil, asynq.ErrTaskNotFound
+}
+
+type fakeFinisher struct {
+	finished []string
+}
+
+func (f *fakeFinisher) FinishTask(_ context.Context, t *repository.Task) {
+	f.finished = append(f.finished, t.TaskID+":"+t.Status)
+}
+
+func TestStateCorrection(t *testing.T) {
+	tests := []struct {
+		name   string
+		status model.TaskStatus
+		state  asynq.TaskState
+		want   model.TaskStatus
+		ok     bool
+	}{
+		{name: "归档", status: model.TaskStatusPending, state: asynq.TaskStateArchived, want: model.TaskStatusDead, ok: true},
+		{name: "已完成", status: model.TaskStatusFail, state: asynq.TaskStateCompleted, want: model.TaskStatusSuccess, ok: true},
+		{name: "running 等待重试", status: model.TaskStatusRunning, state: asynq.TaskStateRetry, want: model.TaskStatusFail, ok: true},
+		{name: "fail 等待重试", status: model.TaskStatusFail, state: asynq.TaskStateRetry},
+		{name: "排队中", status: model.TaskStatusPending, state: asynq.TaskStatePending},
+		{name: "延迟执行", status: model.TaskStatusPending, state: asynq.TaskStateScheduled},
+		{name: "执行中", status: model.TaskStatusRunning, state: asynq.TaskStateActive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, ok := stateCorrection(tt.status, &asynq.TaskInfo{State: tt.state})
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, c.to)
+		})
+	}
+}
+
+func TestRunOnce(t *testing.T) {
+	task := func(id int64, taskID, status string) repository.Task {
+		return repository.Task{ID: id, TaskID: taskID, AsynqTaskID: taskID, Queue: "w:default:default", Status: status}
+	}
+	store := &fakeStore{
+		tasks: []repository.Task{
+			task(1, "cleared", "pending"),
+			task(2, "crashed", "running"),
+			task(3, "archived", "fail"),
+			task(4, "done", "pending"),
+			task(5, "queued", "pending"),
+			task(6, "redis-down", "pending"),
+			task(7, "raced", "pending"),
+		},
+		attempts: map[string]int{"crashed": 1},
+		stolen:   map[string]bool{"raced": true},
+	}
+	inspector := &fakeInspector{
+		infos: map[string]*asynq.TaskInfo{
+			"archived": {State: asynq.TaskStateArchived, LastErr: "boom"},
+			"done":     {State: asynq.TaskStateCompleted},
+			"queued":   {State: asynq.TaskStatePending},
+			"raced":    {State: asynq.TaskStateCompleted},
+		},
+		errs: map[string]error{"redis-down": errors.New("connection refused")},
+	}
+	finisher := &fakeFinisher{}
+
+	job := New(store, inspector, finisher, config.ReconcileConfig{BatchSize: 3, MinAge: time.Minute})
+	res, err := job.RunOnce(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 7, res.Checked, "分 3 批遍历全部任务")
+	assert.Equal(t, 4, res.Corrected)
+	assert.Equal(t, "deleted", store.status("cleared"))
+	assert.Equal(t, "lost", store.status("crashed"))
+	assert.Equal(t, "dead", store.status("archived"))
+	assert.Equal(t, "boom", store.tasks[2].LastError, "归档任务保留 asynq 中的最后错误")
+	assert.Equal(t, "success", store.status("done"))
+	assert.Empty(t, store.tasks[3].LastError)
+	assert.Equal(t, "pending", store.status("queued"))
+	assert.Equal(t, "pending", store.status("redis-down"), "查询 Redis 失败时不修正")
+	assert.Equal(t, "pending", store.status("raced"), "状态已被并发修改时不修正")
+
+	assert.Len(t, store.logs, 4, "每次修正记录一条原因")
+	assert.Contains(t, store.logs[0].Message, "pending → deleted")
+	assert.Equal(t, []string{"cleared:deleted", "crashed:lost", "archived:dead", "done:success"}, finisher.finished)
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BulkJobRepo 批量操作任务仓储实现
+type BulkJobRepo struct {
+	db *gorm.DB
+}
+
+// NewBulkJobRepo 创建批量操作任务仓储
+func NewBulkJobRepo(db *gorm.DB) *BulkJobRepo {
+	return &BulkJobRepo{db: db}
+}
+
+// CreateBulkJob 创建批量操作任务
+func (r *BulkJobRepo) CreateBulkJob(ctx context.Context, job BulkJob) error {
+	if job.JobID == "" {
+		return errors.New("job_id 不能为空")
+	}
+	filter, err := json.Marshal(job.Filter)
+	if err != nil {
+		return err
+	}
+
+	model := BulkJobModel{
+		JobID:     job.JobID,
+		Operation: job.Operation,
+		Filter:    filter,
+		DryRun:    job.DryRun,
+		Status:    "pending",
+	}
+	if job.Priority != "" {
+		model.Priority = &job.Priority
+	}
+	if job.Reason != "" {
+		model.Reason = &job.Reason
+	}
+	return r.db.WithContext(ctx).Create(&model).Error
+}
+
+// GetBulkJob 获取批量操作任务
+func (r *BulkJobRepo) GetBulkJob(ctx context.Context, jobID string) (*BulkJob, error) {
+	var model BulkJobModel
+	if err := r.db.WithContext(ctx).Where("job_id = ?", jobID).First(&model).Error; err != nil {
+		return nil, err
+	}
+	job := model.ToBulkJob()
+	return &job, nil
+}
+
+// ListBulkJobs 按创建时间倒序查询批量操作任务
+func (r *BulkJobRepo) ListBulkJobs(ctx context.Context, status string, limit int) ([]BulkJob, error) {
+	query := r.db.WithContext(ctx).Model(&BulkJobModel{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var models []BulkJobModel
+	if err := query.Order("id DESC").Limit(limit).Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	jobs := make([]BulkJob, len(models))
+	for i := range models {
+		jobs[i] = models[i].ToBulkJob()
+	}
+	return jobs, nil
+}
+
+// ListBulkJobItems 按写入顺序查询明细
+func (r *BulkJobRepo) ListBulkJobItems(ctx context.Context, jobID string, afterID int64, limit int) ([]BulkJobItem, error) {
+	var models []BulkJobItemModel
+	if err := r.db.WithContext(ctx).
+		Where("job_id = ? AND id > ?", jobID, afterID).
+		Order("id ASC").Limit(limit).
+		Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	items := make([]BulkJobItem, len(models))
+	for i, m := range models {
+		items[i] = BulkJobItem{ID: m.ID, TaskID: m.TaskID, Outcome: m.Outcome, Message: m.Message, CreatedAt: m.CreatedAt}
+	}
+	return items, nil
+}
+
+// CancelBulkJob 将未结束的批量操作任务标记为 canceled，执行中的批次在保存进度时发现并停止
+func (r *BulkJobRepo) CancelBulkJob(ctx context.Context, jobID string) (bool, error) {
+	now := time.Now()
+	res := r.db.WithContext(ctx).
+		Model(&BulkJobModel{}).
+		Where("job_id = ? AND status IN ?", jobID, []string{"pending", "running"}).
+		Updates(map[string]interface{}{
+			"status":      "canceled",
+			"finished_at": now,
+			"updated_at":  now,
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// ClaimBulkJob 按创建顺序抢占一个待执行或执行副本已失联（超过 staleBefore 未保存进度）的任务
+func (r *BulkJobRepo) ClaimBulkJob(ctx context.Context, staleBefore time.Time) (*BulkJob, error) {
+	var claimed *BulkJob
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var model BulkJobModel
+		err := tx.Where("status = ? OR (status = ? AND updated_at < ?)", "pending", "running", staleBefore).
+			Order("id ASC").
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			First(&model).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if model.StartedAt == nil {
+			model.StartedAt = &now
+		}
+		model.Status = "running"
+		model.UpdatedAt = now
+		if err := tx.Model(&BulkJobModel{}).Where("id = ?", model.ID).
+			Updates(map[string]interface{}{
+				"status":     model.Status,
+				"started_at": model.StartedAt,
+				"updated_at": now,
+			}).Error; err != nil {
+			return err
+		}
+
+		job := model.ToBulkJob()
+		claimed = &job
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// SaveBulkJobProgress 保存进度与游标并写入本批明细
+func (r *BulkJobRepo) SaveBulkJobProgress(ctx context.Context, job BulkJob, prevCursorID int64, items []BulkJobItem) (bool, error) {
+	saved := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&BulkJobModel{}).
+			Where("job_id = ? AND status = ? AND cursor_id = ?", job.JobID, "running", prevCursorID).
+			Updates(map[string]interface{}{
+				"total":       job.Total,
+				"processed":   job.Processed,
+				"succeeded":   job.Succeeded,
+				"skipped":     job.Skipped,
+				"failed":      job.Failed,
+				"cursor_id":   job.CursorID,
+				"max_task_id": job.MaxTaskID,
+				"updated_at":  time.Now(),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		saved = true
+
+		if len(items) == 0 {
+			return nil
+		}
+		models := make([]BulkJobItemModel, len(items))
+		for i, item := range items {
+			models[i] = BulkJobItemModel{JobID: job.JobID, TaskID: item.TaskID, Outcome: item.Outcome, Message: item.Message}
+		}
+		return tx.Create(&models).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return saved, nil
+}
+
+// FinishBulkJob 结束执行中的批量操作任务
+func (r *BulkJobRepo) FinishBulkJob(ctx context.Context, jobID, status, errMsg string) (bool, error) {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":      status,
+		"finished_at": now,
+		"updated_at":  now,
+	}
+	if errMsg != "" {
+		updates["error"] = errMsg
+	}
+
+	res := r.db.WithContext(ctx).
+		Model(&BulkJobModel{}).
+		Where("job_id = ? AND status = ?", jobID, "running").
+		Updates(updates)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
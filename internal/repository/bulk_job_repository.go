@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// BulkJob 异步批量操作任务
+type BulkJob struct {
+	JobID     string          `json:"job_id"`
+	Operation string          `json:"operation"` // retry/cancel/delete/change_priority
+	Filter    ListTasksFilter `json:"filter"`
+	DryRun    bool            `json:"dry_run"`            // 只统计会被操作与被跳过的任务，不实际执行
+	Priority  string          `json:"priority,omitempty"` // change_priority 的目标优先级
+	Reason    string          `json:"reason,omitempty"`   // cancel 的取消原因
+	Status    string          `json:"status"`             // pending/running/completed/failed/canceled
+
+	// 进度：Total 为开始执行时匹配的任务数；dry_run 时 Succeeded 为会被操作的任务数
+	Total     int `json:"total"`
+	Processed int `json:"processed"`
+	Succeeded int `json:"succeeded"`
+	Skipped   int `json:"skipped"`
+	Failed    int `json:"failed"`
+
+	// 遍历状态：按 task.id 升序处理到 CursorID，只处理 id 不大于 MaxTaskID 的任务
+	CursorID  int64 `json:"-"`
+	MaxTaskID int64 `json:"-"`
+
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// BulkJobItem 批量操作中被跳过或处理失败的任务
+type BulkJobItem struct {
+	ID        int64     `json:"id"`
+	TaskID    string    `json:"task_id"`
+	Outcome   string    `json:"outcome"` // skipped/failed
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BulkJobRepository 批量操作任务仓储接口
+type BulkJobRepository interface {
+	// CreateBulkJob 创建批量操作任务（状态为 pending）
+	CreateBulkJob(ctx context.Context, job BulkJob) error
+
+	// GetBulkJob 根据 job_id 获取批量操作任务
+	GetBulkJob(ctx context.Context, jobID string) (*BulkJob, error)
+
+	// ListBulkJobs 按创建时间倒序查询批量操作任务（status 为空时不过滤）
+	ListBulkJobs(ctx context.Context, status string, limit int) ([]BulkJob, error)
+
+	// ListBulkJobItems 按写入顺序查询 id 大于 afterID 的明细
+	ListBulkJobItems(ctx context.Context, jobID string, afterID int64, limit int) ([]BulkJobItem, error)
+
+	// CancelBulkJob 将 pending/running 的批量操作任务标记为 canceled，返回是否命中
+	CancelBulkJob(ctx context.Context, jobID string) (bool, error)
+
+	// ClaimBulkJob 抢占一个待执行或 updated_at 早于 staleBefore 的执行中任务（多副本互斥），
+	// 将其置为 running 并刷新 updated_at；没有可执行的任务时返回 nil
+	ClaimBulkJob(ctx context.Context, staleBefore time.Time) (*BulkJob, error)
+
+	// SaveBulkJobProgress 保存进度与游标并写入本批明细，仅当任务仍为 running 且游标仍为 prevCursorID 时生效；
+	// 返回 false 表示任务已被取消或被其他副本接管，应停止执行
+	SaveBulkJobProgress(ctx context.Context, job BulkJob, prevCursorID int64, items []BulkJobItem) (bool, error)
+
+	// FinishBulkJob 将 running 的批量操作任务标记为 status（completed/failed）并记录错误，返回是否命中
+	FinishBulkJob(ctx context.Context, jobID, status, errMsg string) (bool, error)
+}
@@ -11,6 +11,7 @@ type TaskModel struct {
 	TaskID         string          `gorm:"column:task_id;uniqueIndex;type:text;not null"`
 	WorkerName     string          `gorm:"column:worker_name;type:text;not null;index:idx_task_worker_created_at"`
 	Queue          string          `gorm:"column:queue;type:text;not null;index:idx_task_queue_updated_at"`
+	AsynqTaskID    *string         `gorm:"column:asynq_task_id;type:text"`
 	Priority       int             `gorm:"column:priority;default:0"`
 	Payload        json.RawMessage `gorm:"column:payload;type:jsonb;not null"`
 	Status         string          `gorm:"column:status;type:text;not null;index:idx_task_status_updated_at"`
@@ -18,6 +19,19 @@ type TaskModel struct {
 	LastError      *string         `gorm:"column:last_error;type:text"`
 	LastWorkerName *string         `gorm:"column:last_worker_name;type:text"`
 	TraceID        *string         `gorm:"column:trace_id;type:text"`
+	MaxRetry       *int32          `gorm:"column:max_retry"`
+	TimeoutSeconds *int32          `gorm:"column:timeout_seconds"`
+	Deadline       *time.Time      `gorm:"column:deadline"`
+	RetentionSecs  *int32          `gorm:"column:retention_seconds"`
+	ExpiresAt      *time.Time      `gorm:"column:expires_at"`
+	WorkflowID     *string         `gorm:"column:workflow_id;type:text;index:idx_task_workflow_id"`
+	DependsOn      json.RawMessage `gorm:"column:depends_on;type:jsonb"`
+	OnSuccess      json.RawMessage `gorm:"column:on_success;type:jsonb"`
+	OnFailure      json.RawMessage `gorm:"column:on_failure;type:jsonb"`
+	ParentTaskID   *string         `gorm:"column:parent_task_id;type:text;index:idx_task_parent_task_id"`
+	ReplayedFrom   *string         `gorm:"column:replayed_from;type:text;index:idx_task_replayed_from"`
+	Result         json.RawMessage `gorm:"column:result;type:jsonb"`
+	Labels         json.RawMessage `gorm:"column:labels;type:jsonb;not null;default:'{}'"`
 	CreatedAt      time.Time       `gorm:"column:created_at;autoCreateTime;index:idx_task_worker_created_at,sort:desc"`
 	UpdatedAt      time.Time       `gorm:"column:updated_at;autoUpdateTime;index:idx_task_status_updated_at,sort:desc;index:idx_task_queue_updated_at,sort:desc"`
 }
@@ -28,15 +42,22 @@ func (TaskModel) TableName() string { return "task" }
 // ToTask 转换为 Task 实体
 func (m *TaskModel) ToTask() Task {
 	t := Task{
-		TaskID:      m.TaskID,
-		WorkerName:  m.WorkerName,
-		Queue:       m.Queue,
-		Priority:    m.Priority,
-		Payload:     m.Payload,
-		Status:      m.Status,
-		LastAttempt: m.LastAttempt,
-		CreatedAt:   m.CreatedAt,
-		UpdatedAt:   m.UpdatedAt,
+		ID:               m.ID,
+		TaskID:           m.TaskID,
+		WorkerName:       m.WorkerName,
+		Queue:            m.Queue,
+		Priority:         m.Priority,
+		Payload:          m.Payload,
+		Status:           m.Status,
+		LastAttempt:      m.LastAttempt,
+		MaxRetry:         m.MaxRetry,
+		TimeoutSeconds:   m.TimeoutSeconds,
+		Deadline:         m.Deadline,
+		RetentionSeconds: m.RetentionSecs,
+		ExpiresAt:        m.ExpiresAt,
+		Result:           m.Result,
+		CreatedAt:        m.CreatedAt,
+		UpdatedAt:        m.UpdatedAt,
 	}
 	if m.LastError != nil {
 		t.LastError = *m.LastError
@@ -47,21 +68,51 @@ func (m *TaskModel) ToTask() Task {
 	if m.TraceID != nil {
 		t.TraceID = *m.TraceID
 	}
+	if m.AsynqTaskID != nil {
+		t.AsynqTaskID = *m.AsynqTaskID
+	}
+	if m.WorkflowID != nil {
+		t.WorkflowID = *m.WorkflowID
+	}
+	if m.DependsOn != nil {
+		_ = json.Unmarshal(m.DependsOn, &t.DependsOn)
+	}
+	if m.OnSuccess != nil {
+		_ = json.Unmarshal(m.OnSuccess, &t.OnSuccess)
+	}
+	if m.OnFailure != nil {
+		_ = json.Unmarshal(m.OnFailure, &t.OnFailure)
+	}
+	if m.Labels != nil {
+		_ = json.Unmarshal(m.Labels, &t.Labels)
+	}
+	if m.ParentTaskID != nil {
+		t.ParentTaskID = *m.ParentTaskID
+	}
+	if m.ReplayedFrom != nil {
+		t.ReplayedFrom = *m.ReplayedFrom
+	}
 	return t
 }
 
 // TaskFromModel 从 Task 实体创建模型
 func TaskToModel(t Task) TaskModel {
 	m := TaskModel{
-		TaskID:      t.TaskID,
-		WorkerName:  t.WorkerName,
-		Queue:       t.Queue,
-		Priority:    t.Priority,
-		Payload:     t.Payload,
-		Status:      t.Status,
-		LastAttempt: t.LastAttempt,
-		CreatedAt:   t.CreatedAt,
-		UpdatedAt:   t.UpdatedAt,
+		TaskID:         t.TaskID,
+		WorkerName:     t.WorkerName,
+		Queue:          t.Queue,
+		Priority:       t.Priority,
+		Payload:        t.Payload,
+		Status:         t.Status,
+		LastAttempt:    t.LastAttempt,
+		MaxRetry:       t.MaxRetry,
+		TimeoutSeconds: t.TimeoutSeconds,
+		Deadline:       t.Deadline,
+		RetentionSecs:  t.RetentionSeconds,
+		ExpiresAt:      t.ExpiresAt,
+		Result:         t.Result,
+		CreatedAt:      t.CreatedAt,
+		UpdatedAt:      t.UpdatedAt,
 	}
 	if t.LastError != "" {
 		m.LastError = &t.LastError
@@ -72,6 +123,31 @@ func TaskToModel(t Task) TaskModel {
 	if t.TraceID != "" {
 		m.TraceID = &t.TraceID
 	}
+	if t.AsynqTaskID != "" {
+		m.AsynqTaskID = &t.AsynqTaskID
+	}
+	if t.WorkflowID != "" {
+		m.WorkflowID = &t.WorkflowID
+	}
+	if len(t.DependsOn) > 0 {
+		m.DependsOn, _ = json.Marshal(t.DependsOn)
+	}
+	if t.OnSuccess != nil {
+		m.OnSuccess, _ = json.Marshal(t.OnSuccess)
+	}
+	if t.OnFailure != nil {
+		m.OnFailure, _ = json.Marshal(t.OnFailure)
+	}
+	if t.ParentTaskID != "" {
+		m.ParentTaskID = &t.ParentTaskID
+	}
+	if t.ReplayedFrom != "" {
+		m.ReplayedFrom = &t.ReplayedFrom
+	}
+	m.Labels = json.RawMessage("{}")
+	if len(t.Labels) > 0 {
+		m.Labels, _ = json.Marshal(t.Labels)
+	}
 	return m
 }
 
@@ -144,14 +220,14 @@ func WorkerConfigToModel(c WorkerConfig) WorkerModel {
 	return m
 }
 
-// TaskAttemptModel GORM 模型 - 对应 task_attempt 表
+// TaskAttemptModel GORM 模型 - 对应 task_attempt 表（按 started_at 月份分区，主键包含分区键）
 type TaskAttemptModel struct {
 	ID          int64      `gorm:"primaryKey;autoIncrement;column:id"`
-	TaskID      string     `gorm:"column:task_id;type:text;not null;index:idx_attempt_task_key_started_at"`
+	TaskID      string     `gorm:"column:task_id;type:text;not null;index:idx_attempt_task_started_at_id"`
 	AsynqTaskID *string    `gorm:"column:asynq_task_id;type:text"`
 	Attempt     int        `gorm:"column:attempt;not null"`
 	Status      string     `gorm:"column:status;type:text;not null;index:idx_attempt_status_started_at"`
-	StartedAt   time.Time  `gorm:"column:started_at;not null;index:idx_attempt_task_key_started_at,sort:desc;index:idx_attempt_status_started_at,sort:desc"`
+	StartedAt   time.Time  `gorm:"primaryKey;column:started_at;not null;index:idx_attempt_task_started_at_id,sort:desc;index:idx_attempt_status_started_at,sort:desc"`
 	FinishedAt  *time.Time `gorm:"column:finished_at"`
 	DurationMs  *int       `gorm:"column:duration_ms"`
 	Error       *string    `gorm:"column:error;type:text"`
@@ -166,6 +242,7 @@ func (TaskAttemptModel) TableName() string { return "task_attempt" }
 // ToAttempt 转换为 Attempt 实体
 func (m *TaskAttemptModel) ToAttempt() Attempt {
 	a := Attempt{
+		ID:         m.ID,
 		TaskID:     m.TaskID,
 		Attempt:    m.Attempt,
 		Status:     m.Status,
@@ -218,3 +295,243 @@ func AttemptToModel(a Attempt) TaskAttemptModel {
 	}
 	return m
 }
+
+// WorkflowModel GORM 模型 - 对应 workflow 表
+type WorkflowModel struct {
+	ID         int64     `gorm:"primaryKey;autoIncrement;column:id"`
+	WorkflowID string    `gorm:"column:workflow_id;uniqueIndex;type:text;not null"`
+	Name       string    `gorm:"column:name;type:text;not null"`
+	Status     string    `gorm:"column:status;type:text;not null;index:idx_workflow_status_created_at"`
+	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime;index:idx_workflow_status_created_at,sort:desc"`
+	UpdatedAt  time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (WorkflowModel) TableName() string { return "workflow" }
+
+// ToWorkflow 转换为 Workflow 实体
+func (m *WorkflowModel) ToWorkflow() Workflow {
+	return Workflow{
+		WorkflowID: m.WorkflowID,
+		Name:       m.Name,
+		Status:     m.Status,
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+	}
+}
+
+// ScheduleModel GORM 模型 - 对应 schedule 表
+type ScheduleModel struct {
+	ID         int64           `gorm:"primaryKey;autoIncrement;column:id"`
+	ScheduleID string          `gorm:"column:schedule_id;uniqueIndex;type:text;not null"`
+	Name       string          `gorm:"column:name;type:text;not null"`
+	CronSpec   string          `gorm:"column:cron_spec;type:text;not null"`
+	Timezone   string          `gorm:"column:timezone;type:text;not null;default:UTC"`
+	WorkerName string          `gorm:"column:worker_name;type:text;not null"`
+	Queue      string          `gorm:"column:queue;type:text;not null"`
+	Priority   string          `gorm:"column:priority;type:text;not null"`
+	Payload    json.RawMessage `gorm:"column:payload;type:jsonb;not null"`
+	Enabled    bool            `gorm:"column:enabled;not null;default:true;index:idx_schedule_enabled"`
+	LastRunAt  *time.Time      `gorm:"column:last_run_at"`
+	NextRunAt  *time.Time      `gorm:"column:next_run_at"`
+	LastTaskID *string         `gorm:"column:last_task_id;type:text"`
+	LastError  *string         `gorm:"column:last_error;type:text"`
+	CreatedAt  time.Time       `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt  time.Time       `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (ScheduleModel) TableName() string { return "schedule" }
+
+// ToSchedule 转换为 Schedule 实体
+func (m *ScheduleModel) ToSchedule() Schedule {
+	s := Schedule{
+		ScheduleID: m.ScheduleID,
+		Name:       m.Name,
+		CronSpec:   m.CronSpec,
+		Timezone:   m.Timezone,
+		WorkerName: m.WorkerName,
+		Queue:      m.Queue,
+		Priority:   m.Priority,
+		Payload:    m.Payload,
+		Enabled:    m.Enabled,
+		LastRunAt:  m.LastRunAt,
+		NextRunAt:  m.NextRunAt,
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+	}
+	if m.LastTaskID != nil {
+		s.LastTaskID = *m.LastTaskID
+	}
+	if m.LastError != nil {
+		s.LastError = *m.LastError
+	}
+	return s
+}
+
+// TaskProgressModel GORM 模型 - 对应 task_progress 表
+type TaskProgressModel struct {
+	TaskID    string    `gorm:"primaryKey;column:task_id;type:text"`
+	Attempt   int       `gorm:"primaryKey;column:attempt"`
+	Percent   int       `gorm:"column:percent;not null"`
+	Message   *string   `gorm:"column:message;type:text"`
+	UpdatedAt time.Time `gorm:"column:updated_at;not null"`
+}
+
+// TableName 指定表名
+func (TaskProgressModel) TableName() string { return "task_progress" }
+
+// ToTaskProgress 转换为 TaskProgress 实体
+func (m *TaskProgressModel) ToTaskProgress() TaskProgress {
+	p := TaskProgress{
+		TaskID:    m.TaskID,
+		Attempt:   m.Attempt,
+		Percent:   m.Percent,
+		UpdatedAt: m.UpdatedAt,
+	}
+	if m.Message != nil {
+		p.Message = *m.Message
+	}
+	return p
+}
+
+// TaskLogModel GORM 模型 - 对应 task_log 表
+type TaskLogModel struct {
+	ID       int64     `gorm:"primaryKey;autoIncrement;column:id"`
+	TaskID   string    `gorm:"column:task_id;type:text;not null;index:idx_task_log_task_attempt_id"`
+	Attempt  int       `gorm:"column:attempt;not null;index:idx_task_log_task_attempt_id"`
+	Level    string    `gorm:"column:level;type:text;not null"`
+	Message  string    `gorm:"column:message;type:text;not null"`
+	LoggedAt time.Time `gorm:"column:logged_at;not null"`
+}
+
+// TableName 指定表名
+func (TaskLogModel) TableName() string { return "task_log" }
+
+// ToTaskLog 转换为 TaskLog 实体
+func (m *TaskLogModel) ToTaskLog() TaskLog {
+	return TaskLog{
+		ID:       m.ID,
+		TaskID:   m.TaskID,
+		Attempt:  m.Attempt,
+		Level:    m.Level,
+		Message:  m.Message,
+		LoggedAt: m.LoggedAt,
+	}
+}
+
+// ScheduleRunModel GORM 模型 - 对应 schedule_run 表
+type ScheduleRunModel struct {
+	ID         int64     `gorm:"primaryKey;autoIncrement;column:id"`
+	ScheduleID string    `gorm:"column:schedule_id;type:text;not null;index:idx_schedule_run_schedule_fired_at"`
+	FiredAt    time.Time `gorm:"column:fired_at;not null;index:idx_schedule_run_schedule_fired_at,sort:desc"`
+	TaskID     *string   `gorm:"column:task_id;type:text"`
+	Status     string    `gorm:"column:status;type:text;not null"`
+	Error      *string   `gorm:"column:error;type:text"`
+}
+
+// TableName 指定表名
+func (ScheduleRunModel) TableName() string { return "schedule_run" }
+
+// ToScheduleRun 转换为 ScheduleRun 实体
+func (m *ScheduleRunModel) ToScheduleRun() ScheduleRun {
+	r := ScheduleRun{
+		ScheduleID: m.ScheduleID,
+		FiredAt:    m.FiredAt,
+		Status:     m.Status,
+	}
+	if m.TaskID != nil {
+		r.TaskID = *m.TaskID
+	}
+	if m.Error != nil {
+		r.Error = *m.Error
+	}
+	return r
+}
+
+// TaskOutboxModel GORM 模型 - 对应 task_outbox 表
+type TaskOutboxModel struct {
+	ID            int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	TaskID        string     `gorm:"column:task_id;type:text;not null;index:idx_task_outbox_task_id"`
+	ProcessAt     *time.Time `gorm:"column:process_at"`
+	Attempts      int        `gorm:"column:attempts;not null;default:0"`
+	LastError     *string    `gorm:"column:last_error;type:text"`
+	NextAttemptAt time.Time  `gorm:"column:next_attempt_at;not null"`
+	CreatedAt     time.Time  `gorm:"column:created_at;autoCreateTime"`
+	DeliveredAt   *time.Time `gorm:"column:delivered_at"`
+}
+
+// TableName 指定表名
+func (TaskOutboxModel) TableName() string { return "task_outbox" }
+
+// BulkJobModel GORM 模型 - 对应 bulk_job 表
+type BulkJobModel struct {
+	ID         int64           `gorm:"primaryKey;autoIncrement;column:id"`
+	JobID      string          `gorm:"column:job_id;uniqueIndex;type:text;not null"`
+	Operation  string          `gorm:"column:operation;type:text;not null"`
+	Filter     json.RawMessage `gorm:"column:filter;type:jsonb;not null"`
+	DryRun     bool            `gorm:"column:dry_run;not null;default:false"`
+	Priority   *string         `gorm:"column:priority;type:text"`
+	Reason     *string         `gorm:"column:reason;type:text"`
+	Status     string          `gorm:"column:status;type:text;not null;default:pending;index:idx_bulk_job_status_updated_at"`
+	Total      int             `gorm:"column:total;not null;default:0"`
+	Processed  int             `gorm:"column:processed;not null;default:0"`
+	Succeeded  int             `gorm:"column:succeeded;not null;default:0"`
+	Skipped    int             `gorm:"column:skipped;not null;default:0"`
+	Failed     int             `gorm:"column:failed;not null;default:0"`
+	CursorID   int64           `gorm:"column:cursor_id;not null;default:0"`
+	MaxTaskID  int64           `gorm:"column:max_task_id;not null;default:0"`
+	Error      *string         `gorm:"column:error;type:text"`
+	CreatedAt  time.Time       `gorm:"column:created_at;autoCreateTime"`
+	StartedAt  *time.Time      `gorm:"column:started_at"`
+	FinishedAt *time.Time      `gorm:"column:finished_at"`
+	UpdatedAt  time.Time       `gorm:"column:updated_at;autoUpdateTime;index:idx_bulk_job_status_updated_at"`
+}
+
+// TableName 指定表名
+func (BulkJobModel) TableName() string { return "bulk_job" }
+
+// ToBulkJob 转换为 BulkJob 实体
+func (m *BulkJobModel) ToBulkJob() BulkJob {
+	j := BulkJob{
+		JobID:      m.JobID,
+		Operation:  m.Operation,
+		DryRun:     m.DryRun,
+		Status:     m.Status,
+		Total:      m.Total,
+		Processed:  m.Processed,
+		Succeeded:  m.Succeeded,
+		Skipped:    m.Skipped,
+		Failed:     m.Failed,
+		CursorID:   m.CursorID,
+		MaxTaskID:  m.MaxTaskID,
+		CreatedAt:  m.CreatedAt,
+		StartedAt:  m.StartedAt,
+		FinishedAt: m.FinishedAt,
+		UpdatedAt:  m.UpdatedAt,
+	}
+	_ = json.Unmarshal(m.Filter, &j.Filter)
+	if m.Priority != nil {
+		j.Priority = *m.Priority
+	}
+	if m.Reason != nil {
+		j.Reason = *m.Reason
+	}
+	if m.Error != nil {
+		j.Error = *m.Error
+	}
+	return j
+}
+
+// BulkJobItemModel GORM 模型 - 对应 bulk_job_item 表
+type BulkJobItemModel struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement;column:id"`
+	JobID     string    `gorm:"column:job_id;type:text;not null;index:idx_bulk_job_item_job_id"`
+	TaskID    string    `gorm:"column:task_id;type:text;not null"`
+	Outcome   string    `gorm:"column:outcome;type:text;not null"`
+	Message   string    `gorm:"column:message;type:text;not null"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+// TableName 指定表名
+func (BulkJobItemModel) TableName() string { return "bulk_job_item" }
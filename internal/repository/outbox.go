@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrTaskExists 写入任务记录时 task_id 已存在
+var ErrTaskExists = errors.New("task_id 已存在")
+
+// CreateTaskWithOutbox 在同一事务中写入任务记录与出箱记录，task_id 已存在时返回 ErrTaskExists
+func (r *TaskRepo) CreateTaskWithOutbox(ctx context.Context, t Task, processAt *time.Time) error {
+	if t.TaskID == "" {
+		return errors.New("task_id 不能为空")
+	}
+	model := TaskToModel(t)
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "task_id"}},
+			DoNothing: true,
+		}).Create(&model)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrTaskExists
+		}
+		return tx.Create(&TaskOutboxModel{
+			TaskID:        t.TaskID,
+			ProcessAt:     processAt,
+			NextAttemptAt: time.Now(),
+		}).Error
+	})
+}
+
+// CreateTasksWithOutbox 在同一事务中逐条写入任务记录，并为新写入的任务批量写入出箱记录。
+// task_id 已存在的任务跳过（不写出箱记录），返回跳过的 task_id 集合；出错时整批回滚。
+func (r *TaskRepo) CreateTasksWithOutbox(ctx context.Context, tasks []OutboxTask) (map[string]struct{}, error) {
+	existing := make(map[string]struct{})
+	if len(tasks) == 0 {
+		return existing, nil
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		entries := make([]TaskOutboxModel, 0, len(tasks))
+		for _, t := range tasks {
+			if t.Task.TaskID == "" {
+				return errors.New("task_id 不能为空")
+			}
+			model := TaskToModel(t.Task)
+			res := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "task_id"}},
+				DoNothing: true,
+			}).Create(&model)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				existing[t.Task.TaskID] = struct{}{}
+				continue
+			}
+			entries = append(entries, TaskOutboxModel{TaskID: t.Task.TaskID, ProcessAt: t.ProcessAt, NextAttemptAt: now})
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		return tx.Create(&entries).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// TransitionTaskWithOutbox 在同一事务中将状态为 from 的任务置为 pending（asynq 任务 ID 即 task_id）并写入出箱记录，返回是否命中
+func (r *TaskRepo) TransitionTaskWithOutbox(ctx context.Context, taskID, from string) (bool, error) {
+	claimed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&TaskModel{}).
+			Where("task_id = ? AND status = ?", taskID, from).
+			Updates(map[string]interface{}{
+				"status":        "pending",
+				"asynq_task_id": taskID,
+				"updated_at":    time.Now(),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		claimed = true
+		return tx.Create(&TaskOutboxModel{TaskID: taskID, NextAttemptAt: time.Now()}).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return claimed, nil
+}
+
+// RelayOutbox 在单个事务中锁定一批到期未投递的出箱记录（SKIP LOCKED，多副本同时投递互不重复），
+// 逐条调用 deliver：成功的标记为已投递，失败的记录错误并推迟到 RetryAt 重新投递。
+// 事务提交失败时本批记录会被再次投递，deliver 需要容忍重复（至少一次）。
+func (r *TaskRepo) RelayOutbox(ctx context.Context, limit int, deliver func(ctx context.Context, e OutboxEntry, t Task) OutboxDelivery) (OutboxRelayResult, error) {
+	var res OutboxRelayResult
+	if limit <= 0 {
+		return res, nil
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var rows []TaskOutboxModel
+		if err := tx.Where("delivered_at IS NULL AND next_attempt_at <= ?", time.Now()).
+			Order("next_attempt_at ASC, id ASC").Limit(limit).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Find(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		taskIDs := make([]string, len(rows))
+		for i, row := range rows {
+			taskIDs[i] = row.TaskID
+		}
+		var models []TaskModel
+		if err := tx.Where("task_id IN ?", taskIDs).Find(&models).Error; err != nil {
+			return err
+		}
+		tasks := make(map[string]Task, len(models))
+		for _, m := range models {
+			tasks[m.TaskID] = m.ToTask()
+		}
+
+		for _, row := range rows {
+			// 任务记录被删除时出箱记录随之级联删除，这里只防御并发删除
+			var d OutboxDelivery
+			if t, ok := tasks[row.TaskID]; ok {
+				d = deliver(ctx, OutboxEntry{
+					ID:        row.ID,
+					TaskID:    row.TaskID,
+					ProcessAt: row.ProcessAt,
+					Attempts:  row.Attempts,
+					CreatedAt: row.CreatedAt,
+				}, t)
+			}
+
+			updates := map[string]interface{}{"attempts": row.Attempts + 1}
+			if d.Err == nil {
+				updates["delivered_at"] = time.Now()
+				updates["last_error"] = nil
+				res.Delivered++
+			} else {
+				updates["last_error"] = d.Err.Error()
+				updates["next_attempt_at"] = d.RetryAt
+				res.Failed++
+			}
+			if err := tx.Model(&TaskOutboxModel{}).Where("id = ?", row.ID).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return OutboxRelayResult{}, err
+	}
+	return res, nil
+}
+
+// GetOutboxStats 统计未投递的出箱记录
+func (r *TaskRepo) GetOutboxStats(ctx context.Context) (OutboxStats, error) {
+	var stats OutboxStats
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT count(*) AS pending, min(created_at) AS oldest_created_at
+		FROM task_outbox
+		WHERE delivered_at IS NULL
+	`).Scan(&stats).Error
+	return stats, err
+}
+
+// PurgeDeliveredOutbox 删除一批投递时间早于 before 的出箱记录，返回删除的行数
+func (r *TaskRepo) PurgeDeliveredOutbox(ctx context.Context, before time.Time, limit int) (int, error) {
+	if limit <= 0 {
+		return 0, nil
+	}
+	res := r.db.WithContext(ctx).Exec(`
+		DELETE FROM task_outbox WHERE id IN (
+			SELECT id FROM task_outbox WHERE delivered_at < ? ORDER BY delivered_at LIMIT ?
+		)`, before, limit)
+	return int(res.RowsAffected), res.Error
+}
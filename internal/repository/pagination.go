@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Cursor keyset 分页游标：最后（或第一）一行的 (时间, id) 与翻页方向。
+// 对调用方不透明，通过 Encode / DecodeCursor 与字符串互转。
+type Cursor struct {
+	Time     time.Time `json:"t"`
+	ID       int64     `json:"i"`
+	Backward bool      `json:"b,omitempty"` // true 表示取游标之前的一页（上一页）
+}
+
+// Encode 编码为不透明的游标字符串
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor 解析游标字符串
+func DecodeCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("cursor 无效")
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ID <= 0 || c.Time.IsZero() {
+		return nil, errors.New("cursor 无效")
+	}
+	return &c, nil
+}
+
+// keysetScan 按游标构造 keyset 条件与扫描顺序。
+// desc 为结果的展示顺序；向后翻页（上一页）时反向扫描，取到结果后再反转。
+func keysetScan(query *gorm.DB, timeCol string, desc bool, cursor *Cursor) *gorm.DB {
+	scanDesc := desc
+	if cursor != nil && cursor.Backward {
+		scanDesc = !desc
+	}
+	order := "ASC"
+	if scanDesc {
+		order = "DESC"
+	}
+	if cursor != nil {
+		cmp := ">"
+		if scanDesc {
+			cmp = "<"
+		}
+		query = query.Where("("+timeCol+", id) "+cmp+" (?, ?)", cursor.Time, cursor.ID)
+	}
+	return query.Order(timeCol + " " + order + ", id " + order)
+}
+
+// keysetPage 根据多取的一行判断是否还有更多数据，返回本页行（已按展示顺序）及上一页/下一页游标。
+// rows 为按扫描顺序取到的最多 limit+1 行，key 返回行的 (时间, id)。
+func keysetPage[T any](rows []T, limit int, cursor *Cursor, key func(T) (time.Time, int64)) ([]T, string, string) {
+	hasMore := len(rows) > limit
+	if hasMore {
+		rows = rows[:limit]
+	}
+	backward := cursor != nil && cursor.Backward
+	if backward {
+		slices.Reverse(rows)
+	}
+	if len(rows) == 0 {
+		return rows, "", ""
+	}
+
+	var next, prev string
+	// 向前翻页：还有更多时有下一页，带游标时有上一页；向后翻页相反
+	if (!backward && hasMore) || backward {
+		t, id := key(rows[len(rows)-1])
+		next = Cursor{Time: t, ID: id}.Encode()
+	}
+	if (backward && hasMore) || (!backward && cursor != nil) {
+		t, id := key(rows[0])
+		prev = Cursor{Time: t, ID: id, Backward: true}.Encode()
+	}
+	return rows, next, prev
+}
+
+// estimateCount 使用查询计划的估计行数代替 COUNT(*)（大表上开销恒定）
+func estimateCount(ctx context.Context, db *gorm.DB, build func(tx *gorm.DB) *gorm.DB) (int, error) {
+	stmt := build(db.Session(&gorm.Session{DryRun: true})).Select("1").Find(&[]map[string]any{}).Statement
+	sqlDB, err := db.DB()
+	if err != nil {
+		return 0, err
+	}
+
+	var plan string
+	if err := sqlDB.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) "+stmt.SQL.String(), stmt.Vars...).Scan(&plan); err != nil {
+		return 0, err
+	}
+	var out []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(plan), &out); err != nil || len(out) == 0 {
+		return 0, errors.New("解析查询计划失败")
+	}
+	return int(math.Round(out[0].Plan.Rows)), nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type row struct {
+	t  time.Time
+	id int64
+}
+
+func rowKey(r row) (time.Time, int64) { return r.t, r.id }
+
+func rows(ids ...int64) []row {
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	out := make([]row, len(ids))
+	for i, id := range ids {
+		out[i] = row{t: base.Add(time.Duration(id) * time.Second), id: id}
+	}
+	return out
+}
+
+func decode(t *testing.T, s string) *Cursor {
+	t.Helper()
+	c, err := DecodeCursor(s)
+	require.NoError(t, err)
+	return c
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	c := Cursor{Time: time.Date(2026, 3, 1, 12, 0, 0, 123456000, time.UTC), ID: 42, Backward: true}
+	got := decode(t, c.Encode())
+	assert.True(t, c.Time.Equal(got.Time))
+	assert.Equal(t, c.ID, got.ID)
+	assert.True(t, got.Backward)
+
+	for _, bad := range []string{"!!", "bm90LWpzb24", Cursor{}.Encode()} {
+		_, err := DecodeCursor(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestKeysetPage(t *testing.T) {
+	t.Run("第一页还有更多", func(t *testing.T) {
+		items, next, prev := keysetPage(rows(9, 8, 7), 2, nil, rowKey)
+		assert.Equal(t, rows(9, 8), items)
+		assert.Equal(t, int64(8), decode(t, next).ID)
+		assert.False(t, decode(t, next).Backward)
+		assert.Empty(t, prev, "第一页没有上一页")
+	})
+
+	t.Run("最后一页", func(t *testing.T) {
+		cur := &Cursor{Time: time.Now(), ID: 8}
+		items, next, prev := keysetPage(rows(7), 2, cur, rowKey)
+		assert.Equal(t, rows(7), items)
+		assert.Empty(t, next)
+		assert.Equal(t, int64(7), decode(t, prev).ID)
+		assert.True(t, decode(t, prev).Backward)
+	})
+
+	t.Run("向后翻页按展示顺序返回", func(t *testing.T) {
+		// 反向扫描取到 5,6,7（升序），展示顺序为降序
+		cur := &Cursor{Time: time.Now(), ID: 4, Backward: true}
+		items, next, prev := keysetPage(rows(5, 6, 7), 2, cur, rowKey)
+		assert.Equal(t, rows(6, 5), items)
+		assert.Equal(t, int64(5), decode(t, next).ID)
+		assert.Equal(t, int64(6), decode(t, prev).ID)
+	})
+
+	t.Run("向后翻到第一页", func(t *testing.T) {
+		cur := &Cursor{Time: time.Now(), ID: 7, Backward: true}
+		items, next, prev := keysetPage(rows(8, 9), 2, cur, rowKey)
+		assert.Equal(t, rows(9, 8), items)
+		assert.Equal(t, int64(8), decode(t, next).ID)
+		assert.Empty(t, prev)
+	})
+
+	t.Run("空页", func(t *testing.T) {
+		items, next, prev := keysetPage(rows(), 2, &Cursor{Time: time.Now(), ID: 1}, rowKey)
+		assert.Empty(t, items)
+		assert.Empty(t, next)
+		assert.Empty(t, prev)
+	})
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// attemptPartitionPrefix task_attempt 月分区的表名前缀，完整表名如 task_attempt_p202603
+	attemptPartitionPrefix = "task_attempt_p"
+	// attemptDefaultPartition 兜底分区，保存尚未创建分区的月份的执行尝试
+	attemptDefaultPartition = "task_attempt_default"
+	// attemptPartitionLockKey 维护分区时持有的事务级 advisory lock，多副本同时运行时串行执行
+	attemptPartitionLockKey = "task_attempt_partitions"
+)
+
+// monthStart 返回 t 所在月份的第一天 0 点（UTC，分区边界统一按 UTC 计算）
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// attemptPartitionName 返回 month 所在月份的分区表名
+func attemptPartitionName(month time.Time) string {
+	return attemptPartitionPrefix + monthStart(month).Format("200601")
+}
+
+// parseAttemptPartition 从分区表名解析月份，非月分区（如默认分区）返回 false
+func parseAttemptPartition(name string) (time.Time, bool) {
+	suffix, ok := strings.CutPrefix(name, attemptPartitionPrefix)
+	if !ok || len(suffix) != len("200601") {
+		return time.Time{}, false
+	}
+	month, err := time.Parse("200601", suffix)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return month, true
+}
+
+// EnsureAttemptPartitions 创建 from 所在月份起 months 个月的执行尝试分区，返回新建的分区名。
+// 已落入默认分区的该月数据在同一事务中移入新分区后再挂载，避免挂载时与默认分区冲突。
+func (r *TaskRepo) EnsureAttemptPartitions(ctx context.Context, from time.Time, months int) ([]string, error) {
+	var created []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", attemptPartitionLockKey).Error; err != nil {
+			return err
+		}
+
+		existing, err := listAttemptPartitions(tx)
+		if err != nil {
+			return err
+		}
+		start := monthStart(from)
+		for i := 0; i < months; i++ {
+			lower := start.AddDate(0, i, 0)
+			upper := lower.AddDate(0, 1, 0)
+			name := attemptPartitionName(lower)
+			if _, ok := existing[name]; ok {
+				continue
+			}
+
+			stmts := []struct {
+				sql  string
+				args []any
+			}{
+				{fmt.Sprintf(`CREATE TABLE %q (LIKE task_attempt INCLUDING DEFAULTS)`, name), nil},
+				{fmt.Sprintf(`WITH moved AS (
+	DELETE FROM %q WHERE started_at >= ? AND started_at < ? RETURNING *
+)
+INSERT INTO %q SELECT * FROM moved`, attemptDefaultPartition, name), []any{lower, upper}},
+				{fmt.Sprintf(`ALTER TABLE task_attempt ATTACH PARTITION %q FOR VALUES FROM ('%s') TO ('%s')`,
+					name, lower.Format(time.RFC3339), upper.Format(time.RFC3339)), nil},
+			}
+			for _, s := range stmts {
+				if err := tx.Exec(s.sql, s.args...).Error; err != nil {
+					return fmt.Errorf("创建分区 %s 失败: %w", name, err)
+				}
+			}
+			created = append(created, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// DropAttemptPartitions 删除整月早于 before 的执行尝试分区（整表删除，不产生死元组），
+// 并逐行清理默认分区中早于 before 的数据，返回删除的分区名
+func (r *TaskRepo) DropAttemptPartitions(ctx context.Context, before time.Time) ([]string, error) {
+	var dropped []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", attemptPartitionLockKey).Error; err != nil {
+			return err
+		}
+
+		existing, err := listAttemptPartitions(tx)
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(existing))
+		for name, month := range existing {
+			if !month.AddDate(0, 1, 0).After(before) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := tx.Exec(fmt.Sprintf(`DROP TABLE %q`, name)).Error; err != nil {
+				return fmt.Errorf("删除分区 %s 失败: %w", name, err)
+			}
+			dropped = append(dropped, name)
+		}
+
+		return tx.Exec(fmt.Sprintf(`DELETE FROM %q WHERE started_at < ?`, attemptDefaultPartition), before).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dropped, nil
+}
+
+// listAttemptPartitions 查询 task_attempt 现有的月分区（表名 -> 月份）
+func listAttemptPartitions(tx *gorm.DB) (map[string]time.Time, error) {
+	var names []string
+	if err := tx.Raw(`
+SELECT c.relname FROM pg_inherits i
+JOIN pg_class c ON c.oid = i.inhrelid
+WHERE i.inhparent = 'task_attempt'::regclass`).Scan(&names).Error; err != nil {
+		return nil, err
+	}
+	partitions := make(map[string]time.Time, len(names))
+	for _, name := range names {
+		if month, ok := parseAttemptPartition(name); ok {
+			partitions[name] = month
+		}
+	}
+	return partitions, nil
+}
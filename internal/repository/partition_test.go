@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttemptPartitionName(t *testing.T) {
+	assert.Equal(t, "task_attempt_p202603", attemptPartitionName(time.Date(2026, 3, 31, 23, 0, 0, 0, time.UTC)))
+	// 分区按 UTC 月份划分：东八区 4 月 1 日 07:00 仍属于 3 月
+	cst := time.FixedZone("CST", 8*3600)
+	assert.Equal(t, "task_attempt_p202603", attemptPartitionName(time.Date(2026, 4, 1, 7, 0, 0, 0, cst)))
+	assert.Equal(t, "task_attempt_p202612", attemptPartitionName(time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestParseAttemptPartition(t *testing.T) {
+	month, ok := parseAttemptPartition("task_attempt_p202603")
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), month)
+
+	for _, name := range []string{"task_attempt_default", "task_attempt_p2026", "task_attempt_p202613", "task_archive"} {
+		_, ok := parseAttemptPartition(name)
+		assert.False(t, ok, name)
+	}
+}
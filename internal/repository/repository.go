@@ -8,9 +8,11 @@ import (
 
 // Task 表示任务实体
 type Task struct {
+	ID             int64           `json:"-"` // 自增主键（仅用于 keyset 分页游标）
 	TaskID         string          `json:"task_id"`
 	WorkerName     string          `json:"worker_name"`
 	Queue          string          `json:"queue"`
+	AsynqTaskID    string          `json:"asynq_task_id,omitempty"`
 	Priority       int             `json:"priority"`
 	Payload        json.RawMessage `json:"payload"`
 	Status         string          `json:"status"`
@@ -18,12 +20,55 @@ type Task struct {
 	LastError      string          `json:"last_error,omitempty"`
 	LastWorkerName string          `json:"last_worker_name,omitempty"`
 	TraceID        string          `json:"trace_id,omitempty"`
-	CreatedAt      time.Time       `json:"created_at"`
-	UpdatedAt      time.Time       `json:"updated_at"`
+
+	// 任务级覆盖参数（为空时使用 worker 默认配置）
+	MaxRetry         *int32     `json:"max_retry,omitempty"`
+	TimeoutSeconds   *int32     `json:"timeout_seconds,omitempty"`
+	Deadline         *time.Time `json:"deadline,omitempty"`
+	RetentionSeconds *int32     `json:"retention_seconds,omitempty"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"` // 到期仍未开始执行则丢弃
+
+	// 工作流：所属工作流 ID 及依赖的上游任务 ID
+	WorkflowID string   `json:"workflow_id,omitempty"`
+	DependsOn  []string `json:"depends_on,omitempty"`
+
+	// 回调：任务成功/最终失败后入队的后续任务，以及（作为回调任务时）触发它的父任务
+	OnSuccess    *CallbackSpec `json:"on_success,omitempty"`
+	OnFailure    *CallbackSpec `json:"on_failure,omitempty"`
+	ParentTaskID string        `json:"parent_task_id,omitempty"`
+
+	// ReplayedFrom 重放产生的任务指向被重放的原任务
+	ReplayedFrom string `json:"replayed_from,omitempty"`
+
+	// Result 任务成功时 worker 上报的结果
+	Result json.RawMessage `json:"result,omitempty"`
+
+	// Labels 任务标签（如 tenant、source、batch_id），用于过滤与统计
+	Labels map[string]string `json:"labels,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AsynqID 任务在 asynq 中的 ID（旧数据没有记录时退化为 task_id）
+func (t Task) AsynqID() string {
+	if t.AsynqTaskID != "" {
+		return t.AsynqTaskID
+	}
+	return t.TaskID
+}
+
+// CallbackSpec 回调任务定义（payload 为模板，入队时替换占位符）
+type CallbackSpec struct {
+	WorkerName string          `json:"worker_name"`
+	Queue      string          `json:"queue"`
+	Priority   string          `json:"priority,omitempty"`
+	Payload    json.RawMessage `json:"payload"`
 }
 
 // Attempt 表示任务执行尝试记录
 type Attempt struct {
+	ID          int64      `json:"-"` // 自增主键（仅用于 keyset 分页游标）
 	TaskID      string     `json:"task_id"`
 	AsynqTaskID string     `json:"asynq_task_id,omitempty"`
 	Attempt     int        `json:"attempt"`
@@ -37,24 +82,163 @@ type Attempt struct {
 	SpanID      string     `json:"span_id,omitempty"`
 }
 
-// ListTasksFilter 任务列表查询过滤条件
+// OpenAttempt 仍在执行（任务为 running 且未收到最终上报）的执行尝试，附带判断是否失联所需的信息
+type OpenAttempt struct {
+	Task            Task       // 所属任务
+	Attempt         int        // 执行尝试序号（即任务的 last_attempt）
+	StartedAt       time.Time  // 开始执行时间
+	WorkerName      string     // 上报开始执行的 worker
+	TimeoutSeconds  int32      // 超时时间：任务覆盖值，未覆盖时为 worker 默认值（0 表示未知）
+	LastHeartbeatAt *time.Time // worker 最近一次心跳（从未上报心跳时为空）
+}
+
+// OutboxTask 出箱模式下批量写入的任务及其计划执行时间（为空表示立即执行）
+type OutboxTask struct {
+	Task      Task
+	ProcessAt *time.Time
+}
+
+// OutboxEntry 待投递的出箱记录
+type OutboxEntry struct {
+	ID        int64
+	TaskID    string
+	ProcessAt *time.Time // 计划执行时间，为空表示立即执行
+	Attempts  int        // 此前的投递次数
+	CreatedAt time.Time
+}
+
+// OutboxDelivery 一条出箱记录的投递结果：Err 为空表示已投递（或无需投递），否则在 RetryAt 重新投递
+type OutboxDelivery struct {
+	Err     error
+	RetryAt time.Time
+}
+
+// OutboxRelayResult 一批出箱记录的投递结果
+type OutboxRelayResult struct {
+	Delivered int
+	Failed    int
+}
+
+// OutboxStats 未投递的出箱记录统计
+type OutboxStats struct {
+	Pending         int
+	OldestCreatedAt *time.Time // 最早一条未投递记录的创建时间（没有时为空）
+}
+
+// TaskProgress 任务某次尝试的最新执行进度
+type TaskProgress struct {
+	TaskID    string    `json:"task_id"`
+	Attempt   int       `json:"attempt"`
+	Percent   int       `json:"percent"`
+	Message   string    `json:"message,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PurgeFilter 保留策略的清理条件：状态属于 Statuses 且 updated_at 早于 Before 的任务
+type PurgeFilter struct {
+	WorkerName     string   // 只清理该 worker 的任务（为空不限）
+	ExcludeWorkers []string // 排除的 worker（由单独的 worker 规则处理）
+	Statuses       []string
+	Before         time.Time
+}
+
+// PurgeResult 一批清理的结果
+type PurgeResult struct {
+	Tasks    int // 删除（或归档）的任务数
+	Attempts int // 随任务删除的执行尝试数
+}
+
+// ReplayLink 重放链中的一个任务
+type ReplayLink struct {
+	TaskID       string    `json:"task_id"`
+	ReplayedFrom string    `json:"replayed_from,omitempty"`
+	Status       string    `json:"status"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ReplayLineage 任务的重放链：Ancestors 为逐级向上被重放的原任务（最近的在前），
+// Replays 为由该任务重放产生的全部后代任务（按创建时间排序）
+type ReplayLineage struct {
+	Ancestors []ReplayLink `json:"ancestors"`
+	Replays   []ReplayLink `json:"replays"`
+}
+
+// TaskLog 任务执行日志
+type TaskLog struct {
+	ID       int64     `json:"id"`
+	TaskID   string    `json:"task_id"`
+	Attempt  int       `json:"attempt"`
+	Level    string    `json:"level"`
+	Message  string    `json:"message"`
+	LoggedAt time.Time `json:"logged_at"`
+}
+
+// ListTaskLogsFilter 任务日志查询条件
+type ListTaskLogsFilter struct {
+	TaskID  string
+	Attempt int   // 为 0 时返回所有尝试的日志
+	AfterID int64 // 只返回 id 大于该值的日志（用于翻页）
+	Limit   int
+}
+
+// ListTasksFilter 任务列表查询过滤条件（作为批量操作的过滤条件时以 JSON 保存，分页与排序字段不保存）
 type ListTasksFilter struct {
-	WorkerName string
-	Status     string
-	Queue      string
-	Limit      int
-	Offset     int
+	TaskIDs    []string          `json:"task_ids,omitempty"` // 只匹配这些 task_id
+	WorkerName string            `json:"worker_name,omitempty"`
+	Status     string            `json:"status,omitempty"`
+	Queue      string            `json:"queue,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`   // 标签选择器：需包含全部键值对
+	Priority   int               `json:"priority,omitempty"` // 1=low, 2=default, 3=critical；0 表示不过滤
+
+	// 时间范围（左闭右开），为空表示不限制
+	CreatedAfter  *time.Time `json:"created_after,omitempty"`
+	CreatedBefore *time.Time `json:"created_before,omitempty"`
+	UpdatedAfter  *time.Time `json:"updated_after,omitempty"`
+	UpdatedBefore *time.Time `json:"updated_before,omitempty"`
+
+	ErrorContains   string          `json:"error,omitempty"`        // last_error 子串（不区分大小写）
+	PayloadContains json.RawMessage `json:"payload,omitempty"`      // payload 需包含的 JSON 对象（@>）
+	PayloadPath     string          `json:"payload_path,omitempty"` // payload 需满足的 jsonpath 谓词（@@），如 $.customer_id == "c-42"
+
+	// MaxID 只匹配 id 不大于该值的任务，为 0 表示不限制（批量操作只处理开始前已存在的任务）
+	MaxID int64 `json:"-"`
+
+	// 排序：SortBy 为 created_at（默认）/updated_at/priority，SortOrder 为 desc（默认）/asc
+	SortBy    string `json:"-"`
+	SortOrder string `json:"-"`
+
+	Limit  int `json:"-"`
+	Offset int `json:"-"`
+}
+
+// TaskPage keyset 分页的一页任务
+type TaskPage struct {
+	Items      []Task
+	NextCursor string // 为空表示没有下一页
+	PrevCursor string // 为空表示没有上一页
+}
+
+// AttemptPage keyset 分页的一页执行尝试
+type AttemptPage struct {
+	Items      []Attempt
+	NextCursor string
+	PrevCursor string
 }
 
+// TaskSortFields ListTasks 支持的排序字段
+var TaskSortFields = map[string]bool{"created_at": true, "updated_at": true, "priority": true}
+
 // WorkerStats Worker 统计信息
 type WorkerStats struct {
 	TotalTasks        int            `json:"total_tasks"`
 	SuccessTasks      int            `json:"success_tasks"`
 	FailedTasks       int            `json:"failed_tasks"`
+	DeadTasks         int            `json:"dead_tasks"`
 	PendingTasks      int            `json:"pending_tasks"`
 	RunningTasks      int            `json:"running_tasks"`
+	CanceledTasks     int            `json:"canceled_tasks"`
 	SuccessRate       float64        `json:"success_rate"`
-	AvgDurationMs     int            `json:"avg_duration_ms"`
+	AvgDurationMs     int            `json:"avg_duration_ms"` // 最近 7 天成功尝试的平均耗时
 	QueueStats        map[string]int `json:"queue_stats"`
 	QueueSuccessStats map[string]int `json:"queue_success_stats"`
 	QueueFailedStats  map[string]int `json:"queue_failed_stats"`
@@ -76,30 +260,129 @@ type TaskRepository interface {
 	// UpsertTask 创建或更新任务
 	UpsertTask(ctx context.Context, task Task) error
 
+	// UpsertTasks 批量创建或更新任务（单条多行写入）
+	UpsertTasks(ctx context.Context, tasks []Task) error
+
 	// UpdateTaskStatus 更新任务状态
 	UpdateTaskStatus(ctx context.Context, taskID, status string, lastAttempt int, lastError string, lastWorkerName string) error
 
+	// TransitionTaskStatus 仅当任务当前状态为 from 时更新为 to（用于并发安全的状态流转），返回是否更新成功
+	TransitionTaskStatus(ctx context.Context, taskID, from, to, asynqTaskID, lastError string) (bool, error)
+
 	// GetTask 根据 task_id 获取任务详情
 	GetTask(ctx context.Context, taskID string) (*Task, error)
 
+	// ValidateJSONPath 校验 jsonpath 表达式能否被 Postgres 解析，语法错误时返回 ErrInvalidJSONPath
+	ValidateJSONPath(ctx context.Context, path string) error
+
+	// ExistingTaskIDs 返回 taskIDs 中已存在任务记录的 task_id 集合
+	ExistingTaskIDs(ctx context.Context, taskIDs []string) (map[string]struct{}, error)
+
 	// ListTasks 查询任务列表（支持分页和过滤）
 	ListTasks(ctx context.Context, filter ListTasksFilter) ([]Task, error)
 
+	// ListTasksPage 按 (created_at, id) keyset 分页查询任务，cursor 为空时返回第一页（忽略 Offset）
+	ListTasksPage(ctx context.Context, filter ListTasksFilter, cursor *Cursor) (*TaskPage, error)
+
+	// ExportTasks 使用服务端游标按过滤条件分批读取全部匹配任务（忽略 Limit/Offset），每批调用一次 fn；
+	// includeAttempts 为 true 时同时查询本批任务的执行尝试（按 task_id 分组，最早在前）
+	ExportTasks(ctx context.Context, filter ListTasksFilter, includeAttempts bool, fn func(tasks []Task, attempts map[string][]Attempt) error) error
+
 	// CountTasks 统计任务总数
 	CountTasks(ctx context.Context, filter ListTasksFilter) (int, error)
 
+	// EstimateTasks 根据查询计划估算任务数（不执行 COUNT）
+	EstimateTasks(ctx context.Context, filter ListTasksFilter) (int, error)
+
+	// MarkTaskRunning 收到开始执行的上报后将任务置为 running（只更新 pending/fail/running 的任务），返回是否命中
+	MarkTaskRunning(ctx context.Context, taskID string, attempt int, workerName string) (bool, error)
+
+	// ApplyAttemptStatus 按最终上报更新任务的 status/last_attempt/last_error（result 不为空时一并更新），
+	// 只更新尚未进入终态的任务（已被取消或被对账/失联检测结束的任务不会被覆盖），返回是否命中
+	ApplyAttemptStatus(ctx context.Context, taskID, status string, attempt int, lastError string, result json.RawMessage) (bool, error)
+
+	// ListOpenAttempts 按任务 id 升序查询 id 大于 afterID、开始时间早于 startedBefore 的未结束执行尝试（失联检测分批遍历）
+	ListOpenAttempts(ctx context.Context, startedBefore time.Time, afterID int64, limit int) ([]OpenAttempt, error)
+
+	// CloseAttempt 将仍为 running 的执行尝试标记为 status 并记录结束时间与原因，返回是否命中
+	CloseAttempt(ctx context.Context, taskID string, attempt int, status, errMsg string, finishedAt time.Time) (bool, error)
+
 	// InsertAttempt 插入任务执行尝试记录
 	InsertAttempt(ctx context.Context, attempt Attempt) error
 
 	// ListAttempts 查询任务的执行尝试历史
 	ListAttempts(ctx context.Context, taskID string, limit int) ([]Attempt, error)
 
-	// GetWorkerStats 获取 Worker 统计信息
-	GetWorkerStats(ctx context.Context, workerName string) (*WorkerStats, error)
+	// ListAttemptsPage 按 (started_at, id) keyset 分页查询任务的执行尝试（最新在前）
+	ListAttemptsPage(ctx context.Context, taskID string, limit int, cursor *Cursor) (*AttemptPage, error)
+
+	// CountAttempts 统计任务的执行尝试记录数
+	CountAttempts(ctx context.Context, taskID string) (int, error)
+
+	// UpsertProgress 保存任务某次尝试的最新进度（覆盖同一尝试的旧进度）
+	UpsertProgress(ctx context.Context, progress TaskProgress) error
 
-	// GetWorkerTimeSeriesStats 获取 Worker 时间序列统计数据
-	GetWorkerTimeSeriesStats(ctx context.Context, workerName string, hours int) ([]TimeSeriesStats, error)
+	// GetLatestProgress 获取任务最近一次尝试的进度，没有进度时返回 nil
+	GetLatestProgress(ctx context.Context, taskID string) (*TaskProgress, error)
+
+	// PurgeTasks 删除一批（最多 limit 个）满足条件的任务及其执行尝试；archive 为 true 时先写入 task_archive
+	PurgeTasks(ctx context.Context, filter PurgeFilter, limit int, archive bool) (PurgeResult, error)
+
+	// EnsureAttemptPartitions 创建 from 所在月份起 months 个月的执行尝试月分区（已存在的跳过），返回新建的分区名
+	EnsureAttemptPartitions(ctx context.Context, from time.Time, months int) ([]string, error)
+
+	// DropAttemptPartitions 删除整月早于 before 的执行尝试分区，返回删除的分区名
+	DropAttemptPartitions(ctx context.Context, before time.Time) ([]string, error)
+
+	// GetReplayLineage 沿 replayed_from 双向查询任务的重放链
+	GetReplayLineage(ctx context.Context, taskID string) (*ReplayLineage, error)
+
+	// InsertTaskLogs 批量写入任务执行日志
+	InsertTaskLogs(ctx context.Context, logs []TaskLog) error
+
+	// ListTaskLogs 按写入顺序查询任务执行日志
+	ListTaskLogs(ctx context.Context, filter ListTaskLogsFilter) ([]TaskLog, error)
+
+	// GetWorkerStats 获取 Worker 统计信息（labels 不为空时只统计包含这些标签的任务）
+	GetWorkerStats(ctx context.Context, workerName string, labels map[string]string) (*WorkerStats, error)
+
+	// GetWorkerTimeSeriesStats 获取 Worker 时间序列统计数据（labels 同上）
+	GetWorkerTimeSeriesStats(ctx context.Context, workerName string, hours int, labels map[string]string) ([]TimeSeriesStats, error)
 
 	// ListFailedTasks 查询失败的任务列表（用于批量重试）
 	ListFailedTasks(ctx context.Context, workerName string, limit int) ([]Task, error)
+
+	// ListTasksForReconcile 按 id 升序查询 id 大于 afterID、状态属于 statuses 且 updated_at 早于 before 的任务（对账分批遍历）。
+	// 出箱中尚未投递的任务还没有入队，不参与对账
+	ListTasksForReconcile(ctx context.Context, statuses []string, before time.Time, afterID int64, limit int) ([]Task, error)
+
+	// CreateTaskWithOutbox 在同一事务中写入任务记录与出箱记录，task_id 已存在时返回 ErrTaskExists
+	CreateTaskWithOutbox(ctx context.Context, t Task, processAt *time.Time) error
+
+	// CreateTasksWithOutbox 在同一事务中批量写入任务记录与出箱记录，返回已存在而跳过的 task_id 集合
+	CreateTasksWithOutbox(ctx context.Context, tasks []OutboxTask) (map[string]struct{}, error)
+
+	// TransitionTaskWithOutbox 在同一事务中将状态为 from 的任务置为 pending 并写入出箱记录（工作流任务依赖满足后入队），返回是否命中
+	TransitionTaskWithOutbox(ctx context.Context, taskID, from string) (bool, error)
+
+	// RelayOutbox 锁定一批到期未投递的出箱记录并逐条调用 deliver，按结果标记已投递或推迟重试
+	RelayOutbox(ctx context.Context, limit int, deliver func(ctx context.Context, e OutboxEntry, t Task) OutboxDelivery) (OutboxRelayResult, error)
+
+	// GetOutboxStats 统计未投递的出箱记录
+	GetOutboxStats(ctx context.Context) (OutboxStats, error)
+
+	// PurgeDeliveredOutbox 删除一批投递时间早于 before 的出箱记录，返回删除的行数
+	PurgeDeliveredOutbox(ctx context.Context, before time.Time, limit int) (int, error)
+
+	// ListTasksAfterID 按 id 升序查询满足过滤条件且 id 大于 afterID 的任务（忽略排序与分页字段，批量操作分批遍历）
+	ListTasksAfterID(ctx context.Context, filter ListTasksFilter, afterID int64, limit int) ([]Task, error)
+
+	// MaxTaskID 返回当前 task.id 的最大值，没有任务时返回 0
+	MaxTaskID(ctx context.Context) (int64, error)
+
+	// DeleteTask 删除任务记录及其执行尝试（进度、日志、出箱记录级联删除），返回是否命中
+	DeleteTask(ctx context.Context, taskID string) (bool, error)
+
+	// MoveTaskQueue 修改任务所在队列与优先级（asynqTaskID 不为空时一并更新），from 不为空时仅当任务当前状态为 from 时更新，返回是否命中
+	MoveTaskQueue(ctx context.Context, taskID, from, queue string, priority int, asynqTaskID string) (bool, error)
 }
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ScheduleRepo 周期任务仓储实现
+type ScheduleRepo struct {
+	db *gorm.DB
+}
+
+// NewScheduleRepo 创建周期任务仓储
+func NewScheduleRepo(db *gorm.DB) *ScheduleRepo {
+	return &ScheduleRepo{db: db}
+}
+
+// CreateSchedule 创建周期任务
+func (r *ScheduleRepo) CreateSchedule(ctx context.Context, s Schedule) error {
+	if s.ScheduleID == "" {
+		return errors.New("schedule_id 不能为空")
+	}
+
+	model := ScheduleModel{
+		ScheduleID: s.ScheduleID,
+		Name:       s.Name,
+		CronSpec:   s.CronSpec,
+		Timezone:   s.Timezone,
+		WorkerName: s.WorkerName,
+		Queue:      s.Queue,
+		Priority:   s.Priority,
+		Payload:    s.Payload,
+		Enabled:    s.Enabled,
+		NextRunAt:  s.NextRunAt,
+	}
+	// enabled 默认值为 true，显式写入 false 需要 Select
+	return r.db.WithContext(ctx).Select("*").Omit("id").Create(&model).Error
+}
+
+// UpdateSchedule 更新周期任务定义
+func (r *ScheduleRepo) UpdateSchedule(ctx context.Context, s Schedule) error {
+	res := r.db.WithContext(ctx).
+		Model(&ScheduleModel{}).
+		Where("schedule_id = ?", s.ScheduleID).
+		Updates(map[string]interface{}{
+			"name":        s.Name,
+			"cron_spec":   s.CronSpec,
+			"timezone":    s.Timezone,
+			"worker_name": s.WorkerName,
+			"queue":       s.Queue,
+			"priority":    s.Priority,
+			"payload":     s.Payload,
+			"enabled":     s.Enabled,
+			"next_run_at": s.NextRunAt,
+			"updated_at":  time.Now(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetSchedule 获取周期任务
+func (r *ScheduleRepo) GetSchedule(ctx context.Context, scheduleID string) (*Schedule, error) {
+	var model ScheduleModel
+	if err := r.db.WithContext(ctx).Where("schedule_id = ?", scheduleID).First(&model).Error; err != nil {
+		return nil, err
+	}
+	s := model.ToSchedule()
+	return &s, nil
+}
+
+// ListSchedules 查询周期任务列表
+func (r *ScheduleRepo) ListSchedules(ctx context.Context, enabledOnly bool) ([]Schedule, error) {
+	query := r.db.WithContext(ctx).Model(&ScheduleModel{})
+	if enabledOnly {
+		query = query.Where("enabled = ?", true)
+	}
+
+	var models []ScheduleModel
+	if err := query.Order("created_at ASC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	schedules := make([]Schedule, len(models))
+	for i, m := range models {
+		schedules[i] = m.ToSchedule()
+	}
+	return schedules, nil
+}
+
+// DeleteSchedule 删除周期任务及其触发记录
+func (r *ScheduleRepo) DeleteSchedule(ctx context.Context, scheduleID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("schedule_id = ?", scheduleID).Delete(&ScheduleRunModel{}).Error; err != nil {
+			return err
+		}
+		res := tx.Where("schedule_id = ?", scheduleID).Delete(&ScheduleModel{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// ClaimRun 条件更新 last_run_at，保证同一次触发只被一个副本处理
+func (r *ScheduleRepo) ClaimRun(ctx context.Context, scheduleID string, firedAt time.Time, minGap time.Duration) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&ScheduleModel{}).
+		Where("schedule_id = ? AND enabled = ?", scheduleID, true).
+		Where("last_run_at IS NULL OR last_run_at <= ?", firedAt.Add(-minGap)).
+		UpdateColumn("last_run_at", firedAt) // 不刷新 updated_at（用于识别定义变更）
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// RecordRun 记录触发结果，并刷新 last_run_at/last_task_id/next_run_at。
+// 使用 UpdateColumns 不刷新 updated_at：调度器以 updated_at 识别定义变更，
+// 每次触发都刷新会导致各副本反复重新注册周期任务。
+func (r *ScheduleRepo) RecordRun(ctx context.Context, run ScheduleRun, nextRunAt *time.Time) error {
+	model := ScheduleRunModel{
+		ScheduleID: run.ScheduleID,
+		FiredAt:    run.FiredAt,
+		Status:     run.Status,
+	}
+	if run.TaskID != "" {
+		model.TaskID = &run.TaskID
+	}
+	if run.Error != "" {
+		model.Error = &run.Error
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&model).Error; err != nil {
+			return err
+		}
+		return tx.Model(&ScheduleModel{}).
+			Where("schedule_id = ?", run.ScheduleID).
+			UpdateColumns(map[string]interface{}{
+				"last_run_at":  run.FiredAt,
+				"last_task_id": model.TaskID,
+				"last_error":   model.Error,
+				"next_run_at":  nextRunAt,
+			}).Error
+	})
+}
+
+// ListRuns 查询触发历史
+func (r *ScheduleRepo) ListRuns(ctx context.Context, scheduleID string, limit int) ([]ScheduleRun, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	var models []ScheduleRunModel
+	if err := r.db.WithContext(ctx).
+		Where("schedule_id = ?", scheduleID).
+		Order("fired_at DESC").
+		Limit(limit).
+		Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	runs := make([]ScheduleRun, len(models))
+	for i, m := range models {
+		runs[i] = m.ToScheduleRun()
+	}
+	return runs, nil
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Schedule 周期任务（cron）定义
+type Schedule struct {
+	ScheduleID string          `json:"schedule_id"`
+	Name       string          `json:"name"`
+	CronSpec   string          `json:"cron_spec"`
+	Timezone   string          `json:"timezone"`
+	WorkerName string          `json:"worker_name"`
+	Queue      string          `json:"queue"`    // 队列组名称
+	Priority   string          `json:"priority"` // critical/default/low
+	Payload    json.RawMessage `json:"payload"`
+	Enabled    bool            `json:"enabled"`
+	LastRunAt  *time.Time      `json:"last_run_at,omitempty"`
+	NextRunAt  *time.Time      `json:"next_run_at,omitempty"`
+	LastTaskID string          `json:"last_task_id,omitempty"`
+	LastError  string          `json:"last_error,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// ScheduleRun 周期任务的一次触发记录
+type ScheduleRun struct {
+	ScheduleID string    `json:"schedule_id"`
+	FiredAt    time.Time `json:"fired_at"`
+	TaskID     string    `json:"task_id,omitempty"`
+	Status     string    `json:"status"` // enqueued/failed
+	Error      string    `json:"error,omitempty"`
+}
+
+// ScheduleRepository 周期任务仓储接口
+type ScheduleRepository interface {
+	// CreateSchedule 创建周期任务
+	CreateSchedule(ctx context.Context, schedule Schedule) error
+
+	// UpdateSchedule 更新周期任务定义（不修改运行记录字段）
+	UpdateSchedule(ctx context.Context, schedule Schedule) error
+
+	// GetSchedule 根据 schedule_id 获取周期任务
+	GetSchedule(ctx context.Context, scheduleID string) (*Schedule, error)
+
+	// ListSchedules 查询周期任务列表（enabledOnly 为 true 时只返回启用的）
+	ListSchedules(ctx context.Context, enabledOnly bool) ([]Schedule, error)
+
+	// DeleteSchedule 删除周期任务及其触发记录
+	DeleteSchedule(ctx context.Context, scheduleID string) error
+
+	// ClaimRun 抢占一次触发：距离上次触发不足 minGap 时返回 false（用于多副本去重）
+	ClaimRun(ctx context.Context, scheduleID string, firedAt time.Time, minGap time.Duration) (bool, error)
+
+	// RecordRun 记录触发结果，并更新周期任务的最近触发时间、任务/错误与下次触发时间（不刷新 updated_at）
+	RecordRun(ctx context.Context, run ScheduleRun, nextRunAt *time.Time) error
+
+	// ListRuns 查询周期任务的触发历史（按时间倒序）
+	ListRuns(ctx context.Context, scheduleID string, limit int) ([]ScheduleRun, error)
+}
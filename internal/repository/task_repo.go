@@ -2,11 +2,17 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+
+	"github.com/azhengyongqin/asynq-hub/internal/model"
 )
 
 // TaskRepo 任务仓储实现
@@ -28,13 +34,38 @@ func (r *TaskRepo) UpsertTask(ctx context.Context, t Task) error {
 	model := TaskToModel(t)
 	model.UpdatedAt = time.Now()
 
-	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+	return r.db.WithContext(ctx).Clauses(taskUpsertClause()).Create(&model).Error
+}
+
+// UpsertTasks 批量创建或更新任务（单条多行 INSERT）
+func (r *TaskRepo) UpsertTasks(ctx context.Context, tasks []Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	models := make([]TaskModel, len(tasks))
+	for i, t := range tasks {
+		if t.TaskID == "" {
+			return errors.New("task_id 不能为空")
+		}
+		models[i] = TaskToModel(t)
+		models[i].UpdatedAt = now
+	}
+
+	return r.db.WithContext(ctx).Clauses(taskUpsertClause()).Create(&models).Error
+}
+
+// taskUpsertClause task_id 冲突时更新的列
+func taskUpsertClause() clause.OnConflict {
+	return clause.OnConflict{
 		Columns: []clause.Column{{Name: "task_id"}},
 		DoUpdates: clause.AssignmentColumns([]string{
-			"queue", "priority", "payload", "status",
-			"last_attempt", "last_error", "last_worker_name", "trace_id", "updated_at",
+			"queue", "asynq_task_id", "priority", "payload", "status",
+			"last_attempt", "last_error", "last_worker_name", "trace_id",
+			"max_retry", "timeout_seconds", "deadline", "retention_seconds", "expires_at", "result", "labels", "updated_at",
 		}),
-	}).Create(&model).Error
+	}
 }
 
 // UpdateTaskStatus 更新任务状态
@@ -53,6 +84,72 @@ func (r *TaskRepo) UpdateTaskStatus(ctx context.Context, taskID, status string,
 		Updates(updates).Error
 }
 
+// TransitionTaskStatus 条件更新任务状态（WHERE status = from），返回是否命中
+func (r *TaskRepo) TransitionTaskStatus(ctx context.Context, taskID, from, to, asynqTaskID, lastError string) (bool, error) {
+	updates := map[string]interface{}{
+		"status":     to,
+		"last_error": lastError,
+		"updated_at": time.Now(),
+	}
+	if asynqTaskID != "" {
+		updates["asynq_task_id"] = asynqTaskID
+	}
+
+	res := r.db.WithContext(ctx).
+		Model(&TaskModel{}).
+		Where("task_id = ? AND status = ?", taskID, from).
+		Updates(updates)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// MarkTaskRunning 将任务置为 running 并记录本次尝试序号与 worker。
+// 只更新等待执行或等待重试的任务，已结束（含已取消）的任务不会被迟到的上报改回 running。
+func (r *TaskRepo) MarkTaskRunning(ctx context.Context, taskID string, attempt int, workerName string) (bool, error) {
+	updates := map[string]interface{}{
+		"status":       "running",
+		"last_attempt": attempt,
+		"last_error":   "",
+		"updated_at":   time.Now(),
+	}
+	if workerName != "" {
+		updates["last_worker_name"] = workerName
+	}
+
+	res := r.db.WithContext(ctx).
+		Model(&TaskModel{}).
+		Where("task_id = ? AND status IN ('pending', 'fail', 'running')", taskID).
+		Updates(updates)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// ApplyAttemptStatus 条件更新最终上报的结果（WHERE status NOT IN 终态），返回是否命中
+func (r *TaskRepo) ApplyAttemptStatus(ctx context.Context, taskID, status string, attempt int, lastError string, result json.RawMessage) (bool, error) {
+	updates := map[string]interface{}{
+		"status":       status,
+		"last_attempt": attempt,
+		"last_error":   lastError,
+		"updated_at":   time.Now(),
+	}
+	if len(result) > 0 {
+		updates["result"] = result
+	}
+
+	res := r.db.WithContext(ctx).
+		Model(&TaskModel{}).
+		Where("task_id = ? AND status NOT IN ?", taskID, model.TerminalStatuses()).
+		Updates(updates)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
 // GetTask 获取任务详情
 func (r *TaskRepo) GetTask(ctx context.Context, taskID string) (*Task, error) {
 	var model TaskModel
@@ -63,6 +160,22 @@ func (r *TaskRepo) GetTask(ctx context.Context, taskID string) (*Task, error) {
 	return &task, nil
 }
 
+// ExistingTaskIDs 返回 taskIDs 中已存在任务记录的 task_id 集合
+func (r *TaskRepo) ExistingTaskIDs(ctx context.Context, taskIDs []string) (map[string]struct{}, error) {
+	existing := make(map[string]struct{})
+	if len(taskIDs) == 0 {
+		return existing, nil
+	}
+	var ids []string
+	if err := r.db.WithContext(ctx).Model(&TaskModel{}).Where("task_id IN ?", taskIDs).Pluck("task_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		existing[id] = struct{}{}
+	}
+	return existing, nil
+}
+
 // ListTasks 查询任务列表
 func (r *TaskRepo) ListTasks(ctx context.Context, f ListTasksFilter) ([]Task, error) {
 	limit := f.Limit
@@ -74,34 +187,117 @@ func (r *TaskRepo) ListTasks(ctx context.Context, f ListTasksFilter) ([]Task, er
 		offset = 0
 	}
 
-	query := r.db.WithContext(ctx).Model(&TaskModel{})
+	query := applyTaskFilter(r.db.WithContext(ctx).Model(&TaskModel{}), f)
 
-	if f.WorkerName != "" {
-		query = query.Where("worker_name = ?", f.WorkerName)
+	var models []TaskModel
+	if err := query.Order(taskOrder(f)).Limit(limit).Offset(offset).Find(&models).Error; err != nil {
+		return nil, err
 	}
-	if f.Status != "" {
-		query = query.Where("status = ?", f.Status)
+
+	tasks := make([]Task, len(models))
+	for i, m := range models {
+		tasks[i] = m.ToTask()
 	}
-	if f.Queue != "" {
-		query = query.Where("queue = ?", f.Queue)
+	return tasks, nil
+}
+
+// ListTasksPage 按 (created_at, id) keyset 分页查询任务
+func (r *TaskRepo) ListTasksPage(ctx context.Context, f ListTasksFilter, cursor *Cursor) (*TaskPage, error) {
+	if f.SortBy != "" && f.SortBy != "created_at" {
+		return nil, errors.New("游标分页只支持按 created_at 排序")
+	}
+	limit := f.Limit
+	if limit <= 0 || limit > 200 {
+		limit = 50
 	}
 
+	query := applyTaskFilter(r.db.WithContext(ctx).Model(&TaskModel{}), f)
+	query = keysetScan(query, "created_at", !strings.EqualFold(f.SortOrder, "asc"), cursor)
+
 	var models []TaskModel
-	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&models).Error; err != nil {
+	if err := query.Limit(limit + 1).Find(&models).Error; err != nil {
 		return nil, err
 	}
 
+	models, next, prev := keysetPage(models, limit, cursor, func(m TaskModel) (time.Time, int64) {
+		return m.CreatedAt, m.ID
+	})
 	tasks := make([]Task, len(models))
 	for i, m := range models {
 		tasks[i] = m.ToTask()
 	}
-	return tasks, nil
+	return &TaskPage{Items: tasks, NextCursor: next, PrevCursor: prev}, nil
 }
 
-// CountTasks 统计任务数量
-func (r *TaskRepo) CountTasks(ctx context.Context, f ListTasksFilter) (int, error) {
-	query := r.db.WithContext(ctx).Model(&TaskModel{})
+// exportBatchSize 导出时每次从服务端游标读取的行数
+const exportBatchSize = 500
+
+// ExportTasks 使用服务端游标（DECLARE CURSOR）分批读取任务，内存占用与结果集大小无关
+func (r *TaskRepo) ExportTasks(ctx context.Context, f ListTasksFilter, includeAttempts bool, fn func(tasks []Task, attempts map[string][]Attempt) error) error {
+	db := r.db.WithContext(ctx)
+	stmt := applyTaskFilter(db.Session(&gorm.Session{DryRun: true}).Model(&TaskModel{}), f).
+		Order(taskOrder(f)).
+		Find(&[]TaskModel{}).Statement
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if _, err := tx.Statement.ConnPool.ExecContext(ctx,
+			"DECLARE task_export NO SCROLL CURSOR FOR "+stmt.SQL.String(), stmt.Vars...); err != nil {
+			return err
+		}
+
+		for {
+			var models []TaskModel
+			if err := tx.Raw(fmt.Sprintf("FETCH %d FROM task_export", exportBatchSize)).Scan(&models).Error; err != nil {
+				return err
+			}
+			if len(models) == 0 {
+				return nil
+			}
+
+			tasks := make([]Task, len(models))
+			taskIDs := make([]string, len(models))
+			for i, m := range models {
+				tasks[i] = m.ToTask()
+				taskIDs[i] = m.TaskID
+			}
+
+			var attempts map[string][]Attempt
+			if includeAttempts {
+				var attemptModels []TaskAttemptModel
+				if err := tx.Where("task_id IN ?", taskIDs).Order("started_at ASC, id ASC").Find(&attemptModels).Error; err != nil {
+					return err
+				}
+				attempts = make(map[string][]Attempt, len(models))
+				for _, am := range attemptModels {
+					attempts[am.TaskID] = append(attempts[am.TaskID], am.ToAttempt())
+				}
+			}
+
+			if err := fn(tasks, attempts); err != nil {
+				return err
+			}
+			if len(models) < exportBatchSize {
+				return nil
+			}
+		}
+	})
+}
+
+// EstimateTasks 根据查询计划估算任务数
+func (r *TaskRepo) EstimateTasks(ctx context.Context, f ListTasksFilter) (int, error) {
+	return estimateCount(ctx, r.db.WithContext(ctx), func(tx *gorm.DB) *gorm.DB {
+		return applyTaskFilter(tx.Model(&TaskModel{}), f)
+	})
+}
 
+// applyTaskFilter 按过滤条件追加 WHERE 子句
+func applyTaskFilter(query *gorm.DB, f ListTasksFilter) *gorm.DB {
+	if len(f.TaskIDs) > 0 {
+		query = query.Where("task_id IN ?", f.TaskIDs)
+	}
+	if f.MaxID > 0 {
+		query = query.Where("id <= ?", f.MaxID)
+	}
 	if f.WorkerName != "" {
 		query = query.Where("worker_name = ?", f.WorkerName)
 	}
@@ -111,6 +307,78 @@ func (r *TaskRepo) CountTasks(ctx context.Context, f ListTasksFilter) (int, erro
 	if f.Queue != "" {
 		query = query.Where("queue = ?", f.Queue)
 	}
+	if f.Priority > 0 {
+		query = query.Where("priority = ?", f.Priority)
+	}
+	if f.CreatedAfter != nil {
+		query = query.Where("created_at >= ?", *f.CreatedAfter)
+	}
+	if f.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *f.CreatedBefore)
+	}
+	if f.UpdatedAfter != nil {
+		query = query.Where("updated_at >= ?", *f.UpdatedAfter)
+	}
+	if f.UpdatedBefore != nil {
+		query = query.Where("updated_at < ?", *f.UpdatedBefore)
+	}
+	if f.ErrorContains != "" {
+		// 使用 trigram 索引 idx_task_last_error_trgm
+		query = query.Where("last_error ILIKE ?", "%"+likeEscaper.Replace(f.ErrorContains)+"%")
+	}
+	if len(f.PayloadContains) > 0 {
+		query = query.Where("payload @> ?::jsonb", string(f.PayloadContains))
+	}
+	if f.PayloadPath != "" {
+		// @@：jsonpath 谓词检查（GORM 会把 @? 中的 ? 当作占位符，因此使用谓词形式）
+		query = query.Where("payload @@ ?::jsonpath", f.PayloadPath)
+	}
+	return applyLabelSelector(query, "labels", f.Labels)
+}
+
+// ErrInvalidJSONPath jsonpath 表达式无法被 Postgres 解析
+var ErrInvalidJSONPath = errors.New("jsonpath 语法错误")
+
+// ValidateJSONPath 由 Postgres 解析 jsonpath 表达式（payload_path 过滤条件），语法错误时返回 ErrInvalidJSONPath
+func (r *TaskRepo) ValidateJSONPath(ctx context.Context, path string) error {
+	var valid bool
+	err := r.db.WithContext(ctx).Raw("SELECT ?::jsonpath IS NOT NULL", path).Scan(&valid).Error
+	var pgErr *pgconn.PgError
+	// 42xxx 语法错误，22xxx 数据异常（如无效的正则标志）
+	if errors.As(err, &pgErr) && (strings.HasPrefix(pgErr.Code, "42") || strings.HasPrefix(pgErr.Code, "22")) {
+		return fmt.Errorf("%w: %s", ErrInvalidJSONPath, pgErr.Message)
+	}
+	return err
+}
+
+// likeEscaper 转义 LIKE 通配符
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// taskOrder 返回 ListTasks 的排序子句（id 作为相同值时的次序）
+func taskOrder(f ListTasksFilter) string {
+	sortBy := "created_at"
+	if TaskSortFields[f.SortBy] {
+		sortBy = f.SortBy
+	}
+	order := "DESC"
+	if strings.EqualFold(f.SortOrder, "asc") {
+		order = "ASC"
+	}
+	return sortBy + " " + order + ", id " + order
+}
+
+// applyLabelSelector 追加标签包含条件（使用 GIN 索引 idx_task_labels）
+func applyLabelSelector(query *gorm.DB, column string, labels map[string]string) *gorm.DB {
+	if len(labels) == 0 {
+		return query
+	}
+	b, _ := json.Marshal(labels)
+	return query.Where(column+" @> ?::jsonb", string(b))
+}
+
+// CountTasks 统计任务数量
+func (r *TaskRepo) CountTasks(ctx context.Context, f ListTasksFilter) (int, error) {
+	query := applyTaskFilter(r.db.WithContext(ctx).Model(&TaskModel{}), f)
 
 	var count int64
 	if err := query.Count(&count).Error; err != nil {
@@ -147,8 +415,295 @@ func (r *TaskRepo) ListAttempts(ctx context.Context, taskID string, limit int) (
 	return attempts, nil
 }
 
+// ListAttemptsPage 按 (started_at, id) keyset 分页查询任务的执行尝试
+func (r *TaskRepo) ListAttemptsPage(ctx context.Context, taskID string, limit int, cursor *Cursor) (*AttemptPage, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	query := r.db.WithContext(ctx).Model(&TaskAttemptModel{}).Where("task_id = ?", taskID)
+	query = keysetScan(query, "started_at", true, cursor)
+
+	var models []TaskAttemptModel
+	if err := query.Limit(limit + 1).Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	models, next, prev := keysetPage(models, limit, cursor, func(m TaskAttemptModel) (time.Time, int64) {
+		return m.StartedAt, m.ID
+	})
+	attempts := make([]Attempt, len(models))
+	for i, m := range models {
+		attempts[i] = m.ToAttempt()
+	}
+	return &AttemptPage{Items: attempts, NextCursor: next, PrevCursor: prev}, nil
+}
+
+// CountAttempts 统计任务的执行尝试记录数
+func (r *TaskRepo) CountAttempts(ctx context.Context, taskID string) (int, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&TaskAttemptModel{}).Where("task_id = ?", taskID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// openAttemptRow ListOpenAttempts 的查询结果行
+type openAttemptRow struct {
+	TaskModel       `gorm:"embedded"`
+	AttemptNo       int        `gorm:"column:attempt_no"`
+	AttemptStarted  time.Time  `gorm:"column:attempt_started_at"`
+	AttemptWorker   *string    `gorm:"column:attempt_worker_name"`
+	TimeoutSecs     int32      `gorm:"column:effective_timeout"`
+	LastHeartbeatAt *time.Time `gorm:"column:last_heartbeat_at"`
+}
+
+// ListOpenAttempts 查询未结束的执行尝试：任务为 running，且其 last_attempt 对应的尝试记录仍为 running
+func (r *TaskRepo) ListOpenAttempts(ctx context.Context, startedBefore time.Time, afterID int64, limit int) ([]OpenAttempt, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	var rows []openAttemptRow
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT t.*,
+			a.attempt AS attempt_no,
+			a.started_at AS attempt_started_at,
+			a.worker_name AS attempt_worker_name,
+			COALESCE(t.timeout_seconds, w.default_timeout, 0) AS effective_timeout,
+			w.last_heartbeat_at
+		FROM task t
+		JOIN LATERAL (
+			SELECT attempt, started_at, worker_name FROM task_attempt
+			WHERE task_id = t.task_id AND attempt = t.last_attempt AND status = 'running'
+			ORDER BY started_at DESC
+			LIMIT 1
+		) a ON true
+		LEFT JOIN worker w ON w.worker_name = t.worker_name
+		WHERE t.status = 'running' AND t.id > ? AND a.started_at < ?
+		ORDER BY t.id ASC
+		LIMIT ?
+	`, afterID, startedBefore, limit).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	attempts := make([]OpenAttempt, len(rows))
+	for i, row := range rows {
+		attempts[i] = OpenAttempt{
+			Task:            row.TaskModel.ToTask(),
+			Attempt:         row.AttemptNo,
+			StartedAt:       row.AttemptStarted,
+			TimeoutSeconds:  row.TimeoutSecs,
+			LastHeartbeatAt: row.LastHeartbeatAt,
+		}
+		if row.AttemptWorker != nil {
+			attempts[i].WorkerName = *row.AttemptWorker
+		}
+	}
+	return attempts, nil
+}
+
+// CloseAttempt 结束仍为 running 的执行尝试（失联检测使用），duration_ms 按 started_at 计算
+func (r *TaskRepo) CloseAttempt(ctx context.Context, taskID string, attempt int, status, errMsg string, finishedAt time.Time) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&TaskAttemptModel{}).
+		Where("task_id = ? AND attempt = ? AND status = 'running'", taskID, attempt).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"error":       errMsg,
+			"finished_at": finishedAt,
+			"duration_ms": gorm.Expr("(EXTRACT(EPOCH FROM (?::timestamptz - started_at)) * 1000)::int", finishedAt),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// UpsertProgress 保存任务某次尝试的最新进度
+func (r *TaskRepo) UpsertProgress(ctx context.Context, p TaskProgress) error {
+	model := TaskProgressModel{
+		TaskID:    p.TaskID,
+		Attempt:   p.Attempt,
+		Percent:   p.Percent,
+		UpdatedAt: time.Now(),
+	}
+	if p.Message != "" {
+		model.Message = &p.Message
+	}
+
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "task_id"}, {Name: "attempt"}},
+		DoUpdates: clause.AssignmentColumns([]string{"percent", "message", "updated_at"}),
+	}).Create(&model).Error
+}
+
+// GetLatestProgress 获取任务最近一次尝试的进度
+func (r *TaskRepo) GetLatestProgress(ctx context.Context, taskID string) (*TaskProgress, error) {
+	var models []TaskProgressModel
+	if err := r.db.WithContext(ctx).
+		Where("task_id = ?", taskID).
+		Order("attempt DESC").
+		Limit(1).
+		Find(&models).Error; err != nil {
+		return nil, err
+	}
+	if len(models) == 0 {
+		return nil, nil
+	}
+	p := models[0].ToTaskProgress()
+	return &p, nil
+}
+
+// PurgeTasks 在单个事务中清理一批过期任务：锁定任务行（SKIP LOCKED，多副本同时清理互不阻塞），
+// 按需归档，再依次删除执行尝试与任务（进度与日志随任务级联删除）
+func (r *TaskRepo) PurgeTasks(ctx context.Context, f PurgeFilter, limit int, archive bool) (PurgeResult, error) {
+	var res PurgeResult
+	if len(f.Statuses) == 0 || limit <= 0 {
+		return res, nil
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&TaskModel{}).
+			Where("status IN ? AND updated_at < ?", f.Statuses, f.Before)
+		if f.WorkerName != "" {
+			query = query.Where("worker_name = ?", f.WorkerName)
+		}
+		if len(f.ExcludeWorkers) > 0 {
+			query = query.Where("worker_name NOT IN ?", f.ExcludeWorkers)
+		}
+
+		var taskIDs []string
+		if err := query.Order("updated_at ASC").Limit(limit).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Pluck("task_id", &taskIDs).Error; err != nil {
+			return err
+		}
+		if len(taskIDs) == 0 {
+			return nil
+		}
+
+		if archive {
+			if err := tx.Exec(`
+INSERT INTO task_archive (task_id, worker_name, status, created_at, updated_at, task, attempts)
+SELECT t.task_id, t.worker_name, t.status, t.created_at, t.updated_at, to_jsonb(t),
+	COALESCE((SELECT jsonb_agg(to_jsonb(a) ORDER BY a.started_at, a.id) FROM task_attempt a WHERE a.task_id = t.task_id), '[]'::jsonb)
+FROM task t WHERE t.task_id IN ?
+ON CONFLICT (task_id) DO NOTHING`, taskIDs).Error; err != nil {
+				return err
+			}
+		}
+
+		deleted := tx.Where("task_id IN ?", taskIDs).Delete(&TaskAttemptModel{})
+		if deleted.Error != nil {
+			return deleted.Error
+		}
+		res.Attempts = int(deleted.RowsAffected)
+
+		deleted = tx.Where("task_id IN ?", taskIDs).Delete(&TaskModel{})
+		if deleted.Error != nil {
+			return deleted.Error
+		}
+		res.Tasks = int(deleted.RowsAffected)
+		return nil
+	})
+	if err != nil {
+		return PurgeResult{}, err
+	}
+	return res, nil
+}
+
+// maxReplayLineage 重放链每个方向最多返回的任务数（同时作为递归深度上限）
+const maxReplayLineage = 100
+
+// GetReplayLineage 沿 replayed_from 双向递归查询任务的重放链
+func (r *TaskRepo) GetReplayLineage(ctx context.Context, taskID string) (*ReplayLineage, error) {
+	db := r.db.WithContext(ctx)
+	lineage := &ReplayLineage{Ancestors: []ReplayLink{}, Replays: []ReplayLink{}}
+
+	if err := db.Raw(`
+WITH RECURSIVE up AS (
+	SELECT p.task_id, p.replayed_from, p.status, p.created_at, 1 AS depth
+	FROM task c JOIN task p ON p.task_id = c.replayed_from
+	WHERE c.task_id = ?
+	UNION ALL
+	SELECT p.task_id, p.replayed_from, p.status, p.created_at, up.depth + 1
+	FROM up JOIN task p ON p.task_id = up.replayed_from
+	WHERE up.depth < ?
+)
+SELECT task_id, COALESCE(replayed_from, '') AS replayed_from, status, created_at FROM up ORDER BY depth`,
+		taskID, maxReplayLineage).Scan(&lineage.Ancestors).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.Raw(`
+WITH RECURSIVE down AS (
+	SELECT task_id, replayed_from, status, created_at, 1 AS depth
+	FROM task WHERE replayed_from = ?
+	UNION ALL
+	SELECT t.task_id, t.replayed_from, t.status, t.created_at, down.depth + 1
+	FROM down JOIN task t ON t.replayed_from = down.task_id
+	WHERE down.depth < ?
+)
+SELECT task_id, COALESCE(replayed_from, '') AS replayed_from, status, created_at FROM down ORDER BY created_at, task_id LIMIT ?`,
+		taskID, maxReplayLineage, maxReplayLineage).Scan(&lineage.Replays).Error; err != nil {
+		return nil, err
+	}
+
+	return lineage, nil
+}
+
+// InsertTaskLogs 批量写入任务执行日志
+func (r *TaskRepo) InsertTaskLogs(ctx context.Context, logs []TaskLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	models := make([]TaskLogModel, len(logs))
+	for i, l := range logs {
+		models[i] = TaskLogModel{
+			TaskID:   l.TaskID,
+			Attempt:  l.Attempt,
+			Level:    l.Level,
+			Message:  l.Message,
+			LoggedAt: l.LoggedAt,
+		}
+	}
+	return r.db.WithContext(ctx).Create(&models).Error
+}
+
+// ListTaskLogs 查询任务执行日志
+func (r *TaskRepo) ListTaskLogs(ctx context.Context, f ListTaskLogsFilter) ([]TaskLog, error) {
+	limit := f.Limit
+	if limit <= 0 || limit > 1000 {
+		limit = 500
+	}
+
+	query := r.db.WithContext(ctx).Model(&TaskLogModel{}).Where("task_id = ?", f.TaskID)
+	if f.Attempt > 0 {
+		query = query.Where("attempt = ?", f.Attempt)
+	}
+	if f.AfterID > 0 {
+		query = query.Where("id > ?", f.AfterID)
+	}
+
+	var models []TaskLogModel
+	if err := query.Order("id ASC").Limit(limit).Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	logs := make([]TaskLog, len(models))
+	for i, m := range models {
+		logs[i] = m.ToTaskLog()
+	}
+	return logs, nil
+}
+
+// avgDurationWindow worker 统计中平均执行时间的统计窗口
+const avgDurationWindow = 7 * 24 * time.Hour
+
 // GetWorkerStats 获取指定 worker 的统计信息
-func (r *TaskRepo) GetWorkerStats(ctx context.Context, workerName string) (*WorkerStats, error) {
+func (r *TaskRepo) GetWorkerStats(ctx context.Context, workerName string, labels map[string]string) (*WorkerStats, error) {
 	stats := &WorkerStats{
 		QueueStats:        make(map[string]int),
 		QueueSuccessStats: make(map[string]int),
@@ -163,8 +718,7 @@ func (r *TaskRepo) GetWorkerStats(ctx context.Context, workerName string) (*Work
 	}
 	var statusCounts []StatusCount
 
-	if err := r.db.WithContext(ctx).
-		Model(&TaskModel{}).
+	if err := applyLabelSelector(r.db.WithContext(ctx).Model(&TaskModel{}), "labels", labels).
 		Select("status, count(*) as count").
 		Where("worker_name = ?", workerName).
 		Group("status").
@@ -183,6 +737,10 @@ func (r *TaskRepo) GetWorkerStats(ctx context.Context, workerName string) (*Work
 			stats.SuccessTasks = sc.Count
 		case "fail":
 			stats.FailedTasks = sc.Count
+		case "dead":
+			stats.DeadTasks = sc.Count
+		case "canceled":
+			stats.CanceledTasks = sc.Count
 		}
 	}
 
@@ -191,13 +749,13 @@ func (r *TaskRepo) GetWorkerStats(ctx context.Context, workerName string) (*Work
 		stats.SuccessRate = float64(stats.SuccessTasks) / float64(stats.TotalTasks) * 100
 	}
 
-	// 计算平均执行时间
+	// 计算平均执行时间（限定 started_at 范围，只扫描最近的分区）
 	var avgDuration *int
-	r.db.WithContext(ctx).
-		Model(&TaskAttemptModel{}).
+	applyLabelSelector(r.db.WithContext(ctx).Model(&TaskAttemptModel{}), "task.labels", labels).
 		Select("COALESCE(AVG(duration_ms)::int, 0)").
 		Joins("JOIN task ON task_attempt.task_id = task.task_id").
 		Where("task.worker_name = ? AND task_attempt.duration_ms IS NOT NULL AND task_attempt.status = 'success'", workerName).
+		Where("task_attempt.started_at >= ?", time.Now().Add(-avgDurationWindow)).
 		Scan(&avgDuration)
 
 	if avgDuration != nil {
@@ -211,8 +769,7 @@ func (r *TaskRepo) GetWorkerStats(ctx context.Context, workerName string) (*Work
 	}
 	var queueCounts []QueueCount
 
-	if err := r.db.WithContext(ctx).
-		Model(&TaskModel{}).
+	if err := applyLabelSelector(r.db.WithContext(ctx).Model(&TaskModel{}), "labels", labels).
 		Select("queue, count(*) as total").
 		Where("worker_name = ?", workerName).
 		Group("queue").
@@ -226,10 +783,18 @@ func (r *TaskRepo) GetWorkerStats(ctx context.Context, workerName string) (*Work
 }
 
 // GetWorkerTimeSeriesStats 获取 worker 的时间序列统计
-func (r *TaskRepo) GetWorkerTimeSeriesStats(ctx context.Context, workerName string, hours int) ([]TimeSeriesStats, error) {
+func (r *TaskRepo) GetWorkerTimeSeriesStats(ctx context.Context, workerName string, hours int, labels map[string]string) ([]TimeSeriesStats, error) {
 	if hours <= 0 || hours > 168 {
 		hours = 24
 	}
+	// 空选择器 '{}' 匹配所有任务
+	selector := []byte("{}")
+	if len(labels) > 0 {
+		selector, _ = json.Marshal(labels)
+	}
+
+	// 起始时间作为参数传入（而非 now() - interval），规划时即可裁剪掉范围之外的分区
+	since := time.Now().Add(-time.Duration(hours) * time.Hour)
 
 	var results []TimeSeriesStats
 
@@ -244,10 +809,11 @@ func (r *TaskRepo) GetWorkerTimeSeriesStats(ctx context.Context, workerName stri
 		FROM task_attempt ta
 		JOIN task t ON ta.task_id = t.task_id
 		WHERE t.worker_name = ? 
-		  AND ta.started_at >= now() - interval '1 hour' * ?
+		  AND ta.started_at >= ?
+		  AND t.labels @> ?::jsonb
 		GROUP BY hour
 		ORDER BY hour ASC
-	`, workerName, hours).Scan(&results).Error
+	`, workerName, since, string(selector)).Scan(&results).Error
 
 	if err != nil {
 		return nil, err
@@ -256,13 +822,13 @@ func (r *TaskRepo) GetWorkerTimeSeriesStats(ctx context.Context, workerName stri
 	return results, nil
 }
 
-// ListFailedTasks 查询失败的任务列表（用于批量重试）
+// ListFailedTasks 查询失败的任务列表（用于批量重试，包含 fail 与 dead）
 func (r *TaskRepo) ListFailedTasks(ctx context.Context, workerName string, limit int) ([]Task, error) {
 	if limit <= 0 || limit > 1000 {
 		limit = 100
 	}
 
-	query := r.db.WithContext(ctx).Model(&TaskModel{}).Where("status = 'fail'")
+	query := r.db.WithContext(ctx).Model(&TaskModel{}).Where("status IN ('fail', 'dead')")
 
 	if workerName != "" {
 		query = query.Where("worker_name = ?", workerName)
@@ -279,3 +845,90 @@ func (r *TaskRepo) ListFailedTasks(ctx context.Context, workerName string, limit
 	}
 	return tasks, nil
 }
+
+// ListTasksForReconcile 按 id keyset 分批查询待对账的任务
+func (r *TaskRepo) ListTasksForReconcile(ctx context.Context, statuses []string, before time.Time, afterID int64, limit int) ([]Task, error) {
+	if len(statuses) == 0 || limit <= 0 {
+		return nil, nil
+	}
+
+	var models []TaskModel
+	if err := r.db.WithContext(ctx).
+		Where("id > ? AND status IN ? AND updated_at < ?", afterID, statuses, before).
+		Where("NOT EXISTS (SELECT 1 FROM task_outbox o WHERE o.task_id = task.task_id AND o.delivered_at IS NULL)").
+		Order("id ASC").Limit(limit).
+		Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	tasks := make([]Task, len(models))
+	for i, m := range models {
+		tasks[i] = m.ToTask()
+	}
+	return tasks, nil
+}
+
+// ListTasksAfterID 按 id 升序查询满足过滤条件且 id 大于 afterID 的任务
+func (r *TaskRepo) ListTasksAfterID(ctx context.Context, f ListTasksFilter, afterID int64, limit int) ([]Task, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	var models []TaskModel
+	query := applyTaskFilter(r.db.WithContext(ctx).Model(&TaskModel{}), f)
+	if err := query.Where("id > ?", afterID).Order("id ASC").Limit(limit).Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	tasks := make([]Task, len(models))
+	for i, m := range models {
+		tasks[i] = m.ToTask()
+	}
+	return tasks, nil
+}
+
+// MaxTaskID 返回当前 task.id 的最大值
+func (r *TaskRepo) MaxTaskID(ctx context.Context) (int64, error) {
+	var maxID int64
+	err := r.db.WithContext(ctx).Model(&TaskModel{}).Select("COALESCE(max(id), 0)").Scan(&maxID).Error
+	return maxID, err
+}
+
+// DeleteTask 删除任务记录及其执行尝试（task_attempt 为分区表，外键不级联，需显式删除）
+func (r *TaskRepo) DeleteTask(ctx context.Context, taskID string) (bool, error) {
+	var deleted bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("task_id = ?", taskID).Delete(&TaskAttemptModel{}).Error; err != nil {
+			return err
+		}
+		res := tx.Where("task_id = ?", taskID).Delete(&TaskModel{})
+		if res.Error != nil {
+			return res.Error
+		}
+		deleted = res.RowsAffected > 0
+		return nil
+	})
+	return deleted, err
+}
+
+// MoveTaskQueue 修改任务所在队列与优先级，from 不为空时作为状态条件
+func (r *TaskRepo) MoveTaskQueue(ctx context.Context, taskID, from, queue string, priority int, asynqTaskID string) (bool, error) {
+	updates := map[string]interface{}{
+		"queue":      queue,
+		"priority":   priority,
+		"updated_at": time.Now(),
+	}
+	if asynqTaskID != "" {
+		updates["asynq_task_id"] = asynqTaskID
+	}
+
+	query := r.db.WithContext(ctx).Model(&TaskModel{}).Where("task_id = ?", taskID)
+	if from != "" {
+		query = query.Where("status = ?", from)
+	}
+	res := query.Updates(updates)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
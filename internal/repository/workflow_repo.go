@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WorkflowRepo 工作流仓储实现
+type WorkflowRepo struct {
+	db *gorm.DB
+}
+
+// NewWorkflowRepo 创建工作流仓储
+func NewWorkflowRepo(db *gorm.DB) *WorkflowRepo {
+	return &WorkflowRepo{db: db}
+}
+
+// CreateWorkflow 在同一事务中创建工作流及其全部任务
+func (r *WorkflowRepo) CreateWorkflow(ctx context.Context, w Workflow, tasks []Task) error {
+	if w.WorkflowID == "" {
+		return errors.New("workflow_id 不能为空")
+	}
+
+	now := time.Now()
+	models := make([]TaskModel, len(tasks))
+	for i, t := range tasks {
+		if t.TaskID == "" {
+			return errors.New("task_id 不能为空")
+		}
+		t.WorkflowID = w.WorkflowID
+		models[i] = TaskToModel(t)
+		models[i].UpdatedAt = now
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		wm := WorkflowModel{
+			WorkflowID: w.WorkflowID,
+			Name:       w.Name,
+			Status:     w.Status,
+		}
+		if err := tx.Create(&wm).Error; err != nil {
+			return err
+		}
+		if len(models) == 0 {
+			return nil
+		}
+		return tx.Create(&models).Error
+	})
+}
+
+// GetWorkflow 获取工作流
+func (r *WorkflowRepo) GetWorkflow(ctx context.Context, workflowID string) (*Workflow, error) {
+	var model WorkflowModel
+	if err := r.db.WithContext(ctx).Where("workflow_id = ?", workflowID).First(&model).Error; err != nil {
+		return nil, err
+	}
+	w := model.ToWorkflow()
+	return &w, nil
+}
+
+// ListWorkflows 查询工作流列表
+func (r *WorkflowRepo) ListWorkflows(ctx context.Context, status string, limit, offset int) ([]Workflow, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := r.db.WithContext(ctx).Model(&WorkflowModel{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var models []WorkflowModel
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	workflows := make([]Workflow, len(models))
+	for i, m := range models {
+		workflows[i] = m.ToWorkflow()
+	}
+	return workflows, nil
+}
+
+// CountWorkflows 统计工作流数量
+func (r *WorkflowRepo) CountWorkflows(ctx context.Context, status string) (int, error) {
+	query := r.db.WithContext(ctx).Model(&WorkflowModel{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// ListWorkflowTasks 查询工作流下的全部任务（按创建顺序）
+func (r *WorkflowRepo) ListWorkflowTasks(ctx context.Context, workflowID string) ([]Task, error) {
+	var models []TaskModel
+	if err := r.db.WithContext(ctx).
+		Where("workflow_id = ?", workflowID).
+		Order("id ASC").
+		Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	tasks := make([]Task, len(models))
+	for i, m := range models {
+		tasks[i] = m.ToTask()
+	}
+	return tasks, nil
+}
+
+// UpdateWorkflowStatus 更新工作流状态
+func (r *WorkflowRepo) UpdateWorkflowStatus(ctx context.Context, workflowID, status string) error {
+	return r.db.WithContext(ctx).
+		Model(&WorkflowModel{}).
+		Where("workflow_id = ?", workflowID).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"updated_at": time.Now(),
+		}).Error
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// Workflow 工作流实体：一组通过 depends_on 组成 DAG 的任务
+type Workflow struct {
+	WorkflowID string    `json:"workflow_id"`
+	Name       string    `json:"name"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// WorkflowRepository 工作流仓储接口
+type WorkflowRepository interface {
+	// CreateWorkflow 在同一事务中创建工作流及其全部任务
+	CreateWorkflow(ctx context.Context, workflow Workflow, tasks []Task) error
+
+	// GetWorkflow 根据 workflow_id 获取工作流
+	GetWorkflow(ctx context.Context, workflowID string) (*Workflow, error)
+
+	// ListWorkflows 查询工作流列表（按创建时间倒序，可按状态过滤）
+	ListWorkflows(ctx context.Context, status string, limit, offset int) ([]Workflow, error)
+
+	// CountWorkflows 统计工作流总数
+	CountWorkflows(ctx context.Context, status string) (int, error)
+
+	// ListWorkflowTasks 查询工作流下的全部任务
+	ListWorkflowTasks(ctx context.Context, workflowID string) ([]Task, error)
+
+	// UpdateWorkflowStatus 更新工作流状态
+	UpdateWorkflowStatus(ctx context.Context, workflowID, status string) error
+}
@@ -0,0 +1,195 @@
+package retention
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/azhengyongqin/asynq-hub/internal/config"
+	"github.com/azhengyongqin/asynq-hub/internal/logger"
+	"github.com/azhengyongqin/asynq-hub/internal/metrics"
+	"github.com/azhengyongqin/asynq-hub/internal/model"
+	"github.com/azhengyongqin/asynq-hub/internal/periodic"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+)
+
+// batchPause 两批清理之间的停顿，避免长时间占用数据库
+const batchPause = 100 * time.Millisecond
+
+// PurgeableStatuses 保留策略可清理的任务状态（终态）。
+// fail 不在其中：任务仍在 asynq 的重试队列中，清理后下一次重试的上报会找不到任务。
+var PurgeableStatuses = model.TerminalStatuses()
+
+// Store 保留策略用到的存储操作（repository.TaskRepository 满足该接口）
+type Store interface {
+	PurgeTasks(ctx context.Context, filter repository.PurgeFilter, limit int, archive bool) (repository.PurgeResult, error)
+	EnsureAttemptPartitions(ctx context.Context, from time.Time, months int) ([]string, error)
+	DropAttemptPartitions(ctx context.Context, before time.Time) ([]string, error)
+}
+
+// Job 保留策略后台任务：按间隔维护执行尝试的月分区，并（Enabled 时）清理过期的任务及执行尝试。
+// 每批在单个事务中完成且按行加锁（SKIP LOCKED），分区维护持有 advisory lock，多副本同时运行也不会重复处理。
+type Job struct {
+	store Store
+	cfg   config.RetentionConfig
+	now   func() time.Time
+
+	loop periodic.Loop
+}
+
+// New 创建保留策略任务
+func New(store Store, cfg config.RetentionConfig) *Job {
+	return &Job{store: store, cfg: cfg, now: time.Now}
+}
+
+// Start 启动后台任务（立即执行一次，之后按 Interval 执行）
+func (j *Job) Start() {
+	j.loop.Start(j.cfg.Interval, func(ctx context.Context) { _, _ = j.RunOnce(ctx) })
+}
+
+// Stop 停止后台清理并等待当前批次结束
+func (j *Job) Stop() {
+	j.loop.Stop()
+}
+
+// RunOnce 执行一轮分区维护与清理，返回清理的任务与执行尝试行数（不含整分区删除的执行尝试）
+func (j *Job) RunOnce(ctx context.Context) (repository.PurgeResult, error) {
+	start := j.now()
+
+	// 分区创建失败时新数据落入默认分区，不影响写入，因此继续清理任务
+	partErr := j.maintainPartitions(ctx, start)
+
+	var total repository.PurgeResult
+	if j.cfg.Enabled {
+		var err error
+		if total, err = j.purge(ctx, start); err != nil {
+			return total, err
+		}
+	}
+	if partErr != nil {
+		return total, partErr
+	}
+
+	metrics.RetentionRunDuration.Observe(time.Since(start).Seconds())
+	metrics.RetentionLastSuccess.Set(float64(j.now().Unix()))
+	return total, nil
+}
+
+// maintainPartitions 提前创建当前月及之后 PartitionsAhead 个月的执行尝试分区，
+// 启用清理且配置了 AttemptAge 时整体删除超出保留时长的月分区
+func (j *Job) maintainPartitions(ctx context.Context, now time.Time) error {
+	created, err := j.store.EnsureAttemptPartitions(ctx, now, j.cfg.PartitionsAhead+1)
+	if err != nil {
+		if ctx.Err() == nil {
+			metrics.RecordError("retention", "partition")
+			logger.L.Error().Err(err).Msg("创建执行尝试分区失败")
+		}
+		return err
+	}
+	if len(created) > 0 {
+		metrics.RecordRetentionPartitions("created", len(created))
+		logger.L.Info().Strs("partitions", created).Msg("已创建执行尝试分区")
+	}
+
+	if !j.cfg.Enabled || j.cfg.AttemptAge <= 0 {
+		return nil
+	}
+	dropped, err := j.store.DropAttemptPartitions(ctx, now.Add(-j.cfg.AttemptAge))
+	if err != nil {
+		if ctx.Err() == nil {
+			metrics.RecordError("retention", "partition")
+			logger.L.Error().Err(err).Msg("删除过期执行尝试分区失败")
+		}
+		return err
+	}
+	if len(dropped) > 0 {
+		metrics.RecordRetentionPartitions("dropped", len(dropped))
+		logger.L.Info().Strs("partitions", dropped).Msg("已删除过期执行尝试分区")
+	}
+	return nil
+}
+
+// purge 按保留策略分批清理过期任务
+func (j *Job) purge(ctx context.Context, now time.Time) (repository.PurgeResult, error) {
+	mode := "delete"
+	if j.cfg.Archive {
+		mode = "archive"
+	}
+
+	var total repository.PurgeResult
+	for _, f := range rules(j.cfg, now) {
+		for {
+			res, err := j.store.PurgeTasks(ctx, f, j.cfg.BatchSize, j.cfg.Archive)
+			if err != nil {
+				if ctx.Err() == nil {
+					metrics.RecordError("retention", "purge")
+					logger.L.Error().Err(err).Str("worker_name", f.WorkerName).Strs("statuses", f.Statuses).Msg("清理过期任务失败")
+				}
+				return total, err
+			}
+			total.Tasks += res.Tasks
+			total.Attempts += res.Attempts
+			metrics.RecordRetentionPurged(mode, res.Tasks, res.Attempts)
+
+			if res.Tasks < j.cfg.BatchSize {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return total, ctx.Err()
+			case <-time.After(batchPause):
+			}
+		}
+	}
+
+	if total.Tasks > 0 {
+		logger.L.Info().Int("tasks", total.Tasks).Int("attempts", total.Attempts).Str("mode", mode).Msg("已清理过期任务")
+	}
+	return total, nil
+}
+
+// rules 将保留策略展开为清理条件：
+// 配置了保留时长的 worker 单独清理（时长为 0 表示永久保留），其余任务按状态时长清理，未配置的状态使用默认时长
+func rules(cfg config.RetentionConfig, now time.Time) []repository.PurgeFilter {
+	var filters []repository.PurgeFilter
+
+	workerNames := make([]string, 0, len(cfg.WorkerAges))
+	for name := range cfg.WorkerAges {
+		workerNames = append(workerNames, name)
+	}
+	sort.Strings(workerNames)
+	for _, name := range workerNames {
+		if age := cfg.WorkerAges[name]; age > 0 {
+			filters = append(filters, repository.PurgeFilter{
+				WorkerName: name,
+				Statuses:   PurgeableStatuses,
+				Before:     now.Add(-age),
+			})
+		}
+	}
+
+	var defaultStatuses []string
+	for _, status := range PurgeableStatuses {
+		age, ok := cfg.StatusAges[status]
+		if !ok {
+			defaultStatuses = append(defaultStatuses, status)
+			continue
+		}
+		if age > 0 {
+			filters = append(filters, repository.PurgeFilter{
+				ExcludeWorkers: workerNames,
+				Statuses:       []string{status},
+				Before:         now.Add(-age),
+			})
+		}
+	}
+
+	if cfg.DefaultAge > 0 && len(defaultStatuses) > 0 {
+		filters = append(filters, repository.PurgeFilter{
+			ExcludeWorkers: workerNames,
+			Statuses:       defaultStatuses,
+			Before:         now.Add(-cfg.DefaultAge),
+		})
+	}
+	return filters
+}
@@ -0,0 +1,133 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/azhengyongqin/asynq-hub/internal/config"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+)
+
+const day = 24 * time.Hour
+
+// fakeStore 按 remaining 中的剩余数量逐批返回清理结果，并记录分区维护调用
+type fakeStore struct {
+	remaining map[string]int // key: worker_name 或 statuses[0]
+	calls     []repository.PurgeFilter
+	err       error
+
+	ensured    []time.Time
+	dropBefore []time.Time
+	partErr    error
+}
+
+func (p *fakeStore) EnsureAttemptPartitions(_ context.Context, from time.Time, months int) ([]string, error) {
+	if p.partErr != nil {
+		return nil, p.partErr
+	}
+	p.ensured = append(p.ensured, from)
+	return make([]string, months), nil
+}
+
+func (p *fakeStore) DropAttemptPartitions(_ context.Context, before time.Time) ([]string, error) {
+	p.dropBefore = append(p.dropBefore, before)
+	return nil, nil
+}
+
+func (p *fakeStore) PurgeTasks(_ context.Context, f repository.PurgeFilter, limit int, _ bool) (repository.PurgeResult, error) {
+	p.calls = append(p.calls, f)
+	if p.err != nil {
+		return repository.PurgeResult{}, p.err
+	}
+	key := f.WorkerName
+	if key == "" {
+		key = f.Statuses[0]
+	}
+	n := min(p.remaining[key], limit)
+	p.remaining[key] -= n
+	return repository.PurgeResult{Tasks: n, Attempts: 2 * n}, nil
+}
+
+func TestRules(t *testing.T) {
+	now := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	cfg := config.RetentionConfig{
+		DefaultAge: 30 * day,
+		StatusAges: map[string]time.Duration{"success": 7 * day, "dead": 90 * day, "canceled": 0},
+		WorkerAges: map[string]time.Duration{"crawler": 3 * day, "billing": 0},
+	}
+
+	filters := rules(cfg, now)
+	require.Len(t, filters, 4)
+
+	assert.Equal(t, "crawler", filters[0].WorkerName)
+	assert.Equal(t, PurgeableStatuses, filters[0].Statuses)
+	assert.Equal(t, now.Add(-3*day), filters[0].Before)
+
+	assert.Equal(t, []string{"success"}, filters[1].Statuses)
+	assert.Equal(t, now.Add(-7*day), filters[1].Before)
+	assert.Equal(t, []string{"billing", "crawler"}, filters[1].ExcludeWorkers, "单独配置的 worker（含永久保留）不参与状态规则")
+
+	assert.Equal(t, []string{"dead"}, filters[2].Statuses)
+
+	assert.Equal(t, []string{"skipped", "expired", "deleted", "lost"}, filters[3].Statuses, "canceled=0 永久保留，不走默认时长")
+	assert.Equal(t, now.Add(-30*day), filters[3].Before)
+
+	assert.Empty(t, rules(config.RetentionConfig{}, now), "未配置任何时长时不清理")
+}
+
+func TestRunOnce(t *testing.T) {
+	store := &fakeStore{remaining: map[string]int{"success": 25, "dead": 3}}
+	job := New(store, config.RetentionConfig{
+		Enabled:    true,
+		BatchSize:  10,
+		StatusAges: map[string]time.Duration{"success": 7 * day, "dead": 90 * day},
+	})
+
+	total, err := job.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 28, total.Tasks)
+	assert.Equal(t, 56, total.Attempts)
+	assert.Len(t, store.calls, 4, "success 分 3 批（10+10+5），dead 1 批")
+
+	store.err = errors.New("db down")
+	_, err = job.RunOnce(context.Background())
+	assert.Error(t, err)
+}
+
+func TestRunOnce_Partitions(t *testing.T) {
+	now := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	store := &fakeStore{remaining: map[string]int{"success": 5}}
+	job := New(store, config.RetentionConfig{
+		BatchSize:       10,
+		PartitionsAhead: 3,
+		AttemptAge:      90 * day,
+		StatusAges:      map[string]time.Duration{"success": 7 * day},
+	})
+	job.now = func() time.Time { return now }
+
+	total, err := job.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{now}, store.ensured, "未启用清理时仍维护分区")
+	assert.Empty(t, store.dropBefore, "未启用清理时不删除分区")
+	assert.Empty(t, store.calls)
+	assert.Zero(t, total.Tasks)
+
+	job.cfg.Enabled = true
+	total, err = job.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{now.Add(-90 * day)}, store.dropBefore)
+	assert.Equal(t, 5, total.Tasks)
+
+	// 分区维护失败不影响任务清理，但本轮返回错误
+	store.partErr = errors.New("lock timeout")
+	store.remaining["success"] = 3
+	total, err = job.RunOnce(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 3, total.Tasks)
+}
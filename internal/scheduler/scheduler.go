@@ -0,0 +1,240 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/robfig/cron/v3"
+
+	"github.com/azhengyongqin/asynq-hub/internal/logger"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+)
+
+const (
+	// TriggerQueue 触发任务所在的内部队列（仅由控制面消费）
+	TriggerQueue = "asynqhub:scheduler"
+
+	// TriggerTaskType 触发任务类型
+	TriggerTaskType = "asynqhub:schedule:fire"
+
+	// 触发状态
+	RunStatusEnqueued = "enqueued"
+	RunStatusFailed   = "failed"
+)
+
+// Firer 按周期任务定义创建一个任务（与 POST /tasks 走同一条路径），返回 task_id
+type Firer interface {
+	FireSchedule(ctx context.Context, schedule repository.Schedule) (string, error)
+}
+
+// triggerPayload 触发任务的 payload。
+// 包含 updated_at，使定义变更后 PeriodicTaskManager 能识别并重新注册。
+type triggerPayload struct {
+	ScheduleID string `json:"schedule_id"`
+	UpdatedAt  int64  `json:"updated_at"`
+}
+
+// Scheduler 周期任务调度器：
+// - PeriodicTaskManager 按 schedule 表中启用的定义，定时向内部队列投递触发任务
+// - 内部 asynq.Server 消费触发任务，抢占本次触发后通过 Firer 创建真正的任务
+//
+// 多副本部署时每个副本都会投递触发任务：触发任务按周期设置 Unique，
+// 且消费时通过 ClaimRun 条件更新 last_run_at，保证每个周期只创建一次任务。
+type Scheduler struct {
+	repo  repository.ScheduleRepository
+	firer Firer
+	mgr   *asynq.PeriodicTaskManager
+	srv   *asynq.Server
+}
+
+// New 创建调度器
+func New(redisOpt asynq.RedisConnOpt, repo repository.ScheduleRepository, firer Firer, syncInterval time.Duration) (*Scheduler, error) {
+	s := &Scheduler{repo: repo, firer: firer}
+
+	mgr, err := asynq.NewPeriodicTaskManager(asynq.PeriodicTaskManagerOpts{
+		PeriodicTaskConfigProvider: s,
+		RedisConnOpt:               redisOpt,
+		SchedulerOpts: &asynq.SchedulerOpts{
+			LogLevel: asynq.WarnLevel,
+			PostEnqueueFunc: func(info *asynq.TaskInfo, err error) {
+				if err != nil && !errors.Is(err, asynq.ErrDuplicateTask) {
+					logger.L.Error().Err(err).Msg("投递周期触发任务失败")
+				}
+			},
+		},
+		SyncInterval: syncInterval,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.mgr = mgr
+
+	s.srv = asynq.NewServer(redisOpt, asynq.Config{
+		Concurrency: 2,
+		Queues:      map[string]int{TriggerQueue: 1},
+		LogLevel:    asynq.WarnLevel,
+	})
+	return s, nil
+}
+
+// Start 启动调度器与触发任务消费者
+func (s *Scheduler) Start() error {
+	mux := asynq.NewServeMux()
+	mux.HandleFunc(TriggerTaskType, s.handleTrigger)
+	if err := s.srv.Start(mux); err != nil {
+		return fmt.Errorf("start trigger server: %w", err)
+	}
+	if err := s.mgr.Start(); err != nil {
+		s.srv.Shutdown()
+		return fmt.Errorf("start periodic task manager: %w", err)
+	}
+	return nil
+}
+
+// Shutdown 停止调度器
+func (s *Scheduler) Shutdown() {
+	s.mgr.Shutdown()
+	s.srv.Shutdown()
+}
+
+// GetConfigs 实现 asynq.PeriodicTaskConfigProvider：返回所有启用的周期任务
+func (s *Scheduler) GetConfigs() ([]*asynq.PeriodicTaskConfig, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	schedules, err := s.repo.ListSchedules(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	configs := make([]*asynq.PeriodicTaskConfig, 0, len(schedules))
+	for _, sc := range schedules {
+		sched, err := ParseSpec(sc.CronSpec, sc.Timezone)
+		if err != nil {
+			logger.L.Warn().Err(err).Str("schedule_id", sc.ScheduleID).Msg("跳过无效的周期任务")
+			continue
+		}
+
+		payload, _ := json.Marshal(triggerPayload{ScheduleID: sc.ScheduleID, UpdatedAt: sc.UpdatedAt.Unix()})
+		configs = append(configs, &asynq.PeriodicTaskConfig{
+			Cronspec: withTimezone(sc.CronSpec, sc.Timezone),
+			Task:     asynq.NewTask(TriggerTaskType, payload),
+			Opts: []asynq.Option{
+				asynq.Queue(TriggerQueue),
+				asynq.MaxRetry(0),
+				asynq.Unique(MinGap(sched, time.Now())),
+			},
+		})
+	}
+	return configs, nil
+}
+
+// handleTrigger 处理一次触发
+func (s *Scheduler) handleTrigger(ctx context.Context, t *asynq.Task) error {
+	var p triggerPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("decode trigger payload: %v: %w", err, asynq.SkipRetry)
+	}
+
+	sc, err := s.repo.GetSchedule(ctx, p.ScheduleID)
+	if err != nil {
+		// 周期任务已被删除，等待下一次同步注销
+		return nil
+	}
+	if !sc.Enabled {
+		return nil
+	}
+	sched, err := ParseSpec(sc.CronSpec, sc.Timezone)
+	if err != nil {
+		return nil
+	}
+
+	now := time.Now()
+	claimed, err := s.repo.ClaimRun(ctx, sc.ScheduleID, now, MinGap(sched, now))
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+
+	_, err = Fire(ctx, s.repo, s.firer, *sc, now)
+	return err
+}
+
+// Fire 创建一次任务并记录触发结果（定时触发与手动触发共用）
+func Fire(ctx context.Context, repo repository.ScheduleRepository, firer Firer, sc repository.Schedule, firedAt time.Time) (repository.ScheduleRun, error) {
+	run := repository.ScheduleRun{
+		ScheduleID: sc.ScheduleID,
+		FiredAt:    firedAt,
+		Status:     RunStatusEnqueued,
+	}
+
+	taskID, err := firer.FireSchedule(ctx, sc)
+	if err != nil {
+		run.Status = RunStatusFailed
+		run.Error = err.Error()
+		logger.L.Error().Err(err).Str("schedule_id", sc.ScheduleID).Msg("周期任务触发失败")
+	}
+	run.TaskID = taskID
+
+	var nextRunAt *time.Time
+	if next, err := NextRun(sc.CronSpec, sc.Timezone, firedAt); err == nil {
+		nextRunAt = &next
+	}
+	if err := repo.RecordRun(ctx, run, nextRunAt); err != nil {
+		return run, err
+	}
+	return run, nil
+}
+
+// ParseSpec 解析 cron 表达式（标准 5 段或 @every/@daily 等描述符），timezone 为空时使用 UTC
+func ParseSpec(spec, timezone string) (cron.Schedule, error) {
+	if _, err := loadLocation(timezone); err != nil {
+		return nil, err
+	}
+	return cron.ParseStandard(withTimezone(spec, timezone))
+}
+
+// NextRun 计算 from 之后的下一次触发时间
+func NextRun(spec, timezone string, from time.Time) (time.Time, error) {
+	sched, err := ParseSpec(spec, timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return sched.Next(from), nil
+}
+
+// MinGap 返回相邻两次触发间隔的一半（至少 1 秒），用于多副本触发去重
+func MinGap(sched cron.Schedule, from time.Time) time.Duration {
+	first := sched.Next(from)
+	gap := sched.Next(first).Sub(first) / 2
+	if gap < time.Second {
+		gap = time.Second
+	}
+	return gap
+}
+
+// withTimezone 为 cron 表达式加上 CRON_TZ 前缀
+func withTimezone(spec, timezone string) string {
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	return "CRON_TZ=" + timezone + " " + spec
+}
+
+// loadLocation 校验时区名称
+func loadLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("无效的时区 %q: %w", timezone, err)
+	}
+	return loc, nil
+}
@@ -0,0 +1,42 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextRunTimezone(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	next, err := NextRun("0 2 * * *", "Asia/Shanghai", from)
+	require.NoError(t, err)
+	// 上海 02:00 即 UTC 前一日 18:00
+	assert.Equal(t, time.Date(2026, 1, 1, 18, 0, 0, 0, time.UTC), next.UTC())
+
+	next, err = NextRun("@every 30s", "", from)
+	require.NoError(t, err)
+	assert.Equal(t, from.Add(30*time.Second), next.UTC())
+}
+
+func TestParseSpecInvalid(t *testing.T) {
+	_, err := ParseSpec("not a cron", "")
+	assert.Error(t, err)
+
+	_, err = ParseSpec("* * * * *", "Mars/Olympus")
+	assert.Error(t, err)
+}
+
+func TestMinGap(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	sched, err := ParseSpec("*/10 * * * *", "")
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, MinGap(sched, from))
+
+	sched, err = ParseSpec("@every 1s", "")
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, MinGap(sched, from))
+}
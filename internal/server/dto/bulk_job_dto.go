@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// BulkTaskFilter 批量操作的任务过滤条件（与任务列表的查询参数相同，另支持 task_ids），至少需要一个条件
+type BulkTaskFilter struct {
+	TaskIDs       []string          `json:"task_ids"` // 只匹配这些 task_id
+	WorkerName    string            `json:"worker_name" example:"my-worker"`
+	Status        string            `json:"status" example:"fail"`
+	Queue         string            `json:"queue" example:"my-worker:default:default"` // 完整队列名
+	Labels        map[string]string `json:"labels"`                                    // 标签选择器：需包含全部键值对
+	Priority      string            `json:"priority" example:"low"`                    // critical/default/low
+	CreatedAfter  *time.Time        `json:"created_after"`                             // 创建时间下限（含）
+	CreatedBefore *time.Time        `json:"created_before"`                            // 创建时间上限（不含）
+	UpdatedAfter  *time.Time        `json:"updated_after"`                             // 更新时间下限（含）
+	UpdatedBefore *time.Time        `json:"updated_before"`                            // 更新时间上限（不含）
+	Error         string            `json:"error" example:"timeout"`                   // last_error 子串（不区分大小写）
+	Payload       json.RawMessage   `json:"payload"`                                   // payload 需包含的 JSON 对象
+	PayloadPath   string            `json:"payload_path" example:"$.amount > 100"`     // payload 需满足的 jsonpath 谓词（只支持谓词表达式，语法错误返回 400）
+}
+
+// CreateBulkJobRequest 创建批量操作请求
+type CreateBulkJobRequest struct {
+	Operation string         `json:"operation" binding:"required" example:"retry"` // retry/cancel/delete/change_priority
+	Filter    BulkTaskFilter `json:"filter"`
+	DryRun    bool           `json:"dry_run"`                     // 只统计会被操作与被跳过的任务，不实际执行
+	Priority  string         `json:"priority" example:"critical"` // change_priority 的目标优先级
+	Reason    string         `json:"reason" example:"bad deploy"` // cancel 的取消原因
+}
+
+// BulkJobResponse 批量操作详情响应
+type BulkJobResponse struct {
+	Item interface{} `json:"item"`
+}
+
+// BulkJobListResponse 批量操作列表响应
+type BulkJobListResponse struct {
+	Items interface{} `json:"items"`
+}
+
+// BulkJobItemListResponse 批量操作明细响应（被跳过或处理失败的任务）
+type BulkJobItemListResponse struct {
+	Items interface{} `json:"items"`
+}
@@ -1,5 +1,10 @@
 package dto
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // QueueStatsRequest 队列统计查询请求
 type QueueStatsRequest struct {
 	WorkerName string `form:"worker_name" binding:"required" example:"my-worker"`
@@ -38,3 +43,30 @@ type ClearDeadQueueResponse struct {
 	ClearedQueues []string `json:"cleared_queues"`
 	TotalDeleted  int      `json:"total_deleted" example:"50"`
 }
+
+// QueueTaskItem Redis 队列中的一个任务
+type QueueTaskItem struct {
+	AsynqTaskID   string          `json:"asynq_task_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TaskID        string          `json:"task_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"` // 从任务信封解析，非本系统投递的任务为空
+	State         string          `json:"state" example:"retry"`
+	Payload       json.RawMessage `json:"payload,omitempty"`     // 信封中的业务 payload
+	RawPayload    string          `json:"raw_payload,omitempty"` // 无法按任务信封解析时的原始 payload
+	Retried       int             `json:"retried" example:"2"`
+	MaxRetry      int             `json:"max_retry" example:"3"`
+	LastError     string          `json:"last_error,omitempty" example:"timeout"`
+	LastFailedAt  *time.Time      `json:"last_failed_at,omitempty"`
+	NextProcessAt *time.Time      `json:"next_process_at,omitempty"` // scheduled/retry 任务的下次执行时间
+	CompletedAt   *time.Time      `json:"completed_at,omitempty"`
+	IsOrphaned    bool            `json:"is_orphaned,omitempty"` // active 任务的 worker 已失联
+	Task          interface{}     `json:"task,omitempty"`        // Postgres 中对应的任务记录，不存在时为空
+}
+
+// QueueTaskListResponse 队列任务列表响应
+type QueueTaskListResponse struct {
+	Queue    string          `json:"queue" example:"my-worker:default:default"`
+	State    string          `json:"state" example:"retry"`
+	Total    int             `json:"total" example:"42"` // 该状态下的任务总数
+	Page     int             `json:"page" example:"1"`
+	PageSize int             `json:"page_size" example:"20"`
+	Items    []QueueTaskItem `json:"items"`
+}
@@ -0,0 +1,31 @@
+package dto
+
+import "encoding/json"
+
+// ScheduleRequest 创建/更新周期任务请求
+type ScheduleRequest struct {
+	Name       string          `json:"name" binding:"required" example:"nightly-report"`
+	CronSpec   string          `json:"cron_spec" binding:"required" example:"0 2 * * *"`       // 标准 5 段 cron 或 @every/@daily 等
+	Timezone   string          `json:"timezone" example:"Asia/Shanghai"`                       // IANA 时区，默认 UTC
+	WorkerName string          `json:"worker_name" binding:"required" example:"report_worker"` // Worker 名称
+	Queue      string          `json:"queue" binding:"required" example:"default"`             // 队列组名称
+	Priority   string          `json:"priority" example:"default"`                             // critical/default/low
+	Payload    json.RawMessage `json:"payload" binding:"required"`                             // 每次触发创建的任务 payload
+	Enabled    *bool           `json:"enabled"`                                                // 是否启用，默认 true
+}
+
+// ScheduleResponse 周期任务详情响应
+type ScheduleResponse struct {
+	Item interface{} `json:"item"`
+}
+
+// ScheduleListResponse 周期任务列表响应
+type ScheduleListResponse struct {
+	Items interface{} `json:"items"`
+	Total int         `json:"total"`
+}
+
+// ScheduleRunListResponse 触发历史响应
+type ScheduleRunListResponse struct {
+	Items interface{} `json:"items"`
+}
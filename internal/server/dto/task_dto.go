@@ -5,12 +5,81 @@ import (
 	"time"
 )
 
+// MaxBatchCreateItems 批量创建任务单次最多条数
+const MaxBatchCreateItems = 1000
+
 // CreateTaskRequest 创建任务请求
 type CreateTaskRequest struct {
+	WorkerName   string          `json:"worker_name" binding:"required" example:"my-worker"`
+	Queue        string          `json:"queue" binding:"required" example:"web_crawl"` // 队列组名称
+	Priority     string          `json:"priority" example:"default"`                   // 优先级：critical, default, low（默认 default）
+	TaskID       string          `json:"task_id"`                                      // 可选，默认生成
+	Payload      json.RawMessage `json:"payload" binding:"required"`
+	DelaySeconds int32           `json:"delay_seconds" example:"0"`
+	RunAt        *time.Time      `json:"run_at"`
+
+	// 任务级覆盖参数（不传则使用 worker 默认配置）
+	MaxRetry         *int32     `json:"max_retry" example:"3"`         // 最大重试次数，0 表示不重试
+	TimeoutSeconds   *int32     `json:"timeout_seconds" example:"60"`  // 单次执行超时（秒）
+	Deadline         *time.Time `json:"deadline"`                      // 执行截止时间，超过后不再执行
+	RetentionSeconds *int32     `json:"retention_seconds" example:"0"` // 成功后在 asynq 中保留的时长（秒）
+
+	// 过期时间：到期仍未开始执行的任务不再执行，标记为 expired（expires_at 与 ttl_seconds 二选一）
+	ExpiresAt  *time.Time `json:"expires_at"`
+	TTLSeconds *int32     `json:"ttl_seconds" example:"3600"` // 从创建时起的存活时长（秒）
+
+	// 回调任务：本任务成功 / 最终失败（dead/expired）后自动入队
+	OnSuccess *CallbackTaskRequest `json:"on_success"`
+	OnFailure *CallbackTaskRequest `json:"on_failure"`
+
+	// 任务标签（如 tenant、source、batch_id），可在列表、统计、批量重试中按标签过滤
+	Labels map[string]string `json:"labels"`
+}
+
+// CallbackTaskRequest 回调任务定义。
+// payload 为模板，支持在字符串中使用占位符：{{parent_task_id}}、{{parent_status}}、{{parent_error}}
+type CallbackTaskRequest struct {
 	WorkerName string          `json:"worker_name" binding:"required" example:"my-worker"`
-	Queue      string          `json:"queue" binding:"required" example:"web_crawl"` // 队列组名称
-	Priority   string          `json:"priority" example:"default"`                   // 优先级：critical, default, low
-	Payload    json.RawMessage `json:"payload" binding:"required"`
+	Queue      string          `json:"queue" binding:"required" example:"notify"` // 队列组名称
+	Priority   string          `json:"priority" example:"default"`                // 优先级（默认 default）
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// BatchCreateTaskRequest 批量创建任务请求
+type BatchCreateTaskRequest struct {
+	Items []CreateTaskRequest `json:"items" binding:"required,min=1"`
+}
+
+// BatchCreateTaskResult 批量创建中单条任务的结果
+type BatchCreateTaskResult struct {
+	Index       int    `json:"index" example:"0"` // 对应请求 items 中的下标
+	TaskID      string `json:"task_id,omitempty"`
+	AsynqTaskID string `json:"asynq_task_id,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// BatchCreateTaskResponse 批量创建任务响应
+type BatchCreateTaskResponse struct {
+	Total     int                     `json:"total" example:"100"`
+	Succeeded int                     `json:"succeeded" example:"98"`
+	Failed    int                     `json:"failed" example:"2"`
+	Items     []BatchCreateTaskResult `json:"items"`
+}
+
+// 导入结果状态
+const (
+	ImportStatusCreated = "created" // 已入队并写入任务记录
+	ImportStatusSkipped = "skipped" // task_id 已存在（此前的导入已处理），未重复入队
+	ImportStatusFailed  = "failed"  // 校验或入队失败
+)
+
+// ImportTaskResult 导入中单行的处理结果（NDJSON 响应中的一行）
+type ImportTaskResult struct {
+	Line        int    `json:"line" example:"1"` // 对应请求体中的行号（从 1 开始）
+	TaskID      string `json:"task_id,omitempty"`
+	Status      string `json:"status" example:"created"` // created, skipped, failed
+	AsynqTaskID string `json:"asynq_task_id,omitempty"`
+	Error       string `json:"error,omitempty"`
 }
 
 // CreateTaskResponse 创建任务响应
@@ -20,7 +89,7 @@ type CreateTaskResponse struct {
 	Queue       string `json:"queue" example:"web_crawl"`
 	Priority    string `json:"priority" example:"default"`
 	AsynqTaskID string `json:"asynq_task_id"`
-	Status      string `json:"status" example:"pending"`
+	Status      string `json:"status" example:"pending"` // enqueued：已入队；accepted：出箱模式下已写入数据库，等待投递入队
 }
 
 // TaskListRequest 任务列表查询请求
@@ -34,8 +103,19 @@ type TaskListRequest struct {
 
 // TaskListResponse 任务列表响应
 type TaskListResponse struct {
-	Items interface{} `json:"items"`
-	Total int         `json:"total"`
+	Items          interface{} `json:"items"`
+	Total          *int        `json:"total,omitempty"`           // total=none 时不返回
+	TotalEstimated bool        `json:"total_estimated,omitempty"` // total=estimate 时为 true（按查询计划估算）
+	NextCursor     string      `json:"next_cursor,omitempty"`     // 为空表示没有下一页
+	PrevCursor     string      `json:"prev_cursor,omitempty"`     // 为空表示没有上一页
+}
+
+// AttemptListResponse 执行尝试列表响应
+type AttemptListResponse struct {
+	Items      interface{} `json:"items"`
+	Total      *int        `json:"total,omitempty"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+	PrevCursor string      `json:"prev_cursor,omitempty"`
 }
 
 // TaskResponse 任务详情响应
@@ -43,35 +123,95 @@ type TaskResponse struct {
 	Task interface{} `json:"task"`
 }
 
-// ReplayTaskRequest 重放任务请求
+// ReplayTaskRequest 重放任务请求（除 delay 外的字段不传时沿用原任务）
 type ReplayTaskRequest struct {
 	Delay int `json:"delay" example:"0"`
+
+	Payload  json.RawMessage `json:"payload"`                     // 修改后的 payload
+	Priority string          `json:"priority" example:"critical"` // 优先级：critical, default, low
+	Queue    string          `json:"queue" example:"web_crawl"`   // 队列组名称（同一 worker 内）
+
+	// 任务级覆盖参数
+	MaxRetry       *int32 `json:"max_retry" example:"3"`
+	TimeoutSeconds *int32 `json:"timeout_seconds" example:"60"`
 }
 
 // ReplayTaskResponse 重放任务响应
 type ReplayTaskResponse struct {
-	Status    string `json:"status" example:"ok"`
-	NewTaskID string `json:"new_task_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Status       string `json:"status" example:"ok"`
+	NewTaskID    string `json:"new_task_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ReplayedFrom string `json:"replayed_from" example:"0b8c6d3e-7f2a-4c1b-9e5d-2a6f8b4c1d3e"`
+	Queue        string `json:"queue" example:"my-worker:web_crawl:critical"` // 新任务的完整队列名
+}
+
+// CancelTaskRequest 取消任务请求
+type CancelTaskRequest struct {
+	Reason string `json:"reason" example:"误提交"`
+}
+
+// CancelTaskResponse 取消任务响应
+type CancelTaskResponse struct {
+	Status     string `json:"status" example:"canceled"`
+	TaskID     string `json:"task_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	RedisState string `json:"redis_state" example:"pending"` // 取消前任务在 asynq 中的状态（not_found 表示已不存在）
 }
 
 // ReportAttemptRequest 上报任务执行请求
 type ReportAttemptRequest struct {
-	Attempt    int             `json:"attempt" binding:"required" example:"1"`
-	Status     string          `json:"status" binding:"required" example:"success"`
-	StartedAt  time.Time       `json:"started_at" binding:"required"`
-	FinishedAt *time.Time      `json:"finished_at"`
-	Error      string          `json:"error"`
-	TraceID    string          `json:"trace_id" example:"trace-123"`
-	SpanID     string          `json:"span_id" example:"span-456"`
-	Payload    json.RawMessage `json:"payload"`
+	Attempt     int             `json:"attempt" binding:"required" example:"1"`
+	Status      string          `json:"status" binding:"required" example:"success"` // running/success/fail/dead/expired
+	AsynqTaskID string          `json:"asynq_task_id" example:"task-123"`
+	WorkerName  string          `json:"worker_name" example:"my-worker"` // 执行的 worker（用于失联检测按心跳判断）
+	StartedAt   time.Time       `json:"started_at" binding:"required"`
+	FinishedAt  *time.Time      `json:"finished_at"`
+	Error       string          `json:"error"`
+	TraceID     string          `json:"trace_id" example:"trace-123"`
+	SpanID      string          `json:"span_id" example:"span-456"`
+	Payload     json.RawMessage `json:"payload"`
+	Result      json.RawMessage `json:"result"` // 任务结果（仅 success 时保存）
+}
+
+// ReportProgressRequest 上报任务执行进度请求
+type ReportProgressRequest struct {
+	Attempt int    `json:"attempt" binding:"required,min=1" example:"1"`
+	Percent int    `json:"percent" binding:"min=0,max=100" example:"42"`
+	Message string `json:"message" binding:"max=1024" example:"已导入 4200/10000 行"`
+}
+
+// TaskLogLine 单条任务日志
+type TaskLogLine struct {
+	Level   string    `json:"level" binding:"required,oneof=debug info warn error" example:"info"`
+	Message string    `json:"message" binding:"required,max=8192" example:"已导入 4200/10000 行"`
+	Time    time.Time `json:"time" binding:"required"`
+}
+
+// AppendTaskLogsRequest 上报任务日志请求
+type AppendTaskLogsRequest struct {
+	Attempt int           `json:"attempt" binding:"required,min=1" example:"1"`
+	Lines   []TaskLogLine `json:"lines" binding:"required,min=1,max=500,dive"`
+}
+
+// TaskLogListResponse 任务日志响应
+type TaskLogListResponse struct {
+	Items interface{} `json:"items"`
+}
+
+// TaskResultResponse 任务结果响应
+type TaskResultResponse struct {
+	TaskID string          `json:"task_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Status string          `json:"status" example:"success"`
+	Ready  bool            `json:"ready" example:"true"` // 任务已结束（success/dead/canceled/skipped/expired/deleted/lost）
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
 }
 
 // BatchRetryRequest 批量重试请求
 type BatchRetryRequest struct {
-	WorkerName string   `json:"worker_name" example:"my-worker"`
-	Status     string   `json:"status" example:"fail"`
-	TaskIDs    []string `json:"task_ids"`
-	Limit      int      `json:"limit" example:"100"`
+	WorkerName string            `json:"worker_name" example:"my-worker"`
+	Status     string            `json:"status" example:"fail"`
+	Labels     map[string]string `json:"labels"` // 标签选择器：需包含全部键值对
+	TaskIDs    []string          `json:"task_ids"`
+	Limit      int               `json:"limit" example:"100"`
 }
 
 // BatchRetryResponse 批量重试响应
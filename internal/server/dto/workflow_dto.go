@@ -0,0 +1,29 @@
+package dto
+
+// MaxWorkflowTasks 单个工作流最多任务数
+const MaxWorkflowTasks = 1000
+
+// WorkflowTaskRequest 工作流中的单个任务：创建任务参数 + 依赖的上游任务
+type WorkflowTaskRequest struct {
+	CreateTaskRequest
+	DependsOn []string `json:"depends_on"` // 上游任务的 task_id（需在同一工作流内）
+}
+
+// CreateWorkflowRequest 创建工作流请求
+type CreateWorkflowRequest struct {
+	Name  string                `json:"name" binding:"required" example:"crawl-analyze-export"`
+	Tasks []WorkflowTaskRequest `json:"tasks" binding:"required,min=1"`
+}
+
+// WorkflowResponse 工作流详情响应
+type WorkflowResponse struct {
+	Item     interface{}    `json:"item"`
+	Tasks    interface{}    `json:"tasks"`
+	Progress map[string]int `json:"progress"` // 各状态的任务数
+}
+
+// WorkflowListResponse 工作流列表响应
+type WorkflowListResponse struct {
+	Items interface{} `json:"items"`
+	Total int         `json:"total"`
+}
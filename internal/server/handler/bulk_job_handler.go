@@ -0,0 +1,291 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/azhengyongqin/asynq-hub/internal/middleware"
+	"github.com/azhengyongqin/asynq-hub/internal/model"
+	asynqx "github.com/azhengyongqin/asynq-hub/internal/queue"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+	"github.com/azhengyongqin/asynq-hub/internal/server/dto"
+	workers "github.com/azhengyongqin/asynq-hub/internal/worker"
+)
+
+// BulkJobHandler 异步批量操作相关 API Handler
+// 创建后由 bulkjob.Runner 在后台分批执行，每个任务的操作由 TaskHandler.ApplyBulkOperation 完成
+type BulkJobHandler struct {
+	bulkJobRepo repository.BulkJobRepository
+	tasks       *TaskHandler
+}
+
+// NewBulkJobHandler 创建 BulkJobHandler
+func NewBulkJobHandler(bulkJobRepo repository.BulkJobRepository, tasks *TaskHandler) *BulkJobHandler {
+	return &BulkJobHandler{bulkJobRepo: bulkJobRepo, tasks: tasks}
+}
+
+// CreateBulkJob godoc
+// @Summary 创建批量操作
+// @Description 按过滤条件对任务批量执行 retry（重放已结束的任务）、cancel（取消未结束的任务）、delete（删除任务及其执行历史）或 change_priority（将尚未执行的任务移动到其他优先级队列）。
+// @Description 操作在后台按 task.id 分批执行，只处理开始执行时已存在的任务；返回的 job_id 用于查询进度、明细或取消。dry_run 为 true 时只统计会被操作与被跳过的任务。
+// @Tags BulkJobs
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateBulkJobRequest true "批量操作"
+// @Success 202 {object} dto.BulkJobResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 501 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Router /bulk-jobs [post]
+func (h *BulkJobHandler) CreateBulkJob(c *gin.Context) {
+	if h.bulkJobRepo == nil || h.tasks.taskRepo == nil {
+		c.JSON(http.StatusNotImplemented, dto.ErrorResponse{Error: "Postgres 未配置"})
+		return
+	}
+
+	var req dto.CreateBulkJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	job, err := buildBulkJob(c.Request.Context(), req, h.tasks.taskRepo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if !job.DryRun {
+		if err := h.tasks.checkBulkOperation(model.BulkOperation(job.Operation)); err != nil {
+			c.JSON(http.StatusServiceUnavailable, dto.ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+	job.JobID = asynqx.NewTaskID()
+
+	ctx := c.Request.Context()
+	if err := h.bulkJobRepo.CreateBulkJob(ctx, job); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	created, err := h.bulkJobRepo.GetBulkJob(ctx, job.JobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, dto.BulkJobResponse{Item: created})
+}
+
+// ListBulkJobs godoc
+// @Summary 查询批量操作列表
+// @Description 按创建时间倒序返回批量操作及其进度
+// @Tags BulkJobs
+// @Produce json
+// @Param status query string false "状态：pending, running, completed, failed, canceled"
+// @Param limit query int false "返回数量（最多 200）" default(50)
+// @Success 200 {object} dto.BulkJobListResponse
+// @Failure 501 {object} dto.ErrorResponse
+// @Router /bulk-jobs [get]
+func (h *BulkJobHandler) ListBulkJobs(c *gin.Context) {
+	if h.bulkJobRepo == nil {
+		c.JSON(http.StatusNotImplemented, dto.ErrorResponse{Error: "Postgres 未配置"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	items, err := h.bulkJobRepo.ListBulkJobs(c.Request.Context(), c.Query("status"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.BulkJobListResponse{Items: items})
+}
+
+// GetBulkJob godoc
+// @Summary 获取批量操作进度
+// @Description 返回批量操作的状态与进度（total 为开始执行时匹配的任务数，processed = succeeded + skipped + failed）
+// @Tags BulkJobs
+// @Produce json
+// @Param job_id path string true "批量操作 ID"
+// @Success 200 {object} dto.BulkJobResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 501 {object} dto.ErrorResponse
+// @Router /bulk-jobs/{job_id} [get]
+func (h *BulkJobHandler) GetBulkJob(c *gin.Context) {
+	if h.bulkJobRepo == nil {
+		c.JSON(http.StatusNotImplemented, dto.ErrorResponse{Error: "Postgres 未配置"})
+		return
+	}
+	h.respondBulkJob(c, http.StatusOK)
+}
+
+// ListBulkJobItems godoc
+// @Summary 查询批量操作明细
+// @Description 按处理顺序返回被跳过或处理失败的任务及原因，通过 after_id 翻页
+// @Tags BulkJobs
+// @Produce json
+// @Param job_id path string true "批量操作 ID"
+// @Param after_id query int false "只返回 id 大于该值的明细" default(0)
+// @Param limit query int false "返回数量（最多 1000）" default(100)
+// @Success 200 {object} dto.BulkJobItemListResponse
+// @Failure 501 {object} dto.ErrorResponse
+// @Router /bulk-jobs/{job_id}/items [get]
+func (h *BulkJobHandler) ListBulkJobItems(c *gin.Context) {
+	if h.bulkJobRepo == nil {
+		c.JSON(http.StatusNotImplemented, dto.ErrorResponse{Error: "Postgres 未配置"})
+		return
+	}
+
+	afterID, _ := strconv.ParseInt(c.DefaultQuery("after_id", "0"), 10, 64)
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+	items, err := h.bulkJobRepo.ListBulkJobItems(c.Request.Context(), c.Param("job_id"), afterID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.BulkJobItemListResponse{Items: items})
+}
+
+// CancelBulkJob godoc
+// @Summary 取消批量操作
+// @Description 取消等待执行或执行中的批量操作：执行中的批次结束后停止，已处理的任务不回滚
+// @Tags BulkJobs
+// @Produce json
+// @Param job_id path string true "批量操作 ID"
+// @Success 200 {object} dto.BulkJobResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 501 {object} dto.ErrorResponse
+// @Router /bulk-jobs/{job_id} [delete]
+func (h *BulkJobHandler) CancelBulkJob(c *gin.Context) {
+	if h.bulkJobRepo == nil {
+		c.JSON(http.StatusNotImplemented, dto.ErrorResponse{Error: "Postgres 未配置"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	canceled, err := h.bulkJobRepo.CancelBulkJob(ctx, c.Param("job_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if !canceled {
+		job, err := h.bulkJobRepo.GetBulkJob(ctx, c.Param("job_id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "批量操作不存在"})
+			return
+		}
+		c.JSON(http.StatusConflict, dto.ErrorResponse{Error: fmt.Sprintf("批量操作已结束（%s），无法取消", job.Status)})
+		return
+	}
+	h.respondBulkJob(c, http.StatusOK)
+}
+
+// respondBulkJob 返回批量操作详情
+func (h *BulkJobHandler) respondBulkJob(c *gin.Context, status int) {
+	job, err := h.bulkJobRepo.GetBulkJob(c.Request.Context(), c.Param("job_id"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "批量操作不存在"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(status, dto.BulkJobResponse{Item: job})
+}
+
+// buildBulkJob 校验请求并转换为批量操作任务
+func buildBulkJob(ctx context.Context, req dto.CreateBulkJobRequest, repo repository.TaskRepository) (repository.BulkJob, error) {
+	op := model.BulkOperation(req.Operation)
+	if !op.Valid() {
+		return repository.BulkJob{}, errors.New("operation 必须是 retry, cancel, delete 或 change_priority")
+	}
+	if op == model.BulkOperationChangePriority && !validPriority(req.Priority) {
+		return repository.BulkJob{}, errors.New("change_priority 需要指定 priority：critical, default 或 low")
+	}
+
+	filter, err := parseBulkTaskFilter(ctx, req.Filter, repo)
+	if err != nil {
+		return repository.BulkJob{}, err
+	}
+
+	job := repository.BulkJob{
+		Operation: req.Operation,
+		Filter:    filter,
+		DryRun:    req.DryRun,
+	}
+	switch op {
+	case model.BulkOperationChangePriority:
+		job.Priority = req.Priority
+	case model.BulkOperationCancel:
+		job.Reason = req.Reason
+	}
+	return job, nil
+}
+
+// parseBulkTaskFilter 校验批量操作的过滤条件并转换为任务查询条件，空条件视为误操作；payload_path 由 repo 校验语法
+func parseBulkTaskFilter(ctx context.Context, f dto.BulkTaskFilter, repo repository.TaskRepository) (repository.ListTasksFilter, error) {
+	filter := repository.ListTasksFilter{
+		TaskIDs:       f.TaskIDs,
+		WorkerName:    f.WorkerName,
+		Status:        f.Status,
+		Queue:         f.Queue,
+		Labels:        f.Labels,
+		CreatedAfter:  f.CreatedAfter,
+		CreatedBefore: f.CreatedBefore,
+		UpdatedAfter:  f.UpdatedAfter,
+		UpdatedBefore: f.UpdatedBefore,
+		ErrorContains: f.Error,
+		PayloadPath:   f.PayloadPath,
+	}
+
+	if f.Status != "" && !model.TaskStatus(f.Status).Valid() {
+		return filter, fmt.Errorf("status 无效: %s", f.Status)
+	}
+	if err := middleware.ValidateLabels(f.Labels); err != nil {
+		return filter, err
+	}
+	if f.Priority != "" {
+		if !validPriority(f.Priority) {
+			return filter, errors.New("filter.priority 必须是 critical, default 或 low")
+		}
+		filter.Priority = priorityToInt(f.Priority)
+	}
+	if len(f.Payload) > 0 && string(f.Payload) != "null" {
+		if !json.Valid(f.Payload) || !strings.HasPrefix(strings.TrimSpace(string(f.Payload)), "{") {
+			return filter, errors.New("filter.payload 必须是 JSON 对象")
+		}
+		filter.PayloadContains = f.Payload
+	}
+	if err := validatePayloadPath(ctx, repo, f.PayloadPath); err != nil {
+		return filter, err
+	}
+
+	empty := len(filter.TaskIDs) == 0 && filter.WorkerName == "" && filter.Status == "" && filter.Queue == "" &&
+		len(filter.Labels) == 0 && filter.Priority == 0 &&
+		filter.CreatedAfter == nil && filter.CreatedBefore == nil && filter.UpdatedAfter == nil && filter.UpdatedBefore == nil &&
+		filter.ErrorContains == "" && len(filter.PayloadContains) == 0 && filter.PayloadPath == ""
+	if empty {
+		return filter, errors.New("filter 至少需要一个条件")
+	}
+	return filter, nil
+}
+
+// validPriority 是否为有效的优先级名称
+func validPriority(p string) bool {
+	return p == workers.PriorityCritical || p == workers.PriorityDefault || p == workers.PriorityLow
+}
@@ -0,0 +1,349 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+
+	"github.com/azhengyongqin/asynq-hub/internal/model"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+	workers "github.com/azhengyongqin/asynq-hub/internal/worker"
+)
+
+// fakeTaskRepo 内存任务仓储，实现 handler 测试用到的方法（其余方法调用时因嵌入的 nil 接口而 panic）
+type fakeTaskRepo struct {
+	repository.TaskRepository
+
+	mu     sync.Mutex
+	tasks  map[string]*repository.Task
+	outbox []string // 写入出箱记录的 task_id
+
+	upsertErr    error           // 不为空时 UpsertTasks 失败
+	invalidPaths map[string]bool // ValidateJSONPath 视为语法错误的表达式
+}
+
+func (r *fakeTaskRepo) GetTask(_ context.Context, taskID string) (*repository.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tasks[taskID]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	cp := *t
+	return &cp, nil
+}
+
+func (r *fakeTaskRepo) status(taskID string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.tasks[taskID].Status
+}
+
+func (r *fakeTaskRepo) ListTasks(_ context.Context, f repository.ListTasksFilter) ([]repository.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []repository.Task
+	for _, id := range f.TaskIDs {
+		if t, ok := r.tasks[id]; ok {
+			out = append(out, *t)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeTaskRepo) ValidateJSONPath(_ context.Context, path string) error {
+	if r.invalidPaths[path] {
+		return fmt.Errorf("%w: syntax error at end of jsonpath input", repository.ErrInvalidJSONPath)
+	}
+	return nil
+}
+
+func (r *fakeTaskRepo) ExistingTaskIDs(_ context.Context, taskIDs []string) (map[string]struct{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing := make(map[string]struct{})
+	for _, id := range taskIDs {
+		if _, ok := r.tasks[id]; ok {
+			existing[id] = struct{}{}
+		}
+	}
+	return existing, nil
+}
+
+func (r *fakeTaskRepo) UpsertTask(_ context.Context, t repository.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tasks[t.TaskID] = &t
+	return nil
+}
+
+func (r *fakeTaskRepo) UpsertTasks(_ context.Context, tasks []repository.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.upsertErr != nil {
+		return r.upsertErr
+	}
+	for i := range tasks {
+		t := tasks[i]
+		r.tasks[t.TaskID] = &t
+	}
+	return nil
+}
+
+func (r *fakeTaskRepo) CreateTaskWithOutbox(_ context.Context, t repository.Task, _ *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tasks[t.TaskID]; ok {
+		return repository.ErrTaskExists
+	}
+	r.tasks[t.TaskID] = &t
+	r.outbox = append(r.outbox, t.TaskID)
+	return nil
+}
+
+func (r *fakeTaskRepo) CreateTasksWithOutbox(_ context.Context, tasks []repository.OutboxTask) (map[string]struct{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing := make(map[string]struct{})
+	for _, ot := range tasks {
+		t := ot.Task
+		if _, ok := r.tasks[t.TaskID]; ok {
+			existing[t.TaskID] = struct{}{}
+			continue
+		}
+		r.tasks[t.TaskID] = &t
+		r.outbox = append(r.outbox, t.TaskID)
+	}
+	return existing, nil
+}
+
+func (r *fakeTaskRepo) TransitionTaskStatus(_ context.Context, taskID, from, to, asynqTaskID, lastError string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tasks[taskID]
+	if !ok || t.Status != from {
+		return false, nil
+	}
+	t.Status = to
+	t.LastError = lastError
+	if asynqTaskID != "" {
+		t.AsynqTaskID = asynqTaskID
+	}
+	return true, nil
+}
+
+func (r *fakeTaskRepo) TransitionTaskWithOutbox(_ context.Context, taskID, from string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tasks[taskID]
+	if !ok || t.Status != from {
+		return false, nil
+	}
+	t.Status = string(model.TaskStatusPending)
+	t.AsynqTaskID = taskID
+	r.outbox = append(r.outbox, taskID)
+	return true, nil
+}
+
+func (r *fakeTaskRepo) MarkTaskRunning(_ context.Context, taskID string, attempt int, workerName string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tasks[taskID]
+	if !ok || (t.Status != "pending" && t.Status != "fail" && t.Status != "running") {
+		return false, nil
+	}
+	t.Status = string(model.TaskStatusRunning)
+	t.LastAttempt = attempt
+	t.LastWorkerName = workerName
+	return true, nil
+}
+
+func (r *fakeTaskRepo) ApplyAttemptStatus(_ context.Context, taskID, status string, attempt int, lastError string, result json.RawMessage) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tasks[taskID]
+	if !ok || model.TaskStatus(t.Status).IsTerminal() {
+		return false, nil
+	}
+	t.Status = status
+	t.LastAttempt = attempt
+	t.LastError = lastError
+	if len(result) > 0 {
+		t.Result = result
+	}
+	return true, nil
+}
+
+func (r *fakeTaskRepo) UpdateTaskStatus(_ context.Context, taskID, status string, attempt int, lastError, workerName string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tasks[taskID]
+	if !ok {
+		return errors.New("not found")
+	}
+	t.Status = status
+	t.LastAttempt = attempt
+	t.LastError = lastError
+	t.LastWorkerName = workerName
+	return nil
+}
+
+func (r *fakeTaskRepo) InsertAttempt(_ context.Context, _ repository.Attempt) error {
+	return nil
+}
+
+// fakeWorkflowRepo 基于 fakeTaskRepo 的工作流仓储；snapshot 不为空时 ListWorkflowTasks 返回该快照（模拟读到旧数据）
+type fakeWorkflowRepo struct {
+	repository.WorkflowRepository
+
+	tasks    *fakeTaskRepo
+	snapshot []repository.Task
+	status   string
+}
+
+func (r *fakeWorkflowRepo) ListWorkflowTasks(_ context.Context, _ string) ([]repository.Task, error) {
+	if r.snapshot != nil {
+		return r.snapshot, nil
+	}
+	r.tasks.mu.Lock()
+	defer r.tasks.mu.Unlock()
+	out := make([]repository.Task, 0, len(r.tasks.tasks))
+	for _, t := range r.tasks.tasks {
+		out = append(out, *t)
+	}
+	return out, nil
+}
+
+func (r *fakeWorkflowRepo) UpdateWorkflowStatus(_ context.Context, _ string, status string) error {
+	r.status = status
+	return nil
+}
+
+// fakeEnqueuer 记录入队的任务，err 不为空时入队失败
+type fakeEnqueuer struct {
+	mu       sync.Mutex
+	enqueued []string
+	err      error
+}
+
+func (e *fakeEnqueuer) Enqueue(task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	id := fmt.Sprintf("%s#%d", task.Type(), len(e.enqueued))
+	e.enqueued = append(e.enqueued, id)
+	return &asynq.TaskInfo{ID: id}, nil
+}
+
+func (e *fakeEnqueuer) count() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.enqueued)
+}
+
+// conflictEnqueuer 对 conflicts 中的 task_id 返回 ErrTaskIDConflict（模拟已入队但未落库）
+type conflictEnqueuer struct {
+	fakeEnqueuer
+	conflicts map[string]bool
+}
+
+func (e *conflictEnqueuer) Enqueue(task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	var msg taskMessage
+	if err := json.Unmarshal(task.Payload(), &msg); err == nil && e.conflicts[msg.TaskID] {
+		return nil, asynq.ErrTaskIDConflict
+	}
+	return e.fakeEnqueuer.Enqueue(task, opts...)
+}
+
+// fakeInspector 按 asynq 任务 ID 返回 infos 中的任务信息，记录删除与取消的任务
+type fakeInspector struct {
+	mu       sync.Mutex
+	infos    map[string]*asynq.TaskInfo
+	deleted  []string
+	canceled []string
+}
+
+func (i *fakeInspector) GetTaskInfo(_, id string) (*asynq.TaskInfo, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	info, ok := i.infos[id]
+	if !ok {
+		return nil, asynq.ErrTaskNotFound
+	}
+	return info, nil
+}
+
+func (i *fakeInspector) setState(id string, state asynq.TaskState) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.infos[id] = &asynq.TaskInfo{ID: id, State: state}
+}
+
+func (i *fakeInspector) DeleteTask(_, id string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.deleted = append(i.deleted, id)
+	delete(i.infos, id)
+	return nil
+}
+
+func (i *fakeInspector) CancelProcessing(id string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.canceled = append(i.canceled, id)
+	return nil
+}
+
+// testTask 属于 test-worker 默认队列组、default 优先级的独立任务
+func testTask(id, status string) repository.Task {
+	return repository.Task{
+		TaskID:     id,
+		WorkerName: "test-worker",
+		Queue:      "test-worker:default:default",
+		Payload:    []byte(`{}`),
+		Status:     status,
+	}
+}
+
+// workflowTask 属于工作流 workflow-1、依赖 deps 的任务
+func workflowTask(id, status string, deps ...string) repository.Task {
+	t := testTask(id, status)
+	t.WorkflowID = "workflow-1"
+	t.DependsOn = deps
+	return t
+}
+
+// newTestHandler 创建使用内存仓储与 fakeEnqueuer 的 TaskHandler，tasks 为仓储中的初始任务；
+// 已注册 test-worker（default 队列组，默认优先级）
+func newTestHandler(t *testing.T, tasks ...repository.Task) (*TaskHandler, *fakeTaskRepo, *fakeWorkflowRepo, *fakeEnqueuer) {
+	t.Helper()
+
+	store := workers.NewStore()
+	_, err := store.Upsert(workers.Config{
+		WorkerName:  "test-worker",
+		QueueGroups: []workers.QueueGroupConfig{{Name: "default", Concurrency: 1, Priorities: workers.DefaultPriorities}},
+		IsEnabled:   true,
+	})
+	require.NoError(t, err)
+
+	taskRepo := &fakeTaskRepo{tasks: make(map[string]*repository.Task, len(tasks))}
+	for i := range tasks {
+		task := tasks[i]
+		taskRepo.tasks[task.TaskID] = &task
+	}
+	wfRepo := &fakeWorkflowRepo{tasks: taskRepo}
+	enq := &fakeEnqueuer{}
+
+	h := NewTaskHandler(nil, nil, taskRepo, nil, wfRepo, store)
+	h.asynqClient = enq
+	return h, taskRepo, wfRepo, enq
+}
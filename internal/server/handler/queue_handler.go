@@ -1,11 +1,17 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hibiken/asynq"
 
+	"github.com/azhengyongqin/asynq-hub/internal/logger"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
 	"github.com/azhengyongqin/asynq-hub/internal/server/dto"
 	workers "github.com/azhengyongqin/asynq-hub/internal/worker"
 )
@@ -13,13 +19,17 @@ import (
 // QueueHandler Queue 相关 API Handler
 type QueueHandler struct {
 	asynqClient *asynq.Client
+	inspector   *asynq.Inspector
+	taskRepo    repository.TaskRepository
 	workerStore *workers.Store
 }
 
 // NewQueueHandler 创建 QueueHandler
-func NewQueueHandler(asynqClient *asynq.Client, workerStore *workers.Store) *QueueHandler {
+func NewQueueHandler(asynqClient *asynq.Client, inspector *asynq.Inspector, taskRepo repository.TaskRepository, workerStore *workers.Store) *QueueHandler {
 	return &QueueHandler{
 		asynqClient: asynqClient,
+		inspector:   inspector,
+		taskRepo:    taskRepo,
 		workerStore: workerStore,
 	}
 }
@@ -230,3 +240,170 @@ func (h *QueueHandler) ClearDeadQueue(c *gin.Context) {
 		TotalDeleted:  totalDeleted,
 	})
 }
+
+// ListQueueTasks godoc
+// @Summary 查询队列中的任务
+// @Description 分页列出 Redis 中指定完整队列、指定状态下的任务，按任务信封解析出 task_id 与 payload，并附带 Postgres 中对应的任务记录（存在时）
+// @Tags Queues
+// @Produce json
+// @Param full_queue path string true "完整队列名（worker:队列组:优先级）"
+// @Param state query string true "状态：pending, active, scheduled, retry, archived, completed"
+// @Param page query int false "页码（从 1 开始）" default(1)
+// @Param page_size query int false "每页数量（最多 100）" default(20)
+// @Success 200 {object} dto.QueueTaskListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Router /queues/{full_queue}/tasks [get]
+func (h *QueueHandler) ListQueueTasks(c *gin.Context) {
+	if h.inspector == nil {
+		c.JSON(http.StatusServiceUnavailable, dto.ErrorResponse{Error: "asynq inspector 未配置"})
+		return
+	}
+
+	queue := c.Param("full_queue")
+	if _, _, _, ok := workers.ParseFullQueueName(queue); !ok {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "full_queue 格式无效，应为 worker:队列组:优先级"})
+		return
+	}
+	state := c.Query("state")
+	list, ok := queueTaskListers[state]
+	if !ok {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "state 必须是 pending, active, scheduled, retry, archived 或 completed"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page <= 0 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	info, err := h.inspector.GetQueueInfo(queue)
+	if errors.Is(err, asynq.ErrQueueNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "队列不存在"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	infos, err := list(h.inspector, queue, asynq.Page(page), asynq.PageSize(pageSize))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	items := newQueueTaskItems(infos)
+	h.attachTaskRecords(c, items)
+
+	c.JSON(http.StatusOK, dto.QueueTaskListResponse{
+		Queue:    queue,
+		State:    state,
+		Total:    queueStateSize(info, state),
+		Page:     page,
+		PageSize: pageSize,
+		Items:    items,
+	})
+}
+
+// queueTaskListers 各状态对应的 Inspector 列表方法
+var queueTaskListers = map[string]func(*asynq.Inspector, string, ...asynq.ListOption) ([]*asynq.TaskInfo, error){
+	"pending":   (*asynq.Inspector).ListPendingTasks,
+	"active":    (*asynq.Inspector).ListActiveTasks,
+	"scheduled": (*asynq.Inspector).ListScheduledTasks,
+	"retry":     (*asynq.Inspector).ListRetryTasks,
+	"archived":  (*asynq.Inspector).ListArchivedTasks,
+	"completed": (*asynq.Inspector).ListCompletedTasks,
+}
+
+// queueStateSize 队列中指定状态的任务数
+func queueStateSize(info *asynq.QueueInfo, state string) int {
+	switch state {
+	case "pending":
+		return info.Pending
+	case "active":
+		return info.Active
+	case "scheduled":
+		return info.Scheduled
+	case "retry":
+		return info.Retry
+	case "archived":
+		return info.Archived
+	case "completed":
+		return info.Completed
+	}
+	return 0
+}
+
+// newQueueTaskItems 将 Redis 中的任务转换为响应项，按任务信封解析 task_id 与 payload
+func newQueueTaskItems(infos []*asynq.TaskInfo) []dto.QueueTaskItem {
+	items := make([]dto.QueueTaskItem, 0, len(infos))
+	for _, info := range infos {
+		item := dto.QueueTaskItem{
+			AsynqTaskID:   info.ID,
+			State:         info.State.String(),
+			Retried:       info.Retried,
+			MaxRetry:      info.MaxRetry,
+			LastError:     info.LastErr,
+			LastFailedAt:  nonZeroTime(info.LastFailedAt),
+			NextProcessAt: nonZeroTime(info.NextProcessAt),
+			CompletedAt:   nonZeroTime(info.CompletedAt),
+			IsOrphaned:    info.IsOrphaned,
+		}
+
+		var msg taskMessage
+		if err := json.Unmarshal(info.Payload, &msg); err == nil && (msg.TaskID != "" || msg.ID != "") {
+			item.TaskID = msg.TaskID
+			if item.TaskID == "" {
+				item.TaskID = msg.ID
+			}
+			item.Payload = msg.Payload
+		} else {
+			item.RawPayload = string(info.Payload)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// attachTaskRecords 为能解析出 task_id 的任务附带 Postgres 中的任务记录，查询失败时只返回 Redis 中的信息
+func (h *QueueHandler) attachTaskRecords(c *gin.Context, items []dto.QueueTaskItem) {
+	if h.taskRepo == nil {
+		return
+	}
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.TaskID != "" {
+			ids = append(ids, item.TaskID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	tasks, err := h.taskRepo.ListTasks(c.Request.Context(), repository.ListTasksFilter{TaskIDs: ids, Limit: len(ids)})
+	if err != nil {
+		logger.L.Warn().Err(err).Msg("查询队列任务对应的任务记录失败")
+		return
+	}
+	byID := make(map[string]repository.Task, len(tasks))
+	for _, t := range tasks {
+		byID[t.TaskID] = t
+	}
+	for i := range items {
+		if t, ok := byID[items[i].TaskID]; ok {
+			items[i].Task = t
+		}
+	}
+}
+
+// nonZeroTime 零值时间返回 nil
+func nonZeroTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
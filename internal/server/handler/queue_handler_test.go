@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+)
+
+func TestNewQueueTaskItems(t *testing.T) {
+	failedAt := time.Date(2026, 3, 14, 8, 0, 0, 0, time.UTC)
+	items := newQueueTaskItems([]*asynq.TaskInfo{
+		{
+			ID:            "a",
+			State:         asynq.TaskStateRetry,
+			Payload:       []byte(`{"id":"a","task_id":"a","payload":{"n":1}}`),
+			Retried:       2,
+			MaxRetry:      3,
+			LastErr:       "timeout",
+			LastFailedAt:  failedAt,
+			NextProcessAt: failedAt.Add(time.Minute),
+		},
+		{ID: "legacy", State: asynq.TaskStatePending, Payload: []byte(`{"id":"legacy","payload":"x"}`)},
+		{ID: "foreign", State: asynq.TaskStateArchived, Payload: []byte(`not json`)},
+	})
+	require.Len(t, items, 3)
+
+	assert.Equal(t, "a", items[0].TaskID)
+	assert.Equal(t, "retry", items[0].State)
+	assert.JSONEq(t, `{"n":1}`, string(items[0].Payload))
+	assert.Empty(t, items[0].RawPayload)
+	assert.Equal(t, 2, items[0].Retried)
+	assert.Equal(t, "timeout", items[0].LastError)
+	assert.Equal(t, failedAt, *items[0].LastFailedAt)
+	assert.Equal(t, failedAt.Add(time.Minute), *items[0].NextProcessAt)
+	assert.Nil(t, items[0].CompletedAt, "零值时间不返回")
+
+	assert.Equal(t, "legacy", items[1].TaskID, "旧信封只有 id")
+	assert.JSONEq(t, `"x"`, string(items[1].Payload))
+
+	assert.Empty(t, items[2].TaskID, "非本系统投递的任务")
+	assert.Equal(t, "not json", items[2].RawPayload)
+}
+
+func TestAttachTaskRecords(t *testing.T) {
+	repo := &fakeTaskRepo{tasks: map[string]*repository.Task{"a": {TaskID: "a", Status: "fail"}}}
+	h := NewQueueHandler(nil, nil, repo, nil)
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil)
+
+	items := newQueueTaskItems([]*asynq.TaskInfo{
+		{ID: "a", State: asynq.TaskStateRetry, Payload: []byte(`{"id":"a","task_id":"a","payload":{}}`)},
+		{ID: "b", State: asynq.TaskStateRetry, Payload: []byte(`{"id":"b","task_id":"b","payload":{}}`)},
+	})
+	h.attachTaskRecords(c, items)
+
+	require.NotNil(t, items[0].Task)
+	assert.Equal(t, "fail", items[0].Task.(repository.Task).Status)
+	assert.Nil(t, items[1].Task, "Postgres 中没有记录")
+}
+
+func TestListQueueTasks_Validation(t *testing.T) {
+	inspector := asynq.NewInspector(asynq.RedisClientOpt{Addr: "127.0.0.1:0"})
+	defer inspector.Close()
+	h := NewQueueHandler(nil, inspector, nil, nil)
+
+	for name, tc := range map[string]struct{ queue, state string }{
+		"队列名无效": {"my-worker", "retry"},
+		"缺少状态":  {"my-worker:default:default", ""},
+		"状态无效":  {"my-worker:default:default", "dead"},
+	} {
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/?state="+tc.state, nil)
+		c.Params = gin.Params{{Key: "full_queue", Value: tc.queue}}
+
+		h.ListQueueTasks(c)
+		assert.Equal(t, http.StatusBadRequest, w.Code, name)
+	}
+}
@@ -0,0 +1,294 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	asynqx "github.com/azhengyongqin/asynq-hub/internal/queue"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+	"github.com/azhengyongqin/asynq-hub/internal/scheduler"
+	"github.com/azhengyongqin/asynq-hub/internal/server/dto"
+)
+
+// ScheduleHandler 周期任务（cron）相关 API Handler
+// 每次触发通过 TaskHandler.createTask 创建任务，与 POST /tasks 走同一条路径
+type ScheduleHandler struct {
+	scheduleRepo repository.ScheduleRepository
+	tasks        *TaskHandler
+}
+
+// NewScheduleHandler 创建 ScheduleHandler
+func NewScheduleHandler(scheduleRepo repository.ScheduleRepository, tasks *TaskHandler) *ScheduleHandler {
+	return &ScheduleHandler{scheduleRepo: scheduleRepo, tasks: tasks}
+}
+
+// CreateSchedule godoc
+// @Summary 创建周期任务
+// @Description 按 cron 表达式（支持时区）定时创建任务，每次触发都会写入 task 表
+// @Tags Schedules
+// @Accept json
+// @Produce json
+// @Param request body dto.ScheduleRequest true "周期任务定义"
+// @Success 200 {object} dto.ScheduleResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 501 {object} dto.ErrorResponse
+// @Router /schedules [post]
+func (h *ScheduleHandler) CreateSchedule(c *gin.Context) {
+	if h.scheduleRepo == nil {
+		c.JSON(http.StatusNotImplemented, dto.ErrorResponse{Error: "Postgres 未配置"})
+		return
+	}
+
+	var req dto.ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	s, err := h.buildSchedule(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	s.ScheduleID = asynqx.NewTaskID()
+
+	ctx := c.Request.Context()
+	if err := h.scheduleRepo.CreateSchedule(ctx, s); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	h.respondSchedule(c, s.ScheduleID)
+}
+
+// ListSchedules godoc
+// @Summary 查询周期任务列表
+// @Description 返回全部周期任务，包含最近一次与下一次触发时间
+// @Tags Schedules
+// @Produce json
+// @Param enabled query bool false "只返回启用的周期任务"
+// @Success 200 {object} dto.ScheduleListResponse
+// @Failure 501 {object} dto.ErrorResponse
+// @Router /schedules [get]
+func (h *ScheduleHandler) ListSchedules(c *gin.Context) {
+	if h.scheduleRepo == nil {
+		c.JSON(http.StatusNotImplemented, dto.ErrorResponse{Error: "Postgres 未配置"})
+		return
+	}
+
+	enabledOnly, _ := strconv.ParseBool(c.DefaultQuery("enabled", "false"))
+	items, err := h.scheduleRepo.ListSchedules(c.Request.Context(), enabledOnly)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ScheduleListResponse{Items: items, Total: len(items)})
+}
+
+// GetSchedule godoc
+// @Summary 获取周期任务详情
+// @Tags Schedules
+// @Produce json
+// @Param schedule_id path string true "周期任务 ID"
+// @Success 200 {object} dto.ScheduleResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 501 {object} dto.ErrorResponse
+// @Router /schedules/{schedule_id} [get]
+func (h *ScheduleHandler) GetSchedule(c *gin.Context) {
+	if h.scheduleRepo == nil {
+		c.JSON(http.StatusNotImplemented, dto.ErrorResponse{Error: "Postgres 未配置"})
+		return
+	}
+	h.respondSchedule(c, c.Param("schedule_id"))
+}
+
+// UpdateSchedule godoc
+// @Summary 更新周期任务
+// @Description 整体替换周期任务定义，并重新计算下次触发时间；调度器在下一次同步时生效
+// @Tags Schedules
+// @Accept json
+// @Produce json
+// @Param schedule_id path string true "周期任务 ID"
+// @Param request body dto.ScheduleRequest true "周期任务定义"
+// @Success 200 {object} dto.ScheduleResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 501 {object} dto.ErrorResponse
+// @Router /schedules/{schedule_id} [put]
+func (h *ScheduleHandler) UpdateSchedule(c *gin.Context) {
+	if h.scheduleRepo == nil {
+		c.JSON(http.StatusNotImplemented, dto.ErrorResponse{Error: "Postgres 未配置"})
+		return
+	}
+
+	var req dto.ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	s, err := h.buildSchedule(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	s.ScheduleID = c.Param("schedule_id")
+
+	if err := h.scheduleRepo.UpdateSchedule(c.Request.Context(), s); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "schedule 不存在"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	h.respondSchedule(c, s.ScheduleID)
+}
+
+// DeleteSchedule godoc
+// @Summary 删除周期任务
+// @Description 删除周期任务及其触发历史（已创建的任务不受影响）
+// @Tags Schedules
+// @Produce json
+// @Param schedule_id path string true "周期任务 ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 501 {object} dto.ErrorResponse
+// @Router /schedules/{schedule_id} [delete]
+func (h *ScheduleHandler) DeleteSchedule(c *gin.Context) {
+	if h.scheduleRepo == nil {
+		c.JSON(http.StatusNotImplemented, dto.ErrorResponse{Error: "Postgres 未配置"})
+		return
+	}
+
+	scheduleID := c.Param("schedule_id")
+	if err := h.scheduleRepo.DeleteSchedule(c.Request.Context(), scheduleID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "schedule 不存在"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"schedule_id": scheduleID, "deleted": true})
+}
+
+// ListScheduleRuns godoc
+// @Summary 查询周期任务触发历史
+// @Description 按触发时间倒序返回每次触发创建的 task_id 或失败原因
+// @Tags Schedules
+// @Produce json
+// @Param schedule_id path string true "周期任务 ID"
+// @Param limit query int false "返回条数（最大 200）" default(50)
+// @Success 200 {object} dto.ScheduleRunListResponse
+// @Failure 501 {object} dto.ErrorResponse
+// @Router /schedules/{schedule_id}/runs [get]
+func (h *ScheduleHandler) ListScheduleRuns(c *gin.Context) {
+	if h.scheduleRepo == nil {
+		c.JSON(http.StatusNotImplemented, dto.ErrorResponse{Error: "Postgres 未配置"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	items, err := h.scheduleRepo.ListRuns(c.Request.Context(), c.Param("schedule_id"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ScheduleRunListResponse{Items: items})
+}
+
+// TriggerSchedule godoc
+// @Summary 立即触发周期任务
+// @Description 手动触发一次，结果记录到触发历史并刷新 last_run_at；距离下一次定时触发不足半个周期时，该次定时触发会被合并
+// @Tags Schedules
+// @Produce json
+// @Param schedule_id path string true "周期任务 ID"
+// @Success 200 {object} repository.ScheduleRun
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 501 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Router /schedules/{schedule_id}/trigger [post]
+func (h *ScheduleHandler) TriggerSchedule(c *gin.Context) {
+	if h.scheduleRepo == nil {
+		c.JSON(http.StatusNotImplemented, dto.ErrorResponse{Error: "Postgres 未配置"})
+		return
+	}
+	if h.tasks.asynqClient == nil {
+		c.JSON(http.StatusServiceUnavailable, dto.ErrorResponse{Error: "asynq client 未配置"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	s, err := h.scheduleRepo.GetSchedule(ctx, c.Param("schedule_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "schedule 不存在"})
+		return
+	}
+
+	run, err := scheduler.Fire(ctx, h.scheduleRepo, h.tasks, *s, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
+
+// buildSchedule 校验请求并转换为周期任务定义（含下次触发时间）
+func (h *ScheduleHandler) buildSchedule(req dto.ScheduleRequest) (repository.Schedule, error) {
+	taskReq := dto.CreateTaskRequest{
+		WorkerName: req.WorkerName,
+		Queue:      req.Queue,
+		Priority:   req.Priority,
+		Payload:    req.Payload,
+	}
+	if err := validateCreateTaskRequest(&taskReq); err != nil {
+		return repository.Schedule{}, err
+	}
+	if _, err := h.tasks.resolveTaskTarget(taskReq.WorkerName, taskReq.Queue, taskReq.Priority); err != nil {
+		return repository.Schedule{}, err
+	}
+
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	next, err := scheduler.NextRun(req.CronSpec, timezone, time.Now())
+	if err != nil {
+		return repository.Schedule{}, fmt.Errorf("cron_spec 无效: %w", err)
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	s := repository.Schedule{
+		Name:       req.Name,
+		CronSpec:   req.CronSpec,
+		Timezone:   timezone,
+		WorkerName: taskReq.WorkerName,
+		Queue:      taskReq.Queue,
+		Priority:   taskReq.Priority,
+		Payload:    taskReq.Payload,
+		Enabled:    enabled,
+	}
+	if enabled {
+		s.NextRunAt = &next
+	}
+	return s, nil
+}
+
+// respondSchedule 返回周期任务详情
+func (h *ScheduleHandler) respondSchedule(c *gin.Context, scheduleID string) {
+	s, err := h.scheduleRepo.GetSchedule(c.Request.Context(), scheduleID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "schedule 不存在"})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ScheduleResponse{Item: s})
+}
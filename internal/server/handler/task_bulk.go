@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/azhengyongqin/asynq-hub/internal/logger"
+	"github.com/azhengyongqin/asynq-hub/internal/model"
+	asynqx "github.com/azhengyongqin/asynq-hub/internal/queue"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+	workers "github.com/azhengyongqin/asynq-hub/internal/worker"
+)
+
+// checkBulkOperation 检查执行批量操作所需的依赖是否已配置
+func (h *TaskHandler) checkBulkOperation(op model.BulkOperation) error {
+	if h.taskRepo == nil {
+		return errors.New("Postgres 未配置")
+	}
+	if op != model.BulkOperationRetry && h.inspector == nil {
+		return errors.New("asynq inspector 未配置")
+	}
+	needClient := op == model.BulkOperationChangePriority || (op == model.BulkOperationRetry && !h.outbox)
+	if needClient && h.asynqClient == nil {
+		return errors.New("asynq client 未配置")
+	}
+	return nil
+}
+
+// ApplyBulkOperation 对单个任务执行批量操作（实现 bulkjob.Executor）。
+// 任务不满足操作条件时返回跳过原因；dry_run 时只做该判断，不修改任何数据。
+func (h *TaskHandler) ApplyBulkOperation(ctx context.Context, job *repository.BulkJob, t *repository.Task) (string, error) {
+	op := model.BulkOperation(job.Operation)
+	if reason := bulkSkipReason(job, t); reason != "" {
+		return reason, nil
+	}
+	if job.DryRun {
+		return "", nil
+	}
+	if err := h.checkBulkOperation(op); err != nil {
+		return "", err
+	}
+
+	switch op {
+	case model.BulkOperationRetry:
+		return "", h.retryTask(ctx, t)
+	case model.BulkOperationCancel:
+		_, err := h.cancelTask(ctx, t, job.Reason)
+		if errors.Is(err, errTaskCompleted) {
+			return err.Error(), nil
+		}
+		return "", err
+	case model.BulkOperationDelete:
+		return "", h.deleteTask(ctx, t)
+	case model.BulkOperationChangePriority:
+		return h.changeTaskPriority(ctx, t, job.Priority)
+	default:
+		return "", fmt.Errorf("不支持的批量操作: %s", job.Operation)
+	}
+}
+
+// bulkSkipReason 按任务记录判断是否满足批量操作的条件，不满足时返回原因
+func bulkSkipReason(job *repository.BulkJob, t *repository.Task) string {
+	status := model.TaskStatus(t.Status)
+	switch model.BulkOperation(job.Operation) {
+	case model.BulkOperationRetry:
+		if !status.IsTerminal() && status != model.TaskStatusFail {
+			return fmt.Sprintf("只能重试已结束的任务（当前状态 %s）", status)
+		}
+	case model.BulkOperationCancel:
+		if status.IsTerminal() {
+			return fmt.Sprintf("任务已结束（当前状态 %s）", status)
+		}
+	case model.BulkOperationDelete:
+		if status == model.TaskStatusRunning {
+			return "执行中的任务需要先取消"
+		}
+		if t.WorkflowID != "" {
+			return "工作流中的任务不能单独删除"
+		}
+	case model.BulkOperationChangePriority:
+		if status != model.TaskStatusPending && status != model.TaskStatusWaiting {
+			return fmt.Sprintf("只能调整尚未开始执行的任务（当前状态 %s）", status)
+		}
+		if t.Priority == priorityToInt(job.Priority) {
+			return "优先级未变化"
+		}
+	}
+	return ""
+}
+
+// retryTask 基于已结束的任务重新提交一个新任务（与重放相同，新任务通过 replayed_from 指向原任务）
+func (h *TaskHandler) retryTask(ctx context.Context, t *repository.Task) error {
+	workerCfg, ok := h.workerStore.Get(t.WorkerName)
+	if !ok {
+		return fmt.Errorf("worker %s 不存在", t.WorkerName)
+	}
+	return h.submitRequeuedTask(ctx, workerCfg, newRequeuedTask(*t, asynqx.NewTaskID()), 0)
+}
+
+// deleteTask 从 Redis 中删除任务（已不存在时忽略），再删除任务记录及其执行历史
+func (h *TaskHandler) deleteTask(ctx context.Context, t *repository.Task) error {
+	err := h.inspector.DeleteTask(t.Queue, t.AsynqID())
+	if err != nil && !errors.Is(err, asynq.ErrTaskNotFound) && !errors.Is(err, asynq.ErrQueueNotFound) {
+		return fmt.Errorf("从 Redis 删除任务失败: %w", err)
+	}
+	_, err = h.taskRepo.DeleteTask(ctx, t.TaskID)
+	return err
+}
+
+// changeTaskPriority 将尚未执行的任务移动到同一队列组的 priority 优先级队列。
+// asynq 不支持修改任务所在队列：先以相同 task_id 入队到新队列（保留延迟执行时间），再从原队列删除；
+// 原任务已被取出执行时撤销新入队的任务。等待上游的工作流任务尚未入队，只修改记录。
+func (h *TaskHandler) changeTaskPriority(ctx context.Context, t *repository.Task, priority string) (string, error) {
+	_, queueGroup, _, ok := workers.ParseFullQueueName(t.Queue)
+	if !ok {
+		return "", fmt.Errorf("任务队列名无效: %s", t.Queue)
+	}
+	workerCfg, err := h.resolveTaskTarget(t.WorkerName, queueGroup, priority)
+	if err != nil {
+		return "", err
+	}
+	queue := workerCfg.FullQueueName(queueGroup, priority)
+
+	if t.Status == string(model.TaskStatusWaiting) {
+		moved, err := h.taskRepo.MoveTaskQueue(ctx, t.TaskID, t.Status, queue, priorityToInt(priority), "")
+		if err == nil && !moved {
+			return "任务已开始执行", nil
+		}
+		return "", err
+	}
+
+	asynqID := t.AsynqID()
+	info, err := h.inspector.GetTaskInfo(t.Queue, asynqID)
+	switch {
+	case errors.Is(err, asynq.ErrTaskNotFound), errors.Is(err, asynq.ErrQueueNotFound):
+		return "Redis 中已不存在该任务", nil
+	case err != nil:
+		return "", err
+	case info.State != asynq.TaskStatePending && info.State != asynq.TaskStateScheduled:
+		return fmt.Sprintf("任务在 Redis 中的状态为 %s，只能调整等待执行的任务", info.State), nil
+	}
+
+	p := asynqx.EnqueueParams{
+		TaskType: queue,
+		TaskKey:  t.TaskID,
+		Queue:    queue,
+		Payload:  t.Payload,
+	}
+	if info.State == asynq.TaskStateScheduled {
+		p.RunAt = info.NextProcessAt
+	}
+	applyTaskOverrides(&p, workerCfg, *t)
+	if _, err := h.asynqClient.Enqueue(newAsynqTask(queue, t.TaskID, t.Payload, t.ExpiresAt), asynqx.EnqueueOptions(p)...); err != nil {
+		return "", fmt.Errorf("入队到 %s 失败: %w", queue, err)
+	}
+	if err := h.inspector.DeleteTask(t.Queue, asynqID); err != nil {
+		if err := h.inspector.DeleteTask(queue, t.TaskID); err != nil {
+			logger.L.Error().Err(err).Str("task_id", t.TaskID).Str("queue", queue).Msg("撤销移动到新队列的任务失败，任务可能被执行两次")
+		}
+		return "", fmt.Errorf("从原队列删除任务失败: %w", err)
+	}
+
+	// 任务已在新队列中，无论记录状态是否已被 worker 上报修改都更新队列
+	_, err = h.taskRepo.MoveTaskQueue(ctx, t.TaskID, "", queue, priorityToInt(priority), t.TaskID)
+	return "", err
+}
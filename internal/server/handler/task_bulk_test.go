@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+	"github.com/azhengyongqin/asynq-hub/internal/server/dto"
+)
+
+func TestBulkSkipReason(t *testing.T) {
+	standalone := func(status string) *repository.Task {
+		task := testTask("a", status)
+		task.Priority = priorityToInt("default")
+		return &task
+	}
+	inWorkflow := workflowTask("a", "fail")
+
+	cases := []struct {
+		name string
+		job  repository.BulkJob
+		task *repository.Task
+		skip bool
+	}{
+		{"重试失败任务", repository.BulkJob{Operation: "retry"}, standalone("fail"), false},
+		{"重试已取消任务", repository.BulkJob{Operation: "retry"}, standalone("canceled"), false},
+		{"重试执行中任务", repository.BulkJob{Operation: "retry"}, standalone("running"), true},
+		{"取消等待任务", repository.BulkJob{Operation: "cancel"}, standalone("pending"), false},
+		{"取消已成功任务", repository.BulkJob{Operation: "cancel"}, standalone("success"), true},
+		{"删除已结束任务", repository.BulkJob{Operation: "delete"}, standalone("dead"), false},
+		{"删除执行中任务", repository.BulkJob{Operation: "delete"}, standalone("running"), true},
+		{"删除工作流任务", repository.BulkJob{Operation: "delete"}, &inWorkflow, true},
+		{"调整等待任务优先级", repository.BulkJob{Operation: "change_priority", Priority: "critical"}, standalone("pending"), false},
+		{"优先级未变化", repository.BulkJob{Operation: "change_priority", Priority: "default"}, standalone("pending"), true},
+		{"调整执行中任务优先级", repository.BulkJob{Operation: "change_priority", Priority: "critical"}, standalone("running"), true},
+	}
+	for _, tc := range cases {
+		reason := bulkSkipReason(&tc.job, tc.task)
+		assert.Equal(t, tc.skip, reason != "", "%s: %q", tc.name, reason)
+	}
+}
+
+func TestApplyBulkOperation(t *testing.T) {
+	failed := testTask("failed", "fail")
+	pending := testTask("pending", "pending")
+	h, repo, _, enq := newTestHandler(t, failed, pending)
+	ctx := context.Background()
+
+	t.Run("dry_run 不修改数据", func(t *testing.T) {
+		job := &repository.BulkJob{Operation: "retry", DryRun: true}
+		skipped, err := h.ApplyBulkOperation(ctx, job, &failed)
+		require.NoError(t, err)
+		assert.Empty(t, skipped)
+		assert.Equal(t, 0, enq.count())
+		assert.Len(t, repo.tasks, 2)
+	})
+
+	t.Run("重试生成新任务", func(t *testing.T) {
+		job := &repository.BulkJob{Operation: "retry"}
+		skipped, err := h.ApplyBulkOperation(ctx, job, &failed)
+		require.NoError(t, err)
+		assert.Empty(t, skipped)
+		assert.Equal(t, 1, enq.count())
+		require.Len(t, repo.tasks, 3)
+		for id, task := range repo.tasks {
+			if id != "failed" && id != "pending" {
+				assert.Equal(t, "failed", task.ReplayedFrom)
+			}
+		}
+	})
+
+	t.Run("不满足条件时跳过", func(t *testing.T) {
+		skipped, err := h.ApplyBulkOperation(ctx, &repository.BulkJob{Operation: "retry"}, &pending)
+		require.NoError(t, err)
+		assert.NotEmpty(t, skipped)
+	})
+
+	t.Run("缺少依赖时失败", func(t *testing.T) {
+		_, err := h.ApplyBulkOperation(ctx, &repository.BulkJob{Operation: "cancel"}, &pending)
+		assert.Error(t, err, "未配置 inspector")
+		assert.Equal(t, "pending", repo.status("pending"))
+	})
+}
+
+func TestBuildBulkJob(t *testing.T) {
+	job, err := buildBulkJob(context.Background(), dto.CreateBulkJobRequest{
+		Operation: "change_priority",
+		Priority:  "critical",
+		Reason:    "ignored",
+		Filter:    dto.BulkTaskFilter{WorkerName: "test-worker", Priority: "low", Payload: json.RawMessage(`{"tenant":"a"}`), PayloadPath: "$.amount > 100"},
+	}, &fakeTaskRepo{})
+	require.NoError(t, err)
+	assert.Equal(t, "critical", job.Priority)
+	assert.Empty(t, job.Reason, "只有 cancel 记录原因")
+	assert.Equal(t, priorityToInt("low"), job.Filter.Priority)
+	assert.JSONEq(t, `{"tenant":"a"}`, string(job.Filter.PayloadContains))
+
+	repo := &fakeTaskRepo{invalidPaths: map[string]bool{"$.amount >": true}}
+	for name, req := range map[string]dto.CreateBulkJobRequest{
+		"操作无效":        {Operation: "archive", Filter: dto.BulkTaskFilter{Status: "fail"}},
+		"缺少目标优先级":     {Operation: "change_priority", Filter: dto.BulkTaskFilter{Status: "pending"}},
+		"过滤条件为空":      {Operation: "retry"},
+		"状态无效":        {Operation: "retry", Filter: dto.BulkTaskFilter{Status: "broken"}},
+		"payload 非对象": {Operation: "delete", Filter: dto.BulkTaskFilter{Payload: json.RawMessage(`[1]`)}},
+		"jsonpath 无效": {Operation: "retry", Filter: dto.BulkTaskFilter{PayloadPath: "$.amount >"}},
+	} {
+		_, err := buildBulkJob(context.Background(), req, repo)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/azhengyongqin/asynq-hub/internal/logger"
+	"github.com/azhengyongqin/asynq-hub/internal/middleware"
+	"github.com/azhengyongqin/asynq-hub/internal/model"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+	"github.com/azhengyongqin/asynq-hub/internal/server/dto"
+	workers "github.com/azhengyongqin/asynq-hub/internal/worker"
+)
+
+// 回调类型，同时用于生成回调任务 ID
+const (
+	callbackOnSuccess = "on_success"
+	callbackOnFailure = "on_failure"
+)
+
+// validateCallbackRequest 校验回调任务定义的格式，并补全默认优先级
+func validateCallbackRequest(name string, cb *dto.CallbackTaskRequest) error {
+	if cb == nil {
+		return nil
+	}
+	if cb.Priority == "" {
+		cb.Priority = workers.PriorityDefault
+	}
+	if cb.Priority != workers.PriorityCritical && cb.Priority != workers.PriorityDefault && cb.Priority != workers.PriorityLow {
+		return fmt.Errorf("%s.priority 必须是 critical, default 或 low", name)
+	}
+	if !middleware.ValidateWorkerName(cb.WorkerName) {
+		return fmt.Errorf("%s.worker_name 格式无效", name)
+	}
+	if !middleware.ValidateQueueName(cb.Queue) {
+		return fmt.Errorf("%s.queue 格式无效", name)
+	}
+	if len(cb.Payload) > middleware.MaxPayloadSize {
+		return fmt.Errorf("%s.payload 过大，最大 2MB", name)
+	}
+	return nil
+}
+
+// resolveCallbackTargets 校验回调任务的 worker/队列组/优先级在当前配置中可用
+func (h *TaskHandler) resolveCallbackTargets(req dto.CreateTaskRequest) error {
+	for name, cb := range map[string]*dto.CallbackTaskRequest{callbackOnSuccess: req.OnSuccess, callbackOnFailure: req.OnFailure} {
+		if cb == nil {
+			continue
+		}
+		if _, err := h.resolveTaskTarget(cb.WorkerName, cb.Queue, cb.Priority); err != nil {
+			return fmt.Errorf("%s: %s", name, err.Error())
+		}
+	}
+	return nil
+}
+
+// toCallbackSpec 转换为持久化的回调定义
+func toCallbackSpec(cb *dto.CallbackTaskRequest) *repository.CallbackSpec {
+	if cb == nil {
+		return nil
+	}
+	return &repository.CallbackSpec{
+		WorkerName: cb.WorkerName,
+		Queue:      cb.Queue,
+		Priority:   cb.Priority,
+		Payload:    cb.Payload,
+	}
+}
+
+// callbackTaskID 由父任务 ID 与回调类型派生回调任务 ID，重复上报时不会重复入队
+func callbackTaskID(parentTaskID, kind string) string {
+	sum := sha256.Sum256([]byte(parentTaskID + ":" + kind))
+	return hex.EncodeToString(sum[:16])
+}
+
+// renderCallbackPayload 替换 payload 模板中的占位符（值按 JSON 字符串转义）
+func renderCallbackPayload(tmpl json.RawMessage, parent *repository.Task) json.RawMessage {
+	if len(tmpl) == 0 {
+		return tmpl
+	}
+	escape := func(s string) string {
+		b, _ := json.Marshal(s)
+		return string(b[1 : len(b)-1])
+	}
+	r := strings.NewReplacer(
+		"{{parent_task_id}}", escape(parent.TaskID),
+		"{{parent_status}}", escape(parent.Status),
+		"{{parent_error}}", escape(parent.LastError),
+	)
+	return json.RawMessage(r.Replace(string(tmpl)))
+}
+
+// enqueueCallback 父任务进入 success/dead/expired 后入队对应的回调任务（失败只记录日志，不影响上报）
+func (h *TaskHandler) enqueueCallback(ctx context.Context, parent *repository.Task) {
+	var (
+		spec *repository.CallbackSpec
+		kind string
+	)
+	switch model.TaskStatus(parent.Status) {
+	case model.TaskStatusSuccess:
+		spec, kind = parent.OnSuccess, callbackOnSuccess
+	case model.TaskStatusDead, model.TaskStatusExpired:
+		spec, kind = parent.OnFailure, callbackOnFailure
+	}
+	if spec == nil || (h.asynqClient == nil && !h.outbox) {
+		return
+	}
+
+	log := logger.L.With().Str("parent_task_id", parent.TaskID).Str("callback", kind).Logger()
+
+	req := dto.CreateTaskRequest{
+		WorkerName: spec.WorkerName,
+		Queue:      spec.Queue,
+		Priority:   spec.Priority,
+		Payload:    renderCallbackPayload(spec.Payload, parent),
+	}
+	if req.Priority == "" {
+		req.Priority = workers.PriorityDefault
+	}
+	workerCfg, err := h.resolveTaskTarget(req.WorkerName, req.Queue, req.Priority)
+	if err != nil {
+		log.Error().Err(err).Msg("回调任务目标不可用")
+		return
+	}
+	if err := h.ensureWorkerPersisted(ctx, workerCfg); err != nil {
+		log.Error().Err(err).Msg("创建 worker 到数据库失败")
+		return
+	}
+
+	taskID := callbackTaskID(parent.TaskID, kind)
+	if h.outbox {
+		// 出箱模式：回调任务记录与出箱记录在同一事务中写入，已存在说明此前已创建
+		fullQueue := workerCfg.FullQueueName(req.Queue, req.Priority)
+		t := newPendingTask(req, taskID, fullQueue, taskID)
+		t.ParentTaskID = parent.TaskID
+		if err := h.taskRepo.CreateTaskWithOutbox(ctx, t, nil); err != nil && !errors.Is(err, repository.ErrTaskExists) {
+			log.Error().Err(err).Str("task_id", taskID).Msg("保存回调任务及出箱记录失败")
+		}
+		return
+	}
+
+	fullQueue, info, err := h.enqueueCreateTask(workerCfg, req, taskID)
+	if err != nil {
+		if errors.Is(err, asynq.ErrTaskIDConflict) || errors.Is(err, asynq.ErrDuplicateTask) {
+			return
+		}
+		log.Error().Err(err).Msg("入队回调任务失败")
+		return
+	}
+
+	t := newPendingTask(req, taskID, fullQueue, info.ID)
+	t.ParentTaskID = parent.TaskID
+	if err := h.taskRepo.UpsertTask(ctx, t); err != nil {
+		log.Error().Err(err).Str("task_id", taskID).Msg("保存回调任务到数据库失败")
+	}
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+)
+
+// callbackParent 配置了成功与失败回调的独立任务
+func callbackParent(id, status string) repository.Task {
+	t := testTask(id, status)
+	t.OnSuccess = &repository.CallbackSpec{WorkerName: "test-worker", Queue: "default", Priority: "default", Payload: json.RawMessage(`{"on":"success","parent":"{{parent_task_id}}"}`)}
+	t.OnFailure = &repository.CallbackSpec{WorkerName: "test-worker", Queue: "default", Payload: json.RawMessage(`{"on":"failure","error":"{{parent_error}}"}`)}
+	return t
+}
+
+func TestRenderCallbackPayload(t *testing.T) {
+	tmpl := json.RawMessage(`{"id":"{{parent_task_id}}","status":"{{parent_status}}","error":"{{parent_error}}"}`)
+
+	cases := []struct {
+		name      string
+		lastError string
+	}{
+		{"普通错误", "timeout"},
+		{"包含引号", `bad "input"`},
+		{"包含换行", "line1\nline2\r\n\tline3"},
+		{"包含反斜杠与 HTML", `C:\tmp <b>&`},
+		{"空错误", ""},
+	}
+	for _, tc := range cases {
+		parent := &repository.Task{TaskID: "p-1", Status: "dead", LastError: tc.lastError}
+		out := renderCallbackPayload(tmpl, parent)
+
+		var got map[string]string
+		require.NoError(t, json.Unmarshal(out, &got), "%s: %s", tc.name, out)
+		assert.Equal(t, map[string]string{"id": "p-1", "status": "dead", "error": tc.lastError}, got, tc.name)
+	}
+
+	assert.Empty(t, renderCallbackPayload(nil, &repository.Task{}), "未配置 payload 时保持为空")
+}
+
+func TestCallbackTaskID(t *testing.T) {
+	id := callbackTaskID("p-1", callbackOnSuccess)
+	assert.Equal(t, id, callbackTaskID("p-1", callbackOnSuccess), "同一父任务与回调类型得到相同 ID")
+	assert.NotEqual(t, id, callbackTaskID("p-1", callbackOnFailure))
+	assert.NotEqual(t, id, callbackTaskID("p-2", callbackOnSuccess))
+	assert.Len(t, id, 32)
+}
+
+func TestEnqueueCallback(t *testing.T) {
+	cases := []struct {
+		status string
+		kind   string // 为空表示不入队回调
+	}{
+		{"success", callbackOnSuccess},
+		{"dead", callbackOnFailure},
+		{"expired", callbackOnFailure},
+		{"canceled", ""},
+		{"lost", ""},
+		{"fail", ""},
+	}
+	for _, tc := range cases {
+		parent := callbackParent("p-1", tc.status)
+		parent.LastError = "boom"
+		h, repo, _, enq := newTestHandler(t, parent)
+
+		h.enqueueCallback(context.Background(), &parent)
+
+		if tc.kind == "" {
+			assert.Zero(t, enq.count(), tc.status)
+			assert.Len(t, repo.tasks, 1, tc.status)
+			continue
+		}
+		assert.Equal(t, 1, enq.count(), tc.status)
+		child, ok := repo.tasks[callbackTaskID("p-1", tc.kind)]
+		require.True(t, ok, tc.status)
+		assert.Equal(t, "p-1", child.ParentTaskID, tc.status)
+		assert.Equal(t, "pending", child.Status, tc.status)
+		assert.Equal(t, "test-worker:default:default", child.Queue, "%s: 未指定优先级时使用 default", tc.status)
+		if tc.kind == callbackOnFailure {
+			assert.JSONEq(t, `{"on":"failure","error":"boom"}`, string(child.Payload), tc.status)
+		} else {
+			assert.JSONEq(t, `{"on":"success","parent":"p-1"}`, string(child.Payload), tc.status)
+		}
+	}
+}
+
+func TestEnqueueCallback_Duplicate(t *testing.T) {
+	parent := callbackParent("p-1", "success")
+	childID := callbackTaskID("p-1", callbackOnSuccess)
+
+	t.Run("asynq 中已存在", func(t *testing.T) {
+		h, repo, _, _ := newTestHandler(t, parent)
+		enq := &conflictEnqueuer{conflicts: map[string]bool{childID: true}}
+		h.asynqClient = enq
+
+		h.enqueueCallback(context.Background(), &parent)
+		assert.Zero(t, enq.count())
+		assert.NotContains(t, repo.tasks, childID, "已入队的回调不重复写入")
+	})
+
+	t.Run("出箱模式", func(t *testing.T) {
+		h, repo, _, enq := newTestHandler(t, parent)
+		h.SetOutboxEnabled(true)
+
+		h.enqueueCallback(context.Background(), &parent)
+		h.enqueueCallback(context.Background(), &parent)
+		assert.Equal(t, []string{childID}, repo.outbox)
+		assert.Equal(t, "p-1", repo.tasks[childID].ParentTaskID)
+		assert.Zero(t, enq.count())
+	})
+}
+
+func TestReportAttempt_DuplicateSuccessEnqueuesCallbackOnce(t *testing.T) {
+	h, repo, _, enq := newTestHandler(t, callbackParent("p-1", "running"))
+
+	for i := 0; i < 2; i++ {
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/", strings.NewReader(`{"attempt":1,"status":"success","started_at":"2026-03-12T12:00:00Z"}`))
+		c.Params = gin.Params{{Key: "task_id", Value: "p-1"}}
+
+		h.ReportAttempt(c)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	assert.Equal(t, 1, enq.count(), "重复上报不重复入队回调")
+	assert.Equal(t, "p-1", repo.tasks[callbackTaskID("p-1", callbackOnSuccess)].ParentTaskID)
+}
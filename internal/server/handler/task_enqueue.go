@@ -0,0 +1,464 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/azhengyongqin/asynq-hub/internal/logger"
+	"github.com/azhengyongqin/asynq-hub/internal/middleware"
+	"github.com/azhengyongqin/asynq-hub/internal/model"
+	asynqx "github.com/azhengyongqin/asynq-hub/internal/queue"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+	"github.com/azhengyongqin/asynq-hub/internal/server/dto"
+	workers "github.com/azhengyongqin/asynq-hub/internal/worker"
+)
+
+// maxTaskRetry 任务级 max_retry 的上限
+const maxTaskRetry = 100
+
+// taskMessage 投递到 asynq 的任务信封（worker 侧按 sdk.Task 解析）
+type taskMessage struct {
+	ID        string          `json:"id,omitempty"`
+	TaskID    string          `json:"task_id,omitempty"`
+	Payload   json.RawMessage `json:"payload"`
+	ExpiresAt *time.Time      `json:"expires_at,omitempty"` // worker 在此之后不再开始执行
+}
+
+// newAsynqTask 构造 asynq 任务，任务类型与完整队列名一致
+func newAsynqTask(fullQueue, taskID string, payload json.RawMessage, expiresAt *time.Time) *asynq.Task {
+	b, _ := json.Marshal(taskMessage{
+		ID:        taskID,
+		TaskID:    taskID,
+		Payload:   payload,
+		ExpiresAt: expiresAt,
+	})
+	return asynq.NewTask(fullQueue, b)
+}
+
+// validateCreateTaskRequest 校验创建任务请求的字段格式（不涉及 worker 配置），并补全默认优先级
+func validateCreateTaskRequest(req *dto.CreateTaskRequest) error {
+	// 默认优先级为 default
+	if req.Priority == "" {
+		req.Priority = workers.PriorityDefault
+	}
+
+	// 验证优先级
+	if req.Priority != workers.PriorityCritical && req.Priority != workers.PriorityDefault && req.Priority != workers.PriorityLow {
+		return errors.New("priority 必须是 critical, default 或 low")
+	}
+
+	// 验证 worker_name 格式
+	if !middleware.ValidateWorkerName(req.WorkerName) {
+		return errors.New("worker_name 格式无效")
+	}
+
+	// 验证 queue 格式
+	if !middleware.ValidateQueueName(req.Queue) {
+		return errors.New("queue 格式无效")
+	}
+
+	// 验证 task_id 格式（如果提供）
+	if req.TaskID != "" && !middleware.ValidateTaskID(req.TaskID) {
+		return errors.New("task_id 格式无效")
+	}
+
+	// 验证 payload 大小
+	if len(req.Payload) > middleware.MaxPayloadSize {
+		return errors.New("payload 过大，最大 2MB")
+	}
+
+	// 验证任务级覆盖参数
+	if req.MaxRetry != nil && (*req.MaxRetry < 0 || *req.MaxRetry > maxTaskRetry) {
+		return errors.New("max_retry 必须在 0-100 之间")
+	}
+	if req.TimeoutSeconds != nil && *req.TimeoutSeconds <= 0 {
+		return errors.New("timeout_seconds 必须大于 0")
+	}
+	if req.Deadline != nil && !req.Deadline.After(time.Now()) {
+		return errors.New("deadline 必须晚于当前时间")
+	}
+	if req.RetentionSeconds != nil && *req.RetentionSeconds < 0 {
+		return errors.New("retention_seconds 不能为负数")
+	}
+	if err := resolveTaskExpiry(req, time.Now()); err != nil {
+		return err
+	}
+
+	// 验证标签
+	if err := middleware.ValidateLabels(req.Labels); err != nil {
+		return err
+	}
+
+	// 验证回调任务
+	if err := validateCallbackRequest(callbackOnSuccess, req.OnSuccess); err != nil {
+		return err
+	}
+	if err := validateCallbackRequest(callbackOnFailure, req.OnFailure); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// resolveTaskExpiry 校验过期时间并将 ttl_seconds 换算为 expires_at。
+// 过期时间必须晚于计划开始执行的时间（run_at / delay_seconds），否则任务注定过期。
+func resolveTaskExpiry(req *dto.CreateTaskRequest, now time.Time) error {
+	if req.TTLSeconds != nil {
+		if req.ExpiresAt != nil {
+			return errors.New("expires_at 与 ttl_seconds 不能同时设置")
+		}
+		if *req.TTLSeconds <= 0 {
+			return errors.New("ttl_seconds 必须大于 0")
+		}
+		expiresAt := now.Add(time.Duration(*req.TTLSeconds) * time.Second)
+		req.ExpiresAt = &expiresAt
+		req.TTLSeconds = nil
+	}
+	if req.ExpiresAt == nil {
+		return nil
+	}
+
+	start := now.Add(time.Duration(req.DelaySeconds) * time.Second)
+	if req.RunAt != nil && req.RunAt.After(start) {
+		start = *req.RunAt
+	}
+	if !req.ExpiresAt.After(start) {
+		return errors.New("expires_at 必须晚于计划执行时间")
+	}
+	return nil
+}
+
+// validateReplayTaskRequest 校验重放请求中修改的字段（规则与创建任务一致）
+func validateReplayTaskRequest(req dto.ReplayTaskRequest) error {
+	if req.Delay < 0 {
+		return errors.New("delay 不能为负数")
+	}
+	if req.Priority != "" && req.Priority != workers.PriorityCritical && req.Priority != workers.PriorityDefault && req.Priority != workers.PriorityLow {
+		return errors.New("priority 必须是 critical, default 或 low")
+	}
+	if req.Queue != "" && !middleware.ValidateQueueName(req.Queue) {
+		return errors.New("queue 格式无效")
+	}
+	if len(req.Payload) > middleware.MaxPayloadSize {
+		return errors.New("payload 过大，最大 2MB")
+	}
+	if req.MaxRetry != nil && (*req.MaxRetry < 0 || *req.MaxRetry > maxTaskRetry) {
+		return errors.New("max_retry 必须在 0-100 之间")
+	}
+	if req.TimeoutSeconds != nil && *req.TimeoutSeconds <= 0 {
+		return errors.New("timeout_seconds 必须大于 0")
+	}
+	return nil
+}
+
+// resolveTaskTarget 校验 worker、队列组与优先级是否可用，返回 worker 配置
+func (h *TaskHandler) resolveTaskTarget(workerName, queue, priority string) (workers.Config, error) {
+	// 验证 worker 是否存在
+	workerCfg, ok := h.workerStore.Get(workerName)
+	if !ok {
+		return workers.Config{}, errors.New("worker 不存在")
+	}
+	if !workerCfg.IsEnabled {
+		return workers.Config{}, errors.New("worker 未启用")
+	}
+
+	// 验证队列组是否存在
+	if !workerCfg.HasQueueGroup(queue) {
+		return workers.Config{}, errors.New("队列组不存在于 worker 配置中")
+	}
+
+	// 验证队列组的优先级是否存在
+	if !workerCfg.HasQueueWithPriority(queue, priority) {
+		return workers.Config{}, errors.New("优先级不存在于队列组配置中")
+	}
+
+	return workerCfg, nil
+}
+
+// batchTargets 批量提交时缓存 worker/队列组/优先级的校验结果与 worker 落库结果，
+// 同一批次内相同组合只校验一次
+type batchTargets struct {
+	h         *TaskHandler
+	targets   map[string]batchTarget
+	persisted map[string]error
+}
+
+type batchTarget struct {
+	cfg workers.Config
+	err error
+}
+
+func newBatchTargets(h *TaskHandler) *batchTargets {
+	return &batchTargets{
+		h:         h,
+		targets:   make(map[string]batchTarget),
+		persisted: make(map[string]error),
+	}
+}
+
+// resolve 校验任务的目标与回调目标，并确保 worker 已落库，返回 worker 配置
+func (b *batchTargets) resolve(ctx context.Context, item dto.CreateTaskRequest) (workers.Config, error) {
+	key := item.WorkerName + "|" + item.Queue + "|" + item.Priority
+	tg, ok := b.targets[key]
+	if !ok {
+		tg.cfg, tg.err = b.h.resolveTaskTarget(item.WorkerName, item.Queue, item.Priority)
+		b.targets[key] = tg
+	}
+	if tg.err != nil {
+		return workers.Config{}, tg.err
+	}
+	if err := b.h.resolveCallbackTargets(item); err != nil {
+		return workers.Config{}, err
+	}
+
+	perr, ok := b.persisted[item.WorkerName]
+	if !ok {
+		perr = b.h.ensureWorkerPersisted(ctx, tg.cfg)
+		b.persisted[item.WorkerName] = perr
+	}
+	if perr != nil {
+		return workers.Config{}, errors.New("创建 worker 失败: " + perr.Error())
+	}
+	return tg.cfg, nil
+}
+
+// ensureWorkerPersisted 确保 worker 在数据库中存在（因为 task 有外键约束）
+func (h *TaskHandler) ensureWorkerPersisted(ctx context.Context, workerCfg workers.Config) error {
+	if h.workerRepo == nil {
+		return nil
+	}
+	if _, err := h.workerRepo.Get(ctx, workerCfg.WorkerName); err == nil {
+		return nil
+	}
+
+	// Worker 不在数据库中，需要先创建
+	return h.workerRepo.Upsert(ctx, repository.WorkerConfig{
+		WorkerName:        workerCfg.WorkerName,
+		BaseURL:           workerCfg.BaseURL,
+		RedisAddr:         workerCfg.RedisAddr,
+		QueueGroups:       convertWorkerQueueGroups(workerCfg.QueueGroups),
+		DefaultRetryCount: workerCfg.DefaultRetryCount,
+		DefaultTimeout:    workerCfg.DefaultTimeout,
+		DefaultDelay:      workerCfg.DefaultDelay,
+		IsEnabled:         workerCfg.IsEnabled,
+		LastHeartbeatAt:   workerCfg.LastHeartbeatAt,
+	})
+}
+
+// enqueueCreateTask 按创建请求入队到 asynq，返回完整队列名与 asynq 任务信息
+func (h *TaskHandler) enqueueCreateTask(workerCfg workers.Config, req dto.CreateTaskRequest, taskID string) (string, *asynq.TaskInfo, error) {
+	// 构造完整队列名：workerName:queueGroupName:priority
+	fullQueue := workerCfg.FullQueueName(req.Queue, req.Priority)
+
+	p := asynqx.EnqueueParams{
+		TaskType:     fullQueue,
+		TaskKey:      taskID,
+		Queue:        fullQueue,
+		DelaySeconds: req.DelaySeconds,
+		Payload:      req.Payload,
+	}
+	if req.RunAt != nil {
+		p.RunAt = *req.RunAt
+	}
+	applyTaskOverrides(&p, workerCfg, repository.Task{
+		MaxRetry:         req.MaxRetry,
+		TimeoutSeconds:   req.TimeoutSeconds,
+		Deadline:         req.Deadline,
+		RetentionSeconds: req.RetentionSeconds,
+	})
+
+	info, err := h.asynqClient.Enqueue(newAsynqTask(fullQueue, taskID, req.Payload, req.ExpiresAt), asynqx.EnqueueOptions(p)...)
+	if err != nil {
+		return fullQueue, nil, err
+	}
+	return fullQueue, info, nil
+}
+
+// newPendingTask 构造刚入队任务的数据库记录
+func newPendingTask(req dto.CreateTaskRequest, taskID, fullQueue, asynqID string) repository.Task {
+	// 确保 payload 不为 null
+	payload := req.Payload
+	if payload == nil || string(payload) == "null" {
+		payload = []byte("{}")
+	}
+
+	return repository.Task{
+		TaskID:      taskID,
+		WorkerName:  req.WorkerName,
+		Queue:       fullQueue,
+		AsynqTaskID: asynqID,
+		Priority:    priorityToInt(req.Priority),
+		Payload:     payload,
+		Status:      string(model.TaskStatusPending),
+		LastAttempt: 0,
+
+		MaxRetry:         req.MaxRetry,
+		TimeoutSeconds:   req.TimeoutSeconds,
+		Deadline:         req.Deadline,
+		RetentionSeconds: req.RetentionSeconds,
+		ExpiresAt:        req.ExpiresAt,
+
+		OnSuccess: toCallbackSpec(req.OnSuccess),
+		OnFailure: toCallbackSpec(req.OnFailure),
+
+		Labels: req.Labels,
+	}
+}
+
+// applyTaskOverrides 按任务记录中的覆盖参数设置重试/超时/截止时间/保留时长，未覆盖的使用 worker 默认值
+func applyTaskOverrides(p *asynqx.EnqueueParams, workerCfg workers.Config, t repository.Task) {
+	p.MaxRetry = workerCfg.DefaultRetryCount
+	if t.MaxRetry != nil {
+		p.MaxRetry = *t.MaxRetry
+	}
+	p.TimeoutSeconds = workerCfg.DefaultTimeout
+	if t.TimeoutSeconds != nil {
+		p.TimeoutSeconds = *t.TimeoutSeconds
+	}
+	if t.Deadline != nil {
+		p.Deadline = *t.Deadline
+	}
+	if t.RetentionSeconds != nil {
+		p.RetentionSeconds = *t.RetentionSeconds
+	}
+}
+
+// newRequeuedTask 基于已有任务构造重新入队（重放/批量重试）的任务记录，沿用原任务的覆盖参数与回调，
+// 并通过 replayed_from 指向原任务。已经过去的 deadline 与 expires_at 不再沿用，否则新任务会立即失败或过期。
+func newRequeuedTask(t repository.Task, newTaskID string) repository.Task {
+	now := time.Now()
+	deadline := t.Deadline
+	if deadline != nil && !deadline.After(now) {
+		deadline = nil
+	}
+	expiresAt := t.ExpiresAt
+	if expiresAt != nil && !expiresAt.After(now) {
+		expiresAt = nil
+	}
+	return repository.Task{
+		TaskID:      newTaskID,
+		WorkerName:  t.WorkerName,
+		Queue:       t.Queue,
+		Priority:    t.Priority,
+		Payload:     t.Payload,
+		Status:      string(model.TaskStatusPending),
+		LastAttempt: 0,
+
+		MaxRetry:         t.MaxRetry,
+		TimeoutSeconds:   t.TimeoutSeconds,
+		Deadline:         deadline,
+		RetentionSeconds: t.RetentionSeconds,
+		ExpiresAt:        expiresAt,
+
+		OnSuccess: t.OnSuccess,
+		OnFailure: t.OnFailure,
+
+		Labels: t.Labels,
+
+		ReplayedFrom: t.TaskID,
+	}
+}
+
+// enqueueRequeuedTask 将 newRequeuedTask 构造的任务记录入队到 asynq
+func (h *TaskHandler) enqueueRequeuedTask(workerCfg workers.Config, t repository.Task, delaySeconds int32) (*asynq.TaskInfo, error) {
+	p := asynqx.EnqueueParams{
+		TaskType:     t.Queue,
+		TaskKey:      t.TaskID,
+		Queue:        t.Queue,
+		DelaySeconds: delaySeconds,
+		Payload:      t.Payload,
+	}
+	applyTaskOverrides(&p, workerCfg, t)
+	return h.asynqClient.Enqueue(newAsynqTask(t.Queue, t.TaskID, t.Payload, t.ExpiresAt), asynqx.EnqueueOptions(p)...)
+}
+
+// submitRequeuedTask 提交 newRequeuedTask 构造的任务（重放/批量重试）：
+// 出箱模式下任务记录与出箱记录在同一事务中写入，由 outbox.Relay 入队；
+// 否则先入队再写库，写库失败只记录日志（任务仍会执行）
+func (h *TaskHandler) submitRequeuedTask(ctx context.Context, workerCfg workers.Config, t repository.Task, delaySeconds int32) error {
+	if h.outbox {
+		t.AsynqTaskID = t.TaskID
+		var at *time.Time
+		if delaySeconds > 0 {
+			v := time.Now().Add(time.Duration(delaySeconds) * time.Second)
+			at = &v
+		}
+		return h.taskRepo.CreateTaskWithOutbox(ctx, t, at)
+	}
+
+	info, err := h.enqueueRequeuedTask(workerCfg, t, delaySeconds)
+	if err != nil {
+		return err
+	}
+	t.AsynqTaskID = info.ID
+	if err := h.taskRepo.UpsertTask(ctx, t); err != nil {
+		logger.L.Error().Err(err).Str("task_id", t.TaskID).Str("replayed_from", t.ReplayedFrom).Msg("保存重新入队的任务失败")
+	}
+	return nil
+}
+
+// processAt 创建请求的计划执行时间（run_at 或 delay_seconds），立即执行时返回 nil
+func processAt(req dto.CreateTaskRequest, now time.Time) *time.Time {
+	if req.RunAt != nil {
+		return req.RunAt
+	}
+	if req.DelaySeconds > 0 {
+		at := now.Add(time.Duration(req.DelaySeconds) * time.Second)
+		return &at
+	}
+	return nil
+}
+
+// DeliverTask 将出箱中的任务记录入队到 asynq（实现 outbox.Enqueuer）。
+// asynq 任务 ID 即 task_id：重复投递时的 ID 冲突说明任务已入队，视为成功；
+// 任务已不是 pending（如投递前被取消）时不再入队。
+func (h *TaskHandler) DeliverTask(_ context.Context, t repository.Task, processAt *time.Time) error {
+	if t.Status != string(model.TaskStatusPending) {
+		return nil
+	}
+	if h.asynqClient == nil {
+		return errors.New("asynq client 未配置")
+	}
+	workerCfg, ok := h.workerStore.Get(t.WorkerName)
+	if !ok {
+		return fmt.Errorf("worker %s 不存在", t.WorkerName)
+	}
+
+	p := asynqx.EnqueueParams{
+		TaskType: t.Queue,
+		TaskKey:  t.TaskID,
+		Queue:    t.Queue,
+		Payload:  t.Payload,
+	}
+	if processAt != nil {
+		p.RunAt = *processAt
+	}
+	applyTaskOverrides(&p, workerCfg, t)
+	_, err := h.asynqClient.Enqueue(newAsynqTask(t.Queue, t.TaskID, t.Payload, t.ExpiresAt), asynqx.EnqueueOptions(p)...)
+	if errors.Is(err, asynq.ErrTaskIDConflict) || errors.Is(err, asynq.ErrDuplicateTask) {
+		return nil
+	}
+	return err
+}
+
+// FireSchedule 实现 scheduler.Firer：按周期任务定义创建一个任务
+func (h *TaskHandler) FireSchedule(ctx context.Context, s repository.Schedule) (string, error) {
+	if h.asynqClient == nil {
+		return "", errors.New("asynq client 未配置")
+	}
+	resp, _, err := h.createTask(ctx, dto.CreateTaskRequest{
+		WorkerName: s.WorkerName,
+		Queue:      s.Queue,
+		Priority:   s.Priority,
+		Payload:    s.Payload,
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.TaskID, nil
+}
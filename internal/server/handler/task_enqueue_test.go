@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/azhengyongqin/asynq-hub/internal/middleware"
+	asynqx "github.com/azhengyongqin/asynq-hub/internal/queue"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+	"github.com/azhengyongqin/asynq-hub/internal/server/dto"
+	workers "github.com/azhengyongqin/asynq-hub/internal/worker"
+)
+
+func int32Ptr(v int32) *int32 { return &v }
+
+func TestValidateCreateTaskRequest(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		mutate  func(req *dto.CreateTaskRequest)
+		wantErr string
+	}{
+		{"最小请求", func(*dto.CreateTaskRequest) {}, ""},
+		{"优先级无效", func(r *dto.CreateTaskRequest) { r.Priority = "urgent" }, "priority"},
+		{"worker_name 无效", func(r *dto.CreateTaskRequest) { r.WorkerName = "bad name" }, "worker_name"},
+		{"queue 无效", func(r *dto.CreateTaskRequest) { r.Queue = "a:b" }, "queue"},
+		{"task_id 无效", func(r *dto.CreateTaskRequest) { r.TaskID = "bad id" }, "task_id"},
+		{"payload 过大", func(r *dto.CreateTaskRequest) {
+			r.Payload = json.RawMessage(`"` + strings.Repeat("a", middleware.MaxPayloadSize) + `"`)
+		}, "payload"},
+
+		{"max_retry 为 0", func(r *dto.CreateTaskRequest) { r.MaxRetry = int32Ptr(0) }, ""},
+		{"max_retry 上限", func(r *dto.CreateTaskRequest) { r.MaxRetry = int32Ptr(maxTaskRetry) }, ""},
+		{"max_retry 超过上限", func(r *dto.CreateTaskRequest) { r.MaxRetry = int32Ptr(maxTaskRetry + 1) }, "max_retry"},
+		{"max_retry 为负数", func(r *dto.CreateTaskRequest) { r.MaxRetry = int32Ptr(-1) }, "max_retry"},
+
+		{"timeout 为 1 秒", func(r *dto.CreateTaskRequest) { r.TimeoutSeconds = int32Ptr(1) }, ""},
+		{"timeout 为 0", func(r *dto.CreateTaskRequest) { r.TimeoutSeconds = int32Ptr(0) }, "timeout_seconds"},
+		{"timeout 为负数", func(r *dto.CreateTaskRequest) { r.TimeoutSeconds = int32Ptr(-5) }, "timeout_seconds"},
+
+		{"deadline 在未来", func(r *dto.CreateTaskRequest) { r.Deadline = &future }, ""},
+		{"deadline 已过去", func(r *dto.CreateTaskRequest) { r.Deadline = &past }, "deadline"},
+
+		{"retention 为 0", func(r *dto.CreateTaskRequest) { r.RetentionSeconds = int32Ptr(0) }, ""},
+		{"retention 为负数", func(r *dto.CreateTaskRequest) { r.RetentionSeconds = int32Ptr(-1) }, "retention_seconds"},
+
+		{"expires_at 与 ttl 同时设置", func(r *dto.CreateTaskRequest) {
+			r.ExpiresAt = &future
+			r.TTLSeconds = int32Ptr(60)
+		}, "ttl_seconds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := dto.CreateTaskRequest{WorkerName: "test-worker", Queue: "default", Payload: json.RawMessage(`{}`)}
+			tt.mutate(&req)
+
+			err := validateCreateTaskRequest(&req)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	req := dto.CreateTaskRequest{WorkerName: "test-worker", Queue: "default"}
+	require.NoError(t, validateCreateTaskRequest(&req))
+	assert.Equal(t, workers.PriorityDefault, req.Priority, "未指定优先级时补全为 default")
+}
+
+func TestApplyTaskOverrides(t *testing.T) {
+	workerCfg := workers.Config{WorkerName: "test-worker", DefaultRetryCount: 3, DefaultTimeout: 30}
+	deadline := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		task repository.Task
+		want asynqx.EnqueueParams
+	}{
+		{
+			name: "使用 worker 默认值",
+			want: asynqx.EnqueueParams{MaxRetry: 3, TimeoutSeconds: 30},
+		},
+		{
+			name: "max_retry 显式为 0 时不重试",
+			task: repository.Task{MaxRetry: int32Ptr(0)},
+			want: asynqx.EnqueueParams{MaxRetry: 0, TimeoutSeconds: 30},
+		},
+		{
+			name: "全部覆盖",
+			task: repository.Task{MaxRetry: int32Ptr(10), TimeoutSeconds: int32Ptr(5), Deadline: &deadline, RetentionSeconds: int32Ptr(600)},
+			want: asynqx.EnqueueParams{MaxRetry: 10, TimeoutSeconds: 5, Deadline: deadline, RetentionSeconds: 600},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p asynqx.EnqueueParams
+			applyTaskOverrides(&p, workerCfg, tt.task)
+			assert.Equal(t, tt.want, p)
+
+			// 覆盖值原样转换为 asynq 选项（max_retry 为 0 也要传递，否则 asynq 使用默认的 25 次）
+			var maxRetry any
+			for _, opt := range asynqx.EnqueueOptions(p) {
+				if opt.Type() == asynq.MaxRetryOpt {
+					maxRetry = opt.Value()
+				}
+			}
+			assert.Equal(t, int(tt.want.MaxRetry), maxRetry)
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/azhengyongqin/asynq-hub/internal/logger"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+	"github.com/azhengyongqin/asynq-hub/internal/server/dto"
+)
+
+const (
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+)
+
+// csvTaskHeader 导出 CSV 的任务列
+var csvTaskHeader = []string{
+	"task_id", "worker_name", "queue", "priority", "status", "last_attempt", "last_error",
+	"labels", "payload", "created_at", "updated_at",
+}
+
+// csvAttemptHeader include_attempts 时追加的执行尝试列（每次尝试一行）
+var csvAttemptHeader = []string{
+	"attempt", "attempt_status", "attempt_started_at", "attempt_finished_at", "attempt_duration_ms", "attempt_error",
+}
+
+// exportedTask NDJSON 导出的一行
+type exportedTask struct {
+	repository.Task
+	Attempts []repository.Attempt `json:"attempts,omitempty"`
+}
+
+// ExportTasks godoc
+// @Summary 导出任务
+// @Description 按与任务列表相同的过滤条件流式导出全部匹配任务（不分页），可附带每个任务的执行尝试。
+// @Description CSV 附带执行尝试时每次尝试输出一行；NDJSON 每行一个任务，执行尝试位于 attempts 字段。
+// @Tags Tasks
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "导出格式：csv, ndjson" default(csv)
+// @Param include_attempts query bool false "附带执行尝试" default(false)
+// @Param worker_name query string false "Worker 名称"
+// @Param status query string false "任务状态"
+// @Param queue query string false "队列名称"
+// @Param label query []string false "标签选择器，格式 key=value，可重复（需同时满足）" collectionFormat(multi)
+// @Param priority query string false "优先级：critical, default, low"
+// @Param created_after query string false "创建时间下限（含，RFC3339）"
+// @Param created_before query string false "创建时间上限（不含，RFC3339）"
+// @Param updated_after query string false "更新时间下限（含，RFC3339）"
+// @Param updated_before query string false "更新时间上限（不含，RFC3339）"
+// @Param error query string false "last_error 子串（不区分大小写）"
+// @Param payload query string false "payload 需包含的 JSON 对象（JSONB 包含查询）"
+// @Param payload_path query string false "payload 需满足的 jsonpath 谓词，如 $.amount > 100（只支持谓词表达式，语法错误返回 400；$.a ? (@ == 1) 这类路径表达式不会匹配任何任务）"
+// @Param sort_by query string false "排序字段：created_at, updated_at, priority" default(created_at)
+// @Param order query string false "排序方向：asc, desc" default(desc)
+// @Success 200 {string} string "CSV 或 NDJSON 数据流"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 501 {object} dto.ErrorResponse
+// @Router /tasks/export [get]
+func (h *TaskHandler) ExportTasks(c *gin.Context) {
+	if h.taskRepo == nil {
+		c.JSON(http.StatusNotImplemented, dto.ErrorResponse{Error: "Postgres 未配置"})
+		return
+	}
+
+	format := c.DefaultQuery("format", exportFormatCSV)
+	if format != exportFormatCSV && format != exportFormatNDJSON {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "format 必须是 csv 或 ndjson"})
+		return
+	}
+	includeAttempts, _ := strconv.ParseBool(c.DefaultQuery("include_attempts", "false"))
+	filter, err := parseTaskFilter(c, h.taskRepo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == exportFormatNDJSON {
+		contentType = "application/x-ndjson"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="tasks-%s.%s"`, time.Now().UTC().Format("20060102T150405Z"), format))
+	c.Status(http.StatusOK)
+
+	var write func(tasks []repository.Task, attempts map[string][]repository.Attempt) error
+	if format == exportFormatCSV {
+		write = newCSVTaskWriter(c.Writer, includeAttempts)
+	} else {
+		write = newNDJSONTaskWriter(c.Writer)
+	}
+
+	rows := 0
+	err = h.taskRepo.ExportTasks(c.Request.Context(), filter, includeAttempts, func(tasks []repository.Task, attempts map[string][]repository.Attempt) error {
+		if err := write(tasks, attempts); err != nil {
+			return err
+		}
+		rows += len(tasks)
+		c.Writer.Flush()
+		return nil
+	})
+	if err != nil {
+		// 响应头已发送，只能中断输出：NDJSON 追加一行错误，CSV 由客户端根据不完整的数据流判断
+		logger.L.Error().Err(err).Int("exported", rows).Str("format", format).Msg("导出任务失败")
+		if format == exportFormatNDJSON {
+			_ = json.NewEncoder(c.Writer).Encode(dto.ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+	logger.L.Info().Int("exported", rows).Str("format", format).Msg("导出任务完成")
+}
+
+// newCSVTaskWriter 返回按批写入 CSV 的函数（首次写入时输出表头）
+func newCSVTaskWriter(w io.Writer, includeAttempts bool) func([]repository.Task, map[string][]repository.Attempt) error {
+	cw := csv.NewWriter(w)
+	headerWritten := false
+	return func(tasks []repository.Task, attempts map[string][]repository.Attempt) error {
+		if !headerWritten {
+			header := csvTaskHeader
+			if includeAttempts {
+				header = append(append([]string{}, csvTaskHeader...), csvAttemptHeader...)
+			}
+			if err := cw.Write(header); err != nil {
+				return err
+			}
+			headerWritten = true
+		}
+
+		for _, t := range tasks {
+			record := csvTaskRecord(t)
+			if !includeAttempts {
+				if err := cw.Write(record); err != nil {
+					return err
+				}
+				continue
+			}
+			taskAttempts := attempts[t.TaskID]
+			if len(taskAttempts) == 0 {
+				// 没有执行尝试的任务也输出一行
+				if err := cw.Write(append(record, make([]string, len(csvAttemptHeader))...)); err != nil {
+					return err
+				}
+				continue
+			}
+			for _, a := range taskAttempts {
+				if err := cw.Write(append(append([]string{}, record...), csvAttemptRecord(a)...)); err != nil {
+					return err
+				}
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+}
+
+func csvTaskRecord(t repository.Task) []string {
+	labels := ""
+	if len(t.Labels) > 0 {
+		b, _ := json.Marshal(t.Labels)
+		labels = string(b)
+	}
+	return []string{
+		t.TaskID, t.WorkerName, t.Queue, strconv.Itoa(t.Priority), t.Status, strconv.Itoa(t.LastAttempt), t.LastError,
+		labels, string(t.Payload), t.CreatedAt.Format(time.RFC3339), t.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func csvAttemptRecord(a repository.Attempt) []string {
+	finished, duration := "", ""
+	if a.FinishedAt != nil {
+		finished = a.FinishedAt.Format(time.RFC3339)
+	}
+	if a.DurationMs != nil {
+		duration = strconv.Itoa(*a.DurationMs)
+	}
+	return []string{strconv.Itoa(a.Attempt), a.Status, a.StartedAt.Format(time.RFC3339), finished, duration, a.Error}
+}
+
+// newNDJSONTaskWriter 返回按批写入 NDJSON 的函数（每行一个任务）
+func newNDJSONTaskWriter(w io.Writer) func([]repository.Task, map[string][]repository.Attempt) error {
+	enc := json.NewEncoder(w)
+	return func(tasks []repository.Task, attempts map[string][]repository.Attempt) error {
+		for _, t := range tasks {
+			if err := enc.Encode(exportedTask{Task: t, Attempts: attempts[t.TaskID]}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
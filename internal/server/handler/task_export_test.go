@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+)
+
+// exportTaskRepo 分两批返回固定任务的导出仓储
+type exportTaskRepo struct {
+	repository.TaskRepository
+
+	tasks    []repository.Task
+	attempts map[string][]repository.Attempt
+}
+
+func (r *exportTaskRepo) ExportTasks(_ context.Context, _ repository.ListTasksFilter, includeAttempts bool, fn func([]repository.Task, map[string][]repository.Attempt) error) error {
+	for i := range r.tasks {
+		var attempts map[string][]repository.Attempt
+		if includeAttempts {
+			attempts = r.attempts
+		}
+		if err := fn(r.tasks[i:i+1], attempts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func exportTasks(t *testing.T, repo repository.TaskRepository, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/?"+query, nil)
+
+	h := NewTaskHandler(nil, nil, repo, nil, nil, nil)
+	h.ExportTasks(c)
+	return w
+}
+
+func TestExportTasks(t *testing.T) {
+	started := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	duration := 1500
+	a := testTask("a", "success")
+	a.Labels = map[string]string{"env": "prod"}
+	repo := &exportTaskRepo{
+		tasks: []repository.Task{a, testTask("b", "pending")},
+		attempts: map[string][]repository.Attempt{
+			"a": {
+				{TaskID: "a", Attempt: 1, Status: "fail", StartedAt: started, Error: "boom"},
+				{TaskID: "a", Attempt: 2, Status: "success", StartedAt: started, DurationMs: &duration},
+			},
+		},
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		w := exportTasks(t, repo, "format=csv")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Disposition"), ".csv")
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, csvTaskHeader, records[0])
+		assert.Equal(t, "a", records[1][0])
+		assert.Equal(t, `{"env":"prod"}`, records[1][7])
+	})
+
+	t.Run("csv 附带执行尝试", func(t *testing.T) {
+		w := exportTasks(t, repo, "format=csv&include_attempts=true")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 4, "a 两次尝试各一行，b 无尝试一行")
+		assert.Len(t, records[0], len(csvTaskHeader)+len(csvAttemptHeader))
+		assert.Equal(t, "boom", records[1][len(records[1])-1])
+		assert.Equal(t, "1500", records[2][len(records[2])-2])
+		assert.Equal(t, "", records[3][len(csvTaskHeader)])
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		w := exportTasks(t, repo, "format=ndjson&include_attempts=true")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		require.Len(t, lines, 2)
+		var first exportedTask
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+		assert.Equal(t, "a", first.TaskID)
+		assert.Len(t, first.Attempts, 2)
+		assert.NotContains(t, lines[1], `"attempts"`)
+	})
+
+	t.Run("非法格式", func(t *testing.T) {
+		w := exportTasks(t, repo, "format=xml")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -1,9 +1,14 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -18,33 +23,103 @@ import (
 	workers "github.com/azhengyongqin/asynq-hub/internal/worker"
 )
 
+const (
+	// cancelWaitTimeout 取消 active 任务后等待其离开 active 状态的最长时间。
+	// 超时未能删除的任务被 asynq 重试时，worker 上报 running 会收到 409 并撤销执行。
+	cancelWaitTimeout = 30 * time.Second
+
+	// batchEnqueueConcurrency 批量创建与导入时并发入队的协程数。
+	// asynq.Client 不提供批量入队接口，也不暴露底层 Redis 连接：每次 Enqueue 都执行一段 Lua 脚本，
+	// 原子地完成 task_id 冲突检查与写入。自行 pipeline 需要复刻 asynq 内部的键布局与脚本，随版本升级容易失效，
+	// 因此以有限并发复用其连接池（默认 10×GOMAXPROCS 个连接）让往返时间重叠，效果接近 pipeline，
+	// 16 个协程既能摊薄单次往返延迟，又不会占满连接池影响其他请求。
+	batchEnqueueConcurrency = 16
+)
+
+// taskEnqueuer 任务入队接口（*asynq.Client 满足该接口）
+type taskEnqueuer interface {
+	Enqueue(task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error)
+}
+
+// taskInspector 查询、删除与取消 asynq 任务的接口（*asynq.Inspector 满足该接口）
+type taskInspector interface {
+	GetTaskInfo(queue, id string) (*asynq.TaskInfo, error)
+	DeleteTask(queue, id string) error
+	CancelProcessing(id string) error
+}
+
 // TaskHandler Task 相关 API Handler
 type TaskHandler struct {
-	asynqClient *asynq.Client
-	taskRepo    repository.TaskRepository
-	workerRepo  repository.WorkerRepository
-	workerStore *workers.Store
+	asynqClient  taskEnqueuer
+	inspector    taskInspector
+	taskRepo     repository.TaskRepository
+	workerRepo   repository.WorkerRepository
+	workflowRepo repository.WorkflowRepository
+	workerStore  *workers.Store
+
+	// outbox 出箱模式：创建/重放/批量重试只在同一事务中写入任务与出箱记录，由 outbox.Relay 入队
+	outbox bool
+
+	// 后台协程（如取消后的清理）使用的上下文，Close 时取消并等待其退出
+	bgCtx    context.Context
+	bgCancel context.CancelFunc
+	bgWG     sync.WaitGroup
 }
 
 // NewTaskHandler 创建 TaskHandler
-func NewTaskHandler(asynqClient *asynq.Client, taskRepo repository.TaskRepository, workerRepo repository.WorkerRepository, workerStore *workers.Store) *TaskHandler {
-	return &TaskHandler{
-		asynqClient: asynqClient,
-		taskRepo:    taskRepo,
-		workerRepo:  workerRepo,
-		workerStore: workerStore,
+func NewTaskHandler(asynqClient *asynq.Client, inspector *asynq.Inspector, taskRepo repository.TaskRepository, workerRepo repository.WorkerRepository, workflowRepo repository.WorkflowRepository, workerStore *workers.Store) *TaskHandler {
+	bgCtx, bgCancel := context.WithCancel(context.Background())
+	h := &TaskHandler{
+		bgCtx:        bgCtx,
+		bgCancel:     bgCancel,
+		taskRepo:     taskRepo,
+		workerRepo:   workerRepo,
+		workflowRepo: workflowRepo,
+		workerStore:  workerStore,
+	}
+	// 避免 nil 指针被包装成非 nil 接口，使 asynqClient/inspector == nil 的检查失效
+	if asynqClient != nil {
+		h.asynqClient = asynqClient
 	}
+	if inspector != nil {
+		h.inspector = inspector
+	}
+	return h
+}
+
+// SetOutboxEnabled 启用出箱模式（需要配置 Postgres）：创建、重放与批量重试的任务不再直接入队，
+// 而是与出箱记录在同一事务中写入数据库，由 outbox.Relay 异步入队，保证被执行的任务一定有任务记录
+func (h *TaskHandler) SetOutboxEnabled(enabled bool) {
+	h.outbox = enabled && h.taskRepo != nil
+}
+
+// Close 停止后台协程并等待其退出（在 HTTP 服务关闭后调用）
+func (h *TaskHandler) Close() {
+	h.bgCancel()
+	h.bgWG.Wait()
+}
+
+// goBackground 启动一个随 Close 退出的后台协程
+func (h *TaskHandler) goBackground(fn func(ctx context.Context)) {
+	h.bgWG.Add(1)
+	go func() {
+		defer h.bgWG.Done()
+		fn(h.bgCtx)
+	}()
 }
 
 // CreateTask godoc
 // @Summary 创建任务
 // @Description 创建新的异步任务并入队到 Asynq
+// @Description 出箱模式（OUTBOX_ENABLED=true）下任务记录与出箱记录在同一事务中写入后即返回（status 为 accepted），由后台投递协程入队；task_id 已有任务记录时返回 409。
 // @Tags Tasks
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "幂等键：窗口内重复提交直接返回首次响应"
 // @Param request body dto.CreateTaskRequest true "任务创建请求"
 // @Success 200 {object} dto.CreateTaskResponse
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
 // @Failure 503 {object} dto.ErrorResponse
 // @Router /tasks [post]
 func (h *TaskHandler) CreateTask(c *gin.Context) {
@@ -53,99 +128,38 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		return
 	}
 
-	var req struct {
-		WorkerName   string          `json:"worker_name" binding:"required"`
-		Queue        string          `json:"queue" binding:"required"` // 队列组名称
-		Priority     string          `json:"priority"`                 // 优先级：critical, default, low（默认 default）
-		TaskID       string          `json:"task_id"`                  // 可选，默认生成
-		Payload      json.RawMessage `json:"payload" binding:"required"`
-		DelaySeconds int32           `json:"delay_seconds"`
-		RunAt        *time.Time      `json:"run_at"`
-	}
+	var req dto.CreateTaskRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	// 默认优先级为 default
-	if req.Priority == "" {
-		req.Priority = workers.PriorityDefault
-	}
-
-	// 验证优先级
-	if req.Priority != workers.PriorityCritical && req.Priority != workers.PriorityDefault && req.Priority != workers.PriorityLow {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "priority 必须是 critical, default 或 low"})
-		return
-	}
-
-	// 验证 worker_name 格式
-	if !middleware.ValidateWorkerName(req.WorkerName) {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "worker_name 格式无效"})
-		return
-	}
-
-	// 验证 queue 格式
-	if !middleware.ValidateQueueName(req.Queue) {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "queue 格式无效"})
-		return
-	}
-
-	// 验证 task_id 格式（如果提供）
-	if req.TaskID != "" && !middleware.ValidateTaskID(req.TaskID) {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "task_id 格式无效"})
-		return
-	}
-
-	// 验证 payload 大小
-	if len(req.Payload) > middleware.MaxPayloadSize {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "payload 过大，最大 2MB"})
+	resp, status, err := h.createTask(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(status, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
+	c.JSON(http.StatusOK, resp)
+}
 
-	// 验证 worker 是否存在
-	workerCfg, ok := h.workerStore.Get(req.WorkerName)
-	if !ok {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "worker 不存在"})
-		return
-	}
-	if !workerCfg.IsEnabled {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "worker 未启用"})
-		return
+// createTask 校验并入队单个任务，返回响应及失败时对应的 HTTP 状态码
+// （CreateTask 与周期任务触发共用）
+func (h *TaskHandler) createTask(ctx context.Context, req dto.CreateTaskRequest) (*dto.CreateTaskResponse, int, error) {
+	if err := validateCreateTaskRequest(&req); err != nil {
+		return nil, http.StatusBadRequest, err
 	}
 
-	// 验证队列组是否存在
-	if !h.workerStore.HasQueue(req.WorkerName, req.Queue) {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "队列组不存在于 worker 配置中"})
-		return
+	workerCfg, err := h.resolveTaskTarget(req.WorkerName, req.Queue, req.Priority)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
 	}
-
-	// 验证队列组的优先级是否存在
-	if !h.workerStore.HasQueueWithPriority(req.WorkerName, req.Queue, req.Priority) {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "优先级不存在于队列组配置中"})
-		return
+	if err := h.resolveCallbackTargets(req); err != nil {
+		return nil, http.StatusBadRequest, err
 	}
 
-	// 确保 worker 在数据库中存在（因为 task 有外键约束）
-	if h.workerRepo != nil {
-		if _, err := h.workerRepo.Get(c.Request.Context(), req.WorkerName); err != nil {
-			// Worker 不在数据库中，需要先创建
-			repoConfig := repository.WorkerConfig{
-				WorkerName:        workerCfg.WorkerName,
-				BaseURL:           workerCfg.BaseURL,
-				RedisAddr:         workerCfg.RedisAddr,
-				QueueGroups:       convertWorkerQueueGroups(workerCfg.QueueGroups),
-				DefaultRetryCount: workerCfg.DefaultRetryCount,
-				DefaultTimeout:    workerCfg.DefaultTimeout,
-				DefaultDelay:      workerCfg.DefaultDelay,
-				IsEnabled:         workerCfg.IsEnabled,
-				LastHeartbeatAt:   workerCfg.LastHeartbeatAt,
-			}
-			if err := h.workerRepo.Upsert(c.Request.Context(), repoConfig); err != nil {
-				logger.L.Error().Err(err).Str("worker_name", req.WorkerName).Msg("创建 worker 到数据库失败")
-				c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "创建 worker 失败: " + err.Error()})
-				return
-			}
-		}
+	if err := h.ensureWorkerPersisted(ctx, workerCfg); err != nil {
+		logger.L.Error().Err(err).Str("worker_name", req.WorkerName).Msg("创建 worker 到数据库失败")
+		return nil, http.StatusInternalServerError, fmt.Errorf("创建 worker 失败: %w", err)
 	}
 
 	// 生成或使用提供的 task_id
@@ -154,60 +168,23 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		taskID = asynqx.NewTaskID()
 	}
 
-	// 构造完整队列名：workerName:queueGroupName:priority
-	fullQueue := workerCfg.FullQueueName(req.Queue, req.Priority)
-
-	// 构造 task payload
-	var msg = struct {
-		ID      string          `json:"id,omitempty"`
-		TaskID  string          `json:"task_id,omitempty"`
-		Payload json.RawMessage `json:"payload"`
-	}{
-		ID:      taskID,
-		TaskID:  taskID,
-		Payload: req.Payload,
+	if h.outbox {
+		return h.createTaskWithOutbox(ctx, req, workerCfg, taskID)
 	}
-	b, _ := json.Marshal(msg)
 
 	// 入队到 asynq
-	t := asynq.NewTask(fullQueue, b)
-	p := asynqx.EnqueueParams{
-		TaskType:       fullQueue,
-		TaskKey:        taskID,
-		Queue:          fullQueue,
-		MaxRetry:       workerCfg.DefaultRetryCount,
-		TimeoutSeconds: workerCfg.DefaultTimeout,
-		DelaySeconds:   req.DelaySeconds,
-		Payload:        req.Payload,
-	}
-	if req.RunAt != nil {
-		p.RunAt = *req.RunAt
-	}
-
-	info, err := h.asynqClient.Enqueue(t, asynqx.EnqueueOptions(p)...)
+	fullQueue, info, err := h.enqueueCreateTask(workerCfg, req, taskID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
-		return
+		// 相同 task_id 的任务仍在 asynq 中（或在唯一性窗口内），属于重复提交而非服务端错误
+		if errors.Is(err, asynq.ErrTaskIDConflict) || errors.Is(err, asynq.ErrDuplicateTask) {
+			return nil, http.StatusConflict, fmt.Errorf("task_id 已存在: %s", taskID)
+		}
+		return nil, http.StatusInternalServerError, err
 	}
 
 	// 记录到数据库
 	if h.taskRepo != nil {
-		// 确保 payload 不为 null
-		payload := req.Payload
-		if payload == nil || string(payload) == "null" {
-			payload = []byte("{}")
-		}
-
-		task := repository.Task{
-			TaskID:      taskID,
-			WorkerName:  req.WorkerName,
-			Queue:       fullQueue,
-			Priority:    priorityToInt(req.Priority),
-			Payload:     payload,
-			Status:      string(model.TaskStatusPending),
-			LastAttempt: 0,
-		}
-		if err := h.taskRepo.UpsertTask(c.Request.Context(), task); err != nil {
+		if err := h.taskRepo.UpsertTask(ctx, newPendingTask(req, taskID, fullQueue, info.ID)); err != nil {
 			logger.L.Error().Err(err).
 				Str("task_id", taskID).
 				Str("worker_name", req.WorkerName).
@@ -216,14 +193,207 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, dto.CreateTaskResponse{
+	return &dto.CreateTaskResponse{
 		TaskID:      taskID,
 		WorkerName:  req.WorkerName,
 		Queue:       req.Queue,
 		Priority:    req.Priority,
 		AsynqTaskID: info.ID,
 		Status:      "enqueued",
-	})
+	}, http.StatusOK, nil
+}
+
+// createTaskWithOutbox 出箱模式下创建任务：任务记录与出箱记录在同一事务中写入，由 outbox.Relay 入队。
+// asynq 任务 ID 与 task_id 相同，响应中直接返回。
+func (h *TaskHandler) createTaskWithOutbox(ctx context.Context, req dto.CreateTaskRequest, workerCfg workers.Config, taskID string) (*dto.CreateTaskResponse, int, error) {
+	fullQueue := workerCfg.FullQueueName(req.Queue, req.Priority)
+	if err := h.taskRepo.CreateTaskWithOutbox(ctx, newPendingTask(req, taskID, fullQueue, taskID), processAt(req, time.Now())); err != nil {
+		if errors.Is(err, repository.ErrTaskExists) {
+			return nil, http.StatusConflict, fmt.Errorf("task_id 已存在: %s", taskID)
+		}
+		logger.L.Error().Err(err).Str("task_id", taskID).Str("queue", fullQueue).Msg("保存任务及出箱记录失败")
+		return nil, http.StatusInternalServerError, fmt.Errorf("保存任务失败: %w", err)
+	}
+
+	return &dto.CreateTaskResponse{
+		TaskID:      taskID,
+		WorkerName:  req.WorkerName,
+		Queue:       req.Queue,
+		Priority:    req.Priority,
+		AsynqTaskID: taskID,
+		Status:      "accepted",
+	}, http.StatusOK, nil
+}
+
+// BatchCreateTasks godoc
+// @Summary 批量创建任务
+// @Description 一次提交多个任务，逐条返回结果；worker/队列组只校验一次，数据库记录单次批量写入
+// @Description 出箱模式下任务记录与出箱记录在同一事务中写入，由后台投递协程入队；task_id 已有任务记录的条目返回错误。
+// @Tags Tasks
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "幂等键：窗口内重复提交直接返回首次响应"
+// @Param request body dto.BatchCreateTaskRequest true "批量创建请求"
+// @Success 200 {object} dto.BatchCreateTaskResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Router /tasks/batch [post]
+func (h *TaskHandler) BatchCreateTasks(c *gin.Context) {
+	if h.asynqClient == nil {
+		c.JSON(http.StatusServiceUnavailable, dto.ErrorResponse{Error: "asynq client 未配置"})
+		return
+	}
+
+	var req dto.BatchCreateTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if len(req.Items) > dto.MaxBatchCreateItems {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: fmt.Sprintf("items 数量超过上限 %d", dto.MaxBatchCreateItems)})
+		return
+	}
+
+	ctx := c.Request.Context()
+	results := make([]dto.BatchCreateTaskResult, len(req.Items))
+
+	targets := newBatchTargets(h)
+	seen := make(map[string]struct{}, len(req.Items))
+
+	type job struct {
+		index     int
+		item      dto.CreateTaskRequest
+		workerCfg workers.Config
+	}
+	jobs := make([]job, 0, len(req.Items))
+
+	for i := range req.Items {
+		item := req.Items[i]
+		results[i].Index = i
+
+		if err := validateCreateTaskRequest(&item); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+
+		workerCfg, err := targets.resolve(ctx, item)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+
+		if item.TaskID == "" {
+			item.TaskID = asynqx.NewTaskID()
+		}
+		if _, dup := seen[item.TaskID]; dup {
+			results[i].Error = "task_id 在批次中重复"
+			continue
+		}
+		seen[item.TaskID] = struct{}{}
+
+		results[i].TaskID = item.TaskID
+		jobs = append(jobs, job{index: i, item: item, workerCfg: workerCfg})
+	}
+
+	if h.outbox {
+		// 出箱模式：任务记录与出箱记录在同一事务中写入，由 outbox.Relay 入队
+		now := time.Now()
+		entries := make([]repository.OutboxTask, 0, len(jobs))
+		for _, j := range jobs {
+			fullQueue := j.workerCfg.FullQueueName(j.item.Queue, j.item.Priority)
+			entries = append(entries, repository.OutboxTask{
+				Task:      newPendingTask(j.item, j.item.TaskID, fullQueue, j.item.TaskID),
+				ProcessAt: processAt(j.item, now),
+			})
+		}
+		existing, err := h.taskRepo.CreateTasksWithOutbox(ctx, entries)
+		if err != nil {
+			logger.L.Error().Err(err).Int("count", len(entries)).Msg("批量保存任务及出箱记录失败")
+		}
+		for _, j := range jobs {
+			_, dup := existing[j.item.TaskID]
+			switch {
+			case err != nil:
+				results[j.index].Error = "保存任务失败: " + err.Error()
+			case dup:
+				results[j.index].Error = "task_id 已存在: " + j.item.TaskID
+			default:
+				results[j.index].AsynqTaskID = j.item.TaskID
+			}
+		}
+		c.JSON(http.StatusOK, newBatchCreateTaskResponse(results))
+		return
+	}
+
+	// 以有限并发入队（见 batchEnqueueConcurrency）
+	records := make([]*repository.Task, len(req.Items))
+	sem := make(chan struct{}, batchEnqueueConcurrency)
+	var wg sync.WaitGroup
+	for _, j := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(j job) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			fullQueue, info, err := h.enqueueCreateTask(j.workerCfg, j.item, j.item.TaskID)
+			if err != nil {
+				results[j.index].Error = err.Error()
+				return
+			}
+			results[j.index].AsynqTaskID = info.ID
+			record := newPendingTask(j.item, j.item.TaskID, fullQueue, info.ID)
+			records[j.index] = &record
+		}(j)
+	}
+	wg.Wait()
+
+	// 单次多行写入数据库
+	tasks := make([]repository.Task, 0, len(jobs))
+	for _, r := range records {
+		if r != nil {
+			tasks = append(tasks, *r)
+		}
+	}
+	if h.taskRepo != nil && len(tasks) > 0 {
+		if err := h.taskRepo.UpsertTasks(ctx, tasks); err != nil {
+			// 没有 task 记录的任务后续上报都会 404：撤回已入队的任务并将这些条目标记为失败
+			logger.L.Error().Err(err).Int("count", len(tasks)).Msg("批量保存任务到数据库失败")
+			for i, r := range records {
+				if r == nil {
+					continue
+				}
+				if h.inspector != nil {
+					if derr := h.inspector.DeleteTask(r.Queue, r.AsynqTaskID); derr != nil && !errors.Is(derr, asynq.ErrTaskNotFound) {
+						logger.L.Warn().Err(derr).Str("task_id", r.TaskID).Msg("撤回未落库的任务失败")
+					}
+				}
+				results[i].AsynqTaskID = ""
+				results[i].Error = "保存任务失败: " + err.Error()
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, newBatchCreateTaskResponse(results))
+}
+
+// newBatchCreateTaskResponse 汇总逐条结果
+func newBatchCreateTaskResponse(results []dto.BatchCreateTaskResult) dto.BatchCreateTaskResponse {
+	resp := dto.BatchCreateTaskResponse{
+		Total: len(results),
+		Items: results,
+	}
+	for _, r := range results {
+		if r.Error == "" {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+		}
+	}
+	return resp
 }
 
 // priorityToInt 将优先级字符串转换为整数（用于数据库存储）
@@ -242,15 +412,30 @@ func priorityToInt(priority string) int {
 
 // ListTasks godoc
 // @Summary 查询任务列表
-// @Description 分页查询任务列表，支持多条件过滤
+// @Description 查询任务列表，支持按时间范围、优先级、标签、错误信息、payload 过滤及排序。
+// @Description 按 created_at 排序时使用 (created_at, id) 游标分页：响应中的 next_cursor/prev_cursor 作为 cursor 参数翻页；传 offset 时使用偏移分页。
 // @Tags Tasks
 // @Produce json
 // @Param worker_name query string false "Worker 名称"
 // @Param status query string false "任务状态"
 // @Param queue query string false "队列名称"
+// @Param label query []string false "标签选择器，格式 key=value，可重复（需同时满足）" collectionFormat(multi)
+// @Param priority query string false "优先级：critical, default, low"
+// @Param created_after query string false "创建时间下限（含，RFC3339）"
+// @Param created_before query string false "创建时间上限（不含，RFC3339）"
+// @Param updated_after query string false "更新时间下限（含，RFC3339）"
+// @Param updated_before query string false "更新时间上限（不含，RFC3339）"
+// @Param error query string false "last_error 子串（不区分大小写）"
+// @Param payload query string false "payload 需包含的 JSON 对象（JSONB 包含查询）"
+// @Param payload_path query string false "payload 需满足的 jsonpath 谓词，如 $.amount > 100（只支持谓词表达式，语法错误返回 400；$.a ? (@ == 1) 这类路径表达式不会匹配任何任务）"
+// @Param sort_by query string false "排序字段：created_at, updated_at, priority" default(created_at)
+// @Param order query string false "排序方向：asc, desc" default(desc)
+// @Param cursor query string false "分页游标（上一次响应的 next_cursor 或 prev_cursor）"
+// @Param total query string false "总数：exact（精确计数）, estimate（按查询计划估算）, none（不返回）" default(exact)
 // @Param limit query int false "每页数量" default(50)
-// @Param offset query int false "偏移量" default(0)
+// @Param offset query int false "偏移量（与 cursor 互斥）" default(0)
 // @Success 200 {object} dto.TaskListResponse
+// @Failure 400 {object} dto.ErrorResponse
 // @Failure 501 {object} dto.ErrorResponse
 // @Router /tasks [get]
 func (h *TaskHandler) ListTasks(c *gin.Context) {
@@ -259,34 +444,119 @@ func (h *TaskHandler) ListTasks(c *gin.Context) {
 		return
 	}
 
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
-	items, err := h.taskRepo.ListTasks(c.Request.Context(), repository.ListTasksFilter{
-		WorkerName: c.DefaultQuery("worker_name", ""),
-		Status:     c.DefaultQuery("status", ""),
-		Queue:      c.DefaultQuery("queue", ""),
-		Limit:      limit,
-		Offset:     offset,
-	})
+	filter, err := parseTaskFilter(c, h.taskRepo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	filter.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "50"))
+	filter.Offset, _ = strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	totalMode := c.DefaultQuery("total", totalExact)
+	if totalMode != totalExact && totalMode != totalEstimate && totalMode != totalNone {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "total 必须是 exact, estimate 或 none"})
+		return
+	}
+
+	cursor, err := parseCursor(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	keyset := filter.SortBy == "" || filter.SortBy == "created_at"
+	if cursor != nil && (!keyset || filter.Offset > 0) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "cursor 只能在按 created_at 排序且不传 offset 时使用"})
+		return
+	}
+
+	var resp dto.TaskListResponse
+	if keyset && filter.Offset == 0 {
+		page, err := h.taskRepo.ListTasksPage(c.Request.Context(), filter, cursor)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+			return
+		}
+		resp = dto.TaskListResponse{Items: page.Items, NextCursor: page.NextCursor, PrevCursor: page.PrevCursor}
+	} else {
+		items, err := h.taskRepo.ListTasks(c.Request.Context(), filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+			return
+		}
+		resp = dto.TaskListResponse{Items: items}
+	}
+
+	var total int
+	switch totalMode {
+	case totalExact:
+		total, err = h.taskRepo.CountTasks(c.Request.Context(), filter)
+	case totalEstimate:
+		total, err = h.taskRepo.EstimateTasks(c.Request.Context(), filter)
+		resp.TotalEstimated = true
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
-	total, err := h.taskRepo.CountTasks(c.Request.Context(), repository.ListTasksFilter{
-		WorkerName: c.DefaultQuery("worker_name", ""),
-		Status:     c.DefaultQuery("status", ""),
-		Queue:      c.DefaultQuery("queue", ""),
-	})
+	if totalMode != totalNone {
+		resp.Total = &total
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListAttempts godoc
+// @Summary 查询任务执行历史
+// @Description 按 (started_at, id) 游标分页查询任务的执行尝试记录（最新在前）
+// @Tags Tasks
+// @Produce json
+// @Param task_id path string true "任务 ID"
+// @Param cursor query string false "分页游标（上一次响应的 next_cursor 或 prev_cursor）"
+// @Param total query string false "总数：exact（精确计数）, none（不返回）" default(exact)
+// @Param limit query int false "每页数量（最多 200）" default(50)
+// @Success 200 {object} dto.AttemptListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 501 {object} dto.ErrorResponse
+// @Router /tasks/{task_id}/attempts [get]
+func (h *TaskHandler) ListAttempts(c *gin.Context) {
+	if h.taskRepo == nil {
+		c.JSON(http.StatusNotImplemented, dto.ErrorResponse{Error: "Postgres 未配置"})
+		return
+	}
+
+	totalMode := c.DefaultQuery("total", totalExact)
+	if totalMode != totalExact && totalMode != totalNone {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "total 必须是 exact 或 none"})
+		return
+	}
+	cursor, err := parseCursor(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	taskID := c.Param("task_id")
+	page, err := h.taskRepo.ListAttemptsPage(c.Request.Context(), taskID, limit, cursor)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, dto.TaskListResponse{Items: items, Total: total})
+	resp := dto.AttemptListResponse{Items: page.Items, NextCursor: page.NextCursor, PrevCursor: page.PrevCursor}
+
+	if totalMode == totalExact {
+		total, err := h.taskRepo.CountAttempts(c.Request.Context(), taskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+			return
+		}
+		resp.Total = &total
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 // GetTask godoc
 // @Summary 获取任务详情
-// @Description 根据 task_id 获取任务详细信息及执行历史
+// @Description 根据 task_id 获取任务详细信息、执行历史、最近一次尝试的执行进度及重放链（被重放的原任务与重放产生的任务）
 // @Tags Tasks
 // @Produce json
 // @Param task_id path string true "任务 ID"
@@ -307,12 +577,15 @@ func (h *TaskHandler) GetTask(c *gin.Context) {
 		return
 	}
 	attempts, _ := h.taskRepo.ListAttempts(c.Request.Context(), taskID, 50)
-	c.JSON(http.StatusOK, gin.H{"item": t, "attempts": attempts})
+	progress, _ := h.taskRepo.GetLatestProgress(c.Request.Context(), taskID)
+	lineage, _ := h.taskRepo.GetReplayLineage(c.Request.Context(), taskID)
+	c.JSON(http.StatusOK, gin.H{"item": t, "attempts": attempts, "progress": progress, "lineage": lineage})
 }
 
 // ReplayTask godoc
 // @Summary 重放任务
-// @Description 将已完成或失败的任务重新入队执行
+// @Description 将已完成或失败的任务以新的 task_id 重新入队执行，新任务的 replayed_from 指向原任务。
+// @Description 可修改 payload、优先级、队列组（同一 worker 内）及重试/超时参数，未传的字段沿用原任务。
 // @Tags Tasks
 // @Accept json
 // @Produce json
@@ -335,8 +608,16 @@ func (h *TaskHandler) ReplayTask(c *gin.Context) {
 
 	taskID := c.Param("task_id")
 
+	// 请求体可选
 	var req dto.ReplayTaskRequest
-	_ = c.ShouldBindJSON(&req)
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := validateReplayTaskRequest(req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
 
 	t, err := h.taskRepo.GetTask(c.Request.Context(), taskID)
 	if err != nil {
@@ -344,66 +625,192 @@ func (h *TaskHandler) ReplayTask(c *gin.Context) {
 		return
 	}
 
-	if t.Status != string(model.TaskStatusSuccess) && t.Status != string(model.TaskStatusFail) {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "只能重放已结束的任务（success/fail）"})
+	if status := model.TaskStatus(t.Status); !status.IsTerminal() && status != model.TaskStatusFail {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "只能重放已结束的任务（success/fail/dead/canceled/skipped/expired/deleted/lost）"})
 		return
 	}
 
-	workerCfg, ok := h.workerStore.Get(t.WorkerName)
+	// 目标队列组与优先级：未修改时沿用原任务所在队列
+	_, queueGroup, priority, ok := workers.ParseFullQueueName(t.Queue)
 	if !ok {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "worker 不存在"})
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "原任务队列名无效: " + t.Queue})
+		return
+	}
+	if req.Queue != "" {
+		queueGroup = req.Queue
+	}
+	if req.Priority != "" {
+		priority = req.Priority
+	}
+	workerCfg, err := h.resolveTaskTarget(t.WorkerName, queueGroup, priority)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
 
 	newTaskID := asynqx.NewTaskID()
+	newTask := newRequeuedTask(*t, newTaskID)
+	newTask.Queue = workerCfg.FullQueueName(queueGroup, priority)
+	newTask.Priority = priorityToInt(priority)
+	if len(req.Payload) > 0 && string(req.Payload) != "null" {
+		newTask.Payload = req.Payload
+	}
+	if req.MaxRetry != nil {
+		newTask.MaxRetry = req.MaxRetry
+	}
+	if req.TimeoutSeconds != nil {
+		newTask.TimeoutSeconds = req.TimeoutSeconds
+	}
 
-	var msg = struct {
-		ID      string          `json:"id,omitempty"`
-		TaskID  string          `json:"task_id,omitempty"`
-		Payload json.RawMessage `json:"payload"`
-	}{
-		ID:      newTaskID,
-		TaskID:  newTaskID,
-		Payload: t.Payload,
-	}
-	b, _ := json.Marshal(msg)
-
-	task := asynq.NewTask(t.Queue, b)
-	p := asynqx.EnqueueParams{
-		TaskType:       t.Queue,
-		TaskKey:        newTaskID,
-		Queue:          t.Queue,
-		MaxRetry:       workerCfg.DefaultRetryCount,
-		TimeoutSeconds: workerCfg.DefaultTimeout,
-		DelaySeconds:   int32(req.Delay),
-		Payload:        t.Payload,
-	}
-
-	_, err = h.asynqClient.Enqueue(task, asynqx.EnqueueOptions(p)...)
-	if err != nil {
+	if err := h.submitRequeuedTask(c.Request.Context(), workerCfg, newTask, int32(req.Delay)); err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	_ = h.taskRepo.UpsertTask(c.Request.Context(), repository.Task{
-		TaskID:      newTaskID,
-		WorkerName:  t.WorkerName,
-		Queue:       t.Queue,
-		Priority:    t.Priority,
-		Payload:     t.Payload,
-		Status:      string(model.TaskStatusPending),
-		LastAttempt: 0,
+	c.JSON(http.StatusOK, dto.ReplayTaskResponse{
+		Status:       "replayed",
+		NewTaskID:    newTaskID,
+		ReplayedFrom: taskID,
+		Queue:        newTask.Queue,
 	})
+}
 
-	c.JSON(http.StatusOK, dto.ReplayTaskResponse{
-		Status:    "replayed",
-		NewTaskID: newTaskID,
+// CancelTask godoc
+// @Summary 取消任务
+// @Description 删除 pending/scheduled/retry 状态的任务，或中断正在执行的任务，并将任务标记为 canceled
+// @Tags Tasks
+// @Accept json
+// @Produce json
+// @Param task_id path string true "任务 ID"
+// @Param request body dto.CancelTaskRequest false "取消参数"
+// @Success 200 {object} dto.CancelTaskResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Router /tasks/{task_id}/cancel [post]
+func (h *TaskHandler) CancelTask(c *gin.Context) {
+	if h.inspector == nil {
+		c.JSON(http.StatusServiceUnavailable, dto.ErrorResponse{Error: "asynq inspector 未配置"})
+		return
+	}
+	if h.taskRepo == nil {
+		c.JSON(http.StatusNotImplemented, dto.ErrorResponse{Error: "Postgres 未配置"})
+		return
+	}
+
+	taskID := c.Param("task_id")
+
+	var req dto.CancelTaskRequest
+	_ = c.ShouldBindJSON(&req)
+
+	t, err := h.taskRepo.GetTask(c.Request.Context(), taskID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "task 不存在"})
+		return
+	}
+
+	if model.TaskStatus(t.Status).IsTerminal() {
+		c.JSON(http.StatusConflict, dto.ErrorResponse{Error: "任务已结束，无法取消"})
+		return
+	}
+
+	redisState, err := h.cancelTask(c.Request.Context(), t, req.Reason)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errTaskCompleted) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.CancelTaskResponse{
+		Status:     string(model.TaskStatusCanceled),
+		TaskID:     taskID,
+		RedisState: redisState,
 	})
 }
 
+// errTaskCompleted 取消时任务已在 Redis 中执行完成
+var errTaskCompleted = errors.New("任务已执行完成，无法取消")
+
+// cancelTask 删除 Redis 中等待执行的任务或中断正在执行的任务，并将未结束的任务 t 标记为 canceled，
+// 返回任务在 Redis 中的状态（已不存在时为 not_found）。CancelTask 与批量取消共用。
+func (h *TaskHandler) cancelTask(ctx context.Context, t *repository.Task, reason string) (string, error) {
+	asynqID := t.AsynqID()
+
+	redisState := "not_found"
+	info, err := h.inspector.GetTaskInfo(t.Queue, asynqID)
+	switch {
+	case errors.Is(err, asynq.ErrTaskNotFound), errors.Is(err, asynq.ErrQueueNotFound):
+		// Redis 中已不存在（例如已被 ClearQueue 删除），直接标记为取消
+	case err != nil:
+		return "", err
+	case info.State == asynq.TaskStateCompleted:
+		return "", errTaskCompleted
+	case info.State == asynq.TaskStateActive:
+		redisState = info.State.String()
+		if err := h.inspector.CancelProcessing(asynqID); err != nil {
+			return "", err
+		}
+		// 被中断的任务会被 asynq 当作失败放入 retry，需要在其离开 active 后删除
+		h.goBackground(func(ctx context.Context) { h.removeAfterCancel(ctx, t.Queue, asynqID) })
+	default:
+		redisState = info.State.String()
+		if err := h.inspector.DeleteTask(t.Queue, asynqID); err != nil && !errors.Is(err, asynq.ErrTaskNotFound) {
+			return "", err
+		}
+	}
+
+	if reason == "" {
+		reason = "canceled by api"
+	}
+	if err := h.taskRepo.UpdateTaskStatus(ctx, t.TaskID, string(model.TaskStatusCanceled), t.LastAttempt, reason, t.LastWorkerName); err != nil {
+		return "", err
+	}
+
+	// 工作流中的下游任务随之跳过
+	h.onWorkflowTaskDone(ctx, t)
+	return redisState, nil
+}
+
+// removeAfterCancel 等待被中断的任务离开 active 状态后将其从 asynq 中删除
+func (h *TaskHandler) removeAfterCancel(ctx context.Context, queue, asynqID string) {
+	ctx, cancel := context.WithTimeout(ctx, cancelWaitTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.L.Warn().Str("queue", queue).Str("asynq_task_id", asynqID).Msg("等待任务中断超时，未能从队列中删除（worker 再次执行时会被撤销）")
+			return
+		case <-ticker.C:
+		}
+
+		info, err := h.inspector.GetTaskInfo(queue, asynqID)
+		if err != nil {
+			// 任务已不存在
+			return
+		}
+		switch info.State {
+		case asynq.TaskStateActive:
+			continue
+		case asynq.TaskStateCompleted:
+			return
+		}
+		if err := h.inspector.DeleteTask(queue, asynqID); err != nil && !errors.Is(err, asynq.ErrTaskNotFound) {
+			logger.L.Error().Err(err).Str("queue", queue).Str("asynq_task_id", asynqID).Msg("删除已取消任务失败")
+		}
+		return
+	}
+}
+
 // ReportAttempt godoc
 // @Summary 上报任务执行状态
-// @Description Worker 上报任务执行的详细状态（running、success、fail）
+// @Description Worker 上报任务执行的详细状态（running、success、fail、dead、expired）。expired 表示任务到过期时间仍未开始执行，worker 已丢弃
 // @Tags Tasks
 // @Accept json
 // @Produce json
@@ -412,6 +819,7 @@ func (h *TaskHandler) ReplayTask(c *gin.Context) {
 // @Success 200 {object} dto.SuccessResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
 // @Router /tasks/{task_id}/report-attempt [post]
 func (h *TaskHandler) ReportAttempt(c *gin.Context) {
 	if h.taskRepo == nil {
@@ -440,20 +848,34 @@ func (h *TaskHandler) ReportAttempt(c *gin.Context) {
 		attemptStatus = model.TaskStatusSuccess
 	case "fail":
 		attemptStatus = model.TaskStatusFail
+	case "dead":
+		// 重试次数耗尽或 worker 判定不可恢复，任务不会再被执行
+		attemptStatus = model.TaskStatusDead
+	case "expired":
+		// 到过期时间仍未开始执行，worker 未执行处理器即丢弃
+		attemptStatus = model.TaskStatusExpired
 	default:
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "status 必须是 running/success/fail"})
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "status 必须是 running/success/fail/dead/expired"})
+		return
+	}
+
+	// 已取消的任务不再执行：取消时正在执行的任务可能被 asynq 放入重试，worker 收到 409 后撤销本次执行
+	if attemptStatus == model.TaskStatusRunning && task.Status == string(model.TaskStatusCanceled) {
+		c.JSON(http.StatusConflict, dto.ErrorResponse{Error: "任务已取消"})
 		return
 	}
 
 	attempt := repository.Attempt{
-		TaskID:     taskID,
-		Attempt:    req.Attempt,
-		Status:     string(attemptStatus),
-		StartedAt:  req.StartedAt,
-		FinishedAt: req.FinishedAt,
-		Error:      req.Error,
-		TraceID:    req.TraceID,
-		SpanID:     req.SpanID,
+		TaskID:      taskID,
+		AsynqTaskID: req.AsynqTaskID,
+		Attempt:     req.Attempt,
+		Status:      string(attemptStatus),
+		StartedAt:   req.StartedAt,
+		FinishedAt:  req.FinishedAt,
+		Error:       req.Error,
+		WorkerName:  req.WorkerName,
+		TraceID:     req.TraceID,
+		SpanID:      req.SpanID,
 	}
 
 	if err := h.taskRepo.InsertAttempt(c.Request.Context(), attempt); err != nil {
@@ -461,21 +883,69 @@ func (h *TaskHandler) ReportAttempt(c *gin.Context) {
 		return
 	}
 
-	if req.Status == "success" || req.Status == "fail" {
-		task.Status = string(attemptStatus)
-		task.LastAttempt = req.Attempt
-		if err := h.taskRepo.UpsertTask(c.Request.Context(), *task); err != nil {
+	// 开始执行：任务置为 running，失联检测据此发现未收到最终上报的执行
+	if attemptStatus == model.TaskStatusRunning {
+		if _, err := h.taskRepo.MarkTaskRunning(c.Request.Context(), taskID, req.Attempt, req.WorkerName); err != nil {
 			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
 			return
 		}
 	}
 
+	// 条件更新：已取消或已被对账/失联检测结束的任务保持原状态，迟到的上报只记录 attempt
+	if attemptStatus != model.TaskStatusRunning {
+		var result json.RawMessage
+		if attemptStatus == model.TaskStatusSuccess {
+			result = req.Result
+		}
+		applied, err := h.taskRepo.ApplyAttemptStatus(c.Request.Context(), taskID, string(attemptStatus), req.Attempt, req.Error, result)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		// 命中说明此前不是终态：首次进入终态时入队回调、推进工作流
+		if applied && attemptStatus.IsTerminal() {
+			task.Status = string(attemptStatus)
+			task.LastAttempt = req.Attempt
+			task.LastError = req.Error
+			if len(result) > 0 {
+				task.Result = result
+			}
+			h.FinishTask(c.Request.Context(), task)
+		}
+	}
+
 	c.JSON(http.StatusOK, dto.SuccessResponse{Status: "ok", Message: "上报成功"})
 }
 
+// FinishTask 任务首次进入终态后的后续处理：入队回调任务，推进工作流（入队或跳过下游任务）。
+// 同时实现 reconciler.Finisher，对账修正出的终态与 worker 上报的终态走同一条路径。
+func (h *TaskHandler) FinishTask(ctx context.Context, task *repository.Task) {
+	h.enqueueCallback(ctx, task)
+	h.onWorkflowTaskDone(ctx, task)
+}
+
+// RequeueTask 按原 task_id 将任务重新入队到 asynq，返回新的 asynq 任务 ID。
+// 用于失联检测：执行失联且已不在 Redis 中的任务（实现 watchdog.Handler）。
+func (h *TaskHandler) RequeueTask(_ context.Context, task *repository.Task) (string, error) {
+	if h.asynqClient == nil {
+		return "", errors.New("asynq client 未配置")
+	}
+	workerCfg, ok := h.workerStore.Get(task.WorkerName)
+	if !ok {
+		return "", fmt.Errorf("worker %s 不存在", task.WorkerName)
+	}
+	info, err := h.enqueueRequeuedTask(workerCfg, *task, 0)
+	if err != nil {
+		return "", err
+	}
+	return info.ID, nil
+}
+
 // BatchRetry godoc
 // @Summary 批量重试失败任务
 // @Description 批量重试指定条件的失败任务
+// @Description 已废弃：同步执行且最多处理 1000 个任务，请使用 POST /bulk-jobs（operation=retry）
 // @Tags Tasks
 // @Accept json
 // @Produce json
@@ -484,6 +954,7 @@ func (h *TaskHandler) ReportAttempt(c *gin.Context) {
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 501 {object} dto.ErrorResponse
 // @Router /tasks/batch-retry [post]
+// @Deprecated
 func (h *TaskHandler) BatchRetry(c *gin.Context) {
 	if h.asynqClient == nil {
 		c.JSON(http.StatusServiceUnavailable, dto.ErrorResponse{Error: "asynq client 未配置"})
@@ -500,6 +971,11 @@ func (h *TaskHandler) BatchRetry(c *gin.Context) {
 		return
 	}
 
+	if err := middleware.ValidateLabels(req.Labels); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	limit := req.Limit
 	if limit <= 0 || limit > 1000 {
 		limit = 100
@@ -512,6 +988,7 @@ func (h *TaskHandler) BatchRetry(c *gin.Context) {
 		filter := repository.ListTasksFilter{
 			WorkerName: req.WorkerName,
 			Status:     req.Status,
+			Labels:     req.Labels,
 			Limit:      limit,
 		}
 		tasks, err := h.taskRepo.ListTasks(c.Request.Context(), filter)
@@ -537,41 +1014,12 @@ func (h *TaskHandler) BatchRetry(c *gin.Context) {
 		}
 
 		newTaskID := asynqx.NewTaskID()
-		var msg = struct {
-			ID      string          `json:"id,omitempty"`
-			TaskID  string          `json:"task_id,omitempty"`
-			Payload json.RawMessage `json:"payload"`
-		}{
-			ID:      newTaskID,
-			TaskID:  newTaskID,
-			Payload: t.Payload,
-		}
-		b, _ := json.Marshal(msg)
-
-		task := asynq.NewTask(t.Queue, b)
-		p := asynqx.EnqueueParams{
-			TaskType:       t.Queue,
-			TaskKey:        newTaskID,
-			Queue:          t.Queue,
-			MaxRetry:       workerCfg.DefaultRetryCount,
-			TimeoutSeconds: workerCfg.DefaultTimeout,
-			Payload:        t.Payload,
-		}
-
-		if _, err := h.asynqClient.Enqueue(task, asynqx.EnqueueOptions(p)...); err != nil {
+		newTask := newRequeuedTask(*t, newTaskID)
+
+		if err := h.submitRequeuedTask(c.Request.Context(), workerCfg, newTask, 0); err != nil {
 			continue
 		}
 
-		_ = h.taskRepo.UpsertTask(c.Request.Context(), repository.Task{
-			TaskID:      newTaskID,
-			WorkerName:  t.WorkerName,
-			Queue:       t.Queue,
-			Priority:    t.Priority,
-			Payload:     t.Payload,
-			Status:      string(model.TaskStatusPending),
-			LastAttempt: 0,
-		})
-
 		newTaskIDs = append(newTaskIDs, newTaskID)
 	}
 
@@ -0,0 +1,431 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/azhengyongqin/asynq-hub/internal/model"
+	"github.com/azhengyongqin/asynq-hub/internal/server/dto"
+)
+
+func replayTask(t *testing.T, h *TaskHandler, taskID, body string) (int, dto.ReplayTaskResponse) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/", strings.NewReader(body))
+	c.Params = gin.Params{{Key: "task_id", Value: taskID}}
+
+	h.ReplayTask(c)
+
+	var resp dto.ReplayTaskResponse
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w.Code, resp
+}
+
+func TestReplayTask(t *testing.T) {
+	retry := int32(1)
+	dead := testTask("dead", "dead")
+	dead.Payload = []byte(`{"n":1}`)
+	dead.MaxRetry = &retry
+	dead.Priority = priorityToInt("default")
+	h, repo, _, enq := newTestHandler(t, dead, testTask("running", "running"))
+
+	t.Run("沿用原任务", func(t *testing.T) {
+		code, resp := replayTask(t, h, "dead", "")
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "dead", resp.ReplayedFrom)
+
+		nt := repo.tasks[resp.NewTaskID]
+		require.NotNil(t, nt)
+		assert.Equal(t, "dead", nt.ReplayedFrom)
+		assert.Equal(t, "test-worker:default:default", nt.Queue)
+		assert.JSONEq(t, `{"n":1}`, string(nt.Payload))
+		assert.Equal(t, int32(1), *nt.MaxRetry)
+	})
+
+	t.Run("修改 payload、优先级与重试参数", func(t *testing.T) {
+		code, resp := replayTask(t, h, "dead", `{"payload":{"n":2},"priority":"critical","max_retry":5,"timeout_seconds":30}`)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "test-worker:default:critical", resp.Queue)
+
+		nt := repo.tasks[resp.NewTaskID]
+		require.NotNil(t, nt)
+		assert.JSONEq(t, `{"n":2}`, string(nt.Payload))
+		assert.Equal(t, priorityToInt("critical"), nt.Priority)
+		assert.Equal(t, int32(5), *nt.MaxRetry)
+		assert.Equal(t, int32(30), *nt.TimeoutSeconds)
+		assert.JSONEq(t, `{"n":1}`, string(repo.tasks["dead"].Payload), "原任务不受影响")
+	})
+
+	before := enq.count()
+	for name, tc := range map[string]struct{ taskID, body string }{
+		"队列组不存在": {"dead", `{"queue":"missing"}`},
+		"优先级无效":  {"dead", `{"priority":"urgent"}`},
+		"重试次数越界": {"dead", `{"max_retry":1000}`},
+		"请求体无效":  {"dead", `{"payload":`},
+		"任务未结束":  {"running", ""},
+	} {
+		code, _ := replayTask(t, h, tc.taskID, tc.body)
+		assert.Equal(t, http.StatusBadRequest, code, name)
+	}
+	assert.Equal(t, before, enq.count(), "校验失败时不入队")
+}
+
+func TestResolveTaskExpiry(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	ttl := func(n int32) *int32 { return &n }
+	at := func(d time.Duration) *time.Time { t := now.Add(d); return &t }
+
+	tests := []struct {
+		name    string
+		req     dto.CreateTaskRequest
+		want    *time.Time
+		wantErr string
+	}{
+		{name: "未设置", req: dto.CreateTaskRequest{}},
+		{name: "ttl 换算为 expires_at", req: dto.CreateTaskRequest{TTLSeconds: ttl(600)}, want: at(10 * time.Minute)},
+		{name: "expires_at", req: dto.CreateTaskRequest{ExpiresAt: at(time.Hour)}, want: at(time.Hour)},
+		{name: "同时设置", req: dto.CreateTaskRequest{TTLSeconds: ttl(600), ExpiresAt: at(time.Hour)}, wantErr: "不能同时设置"},
+		{name: "ttl 非正数", req: dto.CreateTaskRequest{TTLSeconds: ttl(0)}, wantErr: "ttl_seconds"},
+		{name: "已过期", req: dto.CreateTaskRequest{ExpiresAt: at(-time.Minute)}, wantErr: "晚于计划执行时间"},
+		{name: "早于延迟执行时间", req: dto.CreateTaskRequest{TTLSeconds: ttl(60), DelaySeconds: 120}, wantErr: "晚于计划执行时间"},
+		{name: "早于 run_at", req: dto.CreateTaskRequest{ExpiresAt: at(time.Hour), RunAt: at(2 * time.Hour)}, wantErr: "晚于计划执行时间"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			err := resolveTaskExpiry(&req, now)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, req.ExpiresAt)
+			assert.Nil(t, req.TTLSeconds)
+		})
+	}
+}
+
+func TestReportAttempt_Expired(t *testing.T) {
+	h, repo, wf, _ := newTestHandler(t, workflowTask("a", "pending"), workflowTask("b", "waiting", "a"))
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/", strings.NewReader(`{"attempt":1,"status":"expired","started_at":"2026-03-10T12:00:00Z"}`))
+	c.Params = gin.Params{{Key: "task_id", Value: "a"}}
+
+	h.ReportAttempt(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, string(model.TaskStatusExpired), repo.status("a"))
+	assert.Equal(t, string(model.TaskStatusSkipped), repo.status("b"), "过期视为失败，下游任务被跳过")
+	assert.Equal(t, string(model.WorkflowStatusFailed), wf.status)
+}
+
+func TestNewRequeuedTask_DropsPastExpiry(t *testing.T) {
+	past, future := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+
+	orig := testTask("a", "expired")
+	orig.ExpiresAt = &past
+	assert.Nil(t, newRequeuedTask(orig, "a2").ExpiresAt)
+
+	orig.ExpiresAt = &future
+	assert.Equal(t, &future, newRequeuedTask(orig, "a3").ExpiresAt)
+}
+
+func TestReportAttempt_KeepsFinishedStatus(t *testing.T) {
+	for _, status := range []string{"canceled", "lost"} {
+		h, repo, _, enq := newTestHandler(t, workflowTask("a", status), workflowTask("b", "skipped", "a"))
+
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/", strings.NewReader(`{"attempt":1,"status":"success","started_at":"2026-03-12T12:00:00Z"}`))
+		c.Params = gin.Params{{Key: "task_id", Value: "a"}}
+
+		h.ReportAttempt(c)
+
+		require.Equal(t, http.StatusOK, w.Code, status)
+		assert.Equal(t, status, repo.status("a"), "迟到的上报不覆盖已结束的任务")
+		assert.Equal(t, "skipped", repo.status("b"))
+		assert.Equal(t, 0, enq.count(), "不再推进工作流")
+	}
+}
+
+func TestReportAttempt_RunningMarksTask(t *testing.T) {
+	h, repo, _, _ := newTestHandler(t, testTask("a", "fail"))
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/", strings.NewReader(`{"attempt":2,"status":"running","worker_name":"test-worker","started_at":"2026-03-12T12:00:00Z"}`))
+	c.Params = gin.Params{{Key: "task_id", Value: "a"}}
+
+	h.ReportAttempt(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, string(model.TaskStatusRunning), repo.status("a"), "重试开始执行时任务回到 running")
+	assert.Equal(t, 2, repo.tasks["a"].LastAttempt)
+	assert.Equal(t, "test-worker", repo.tasks["a"].LastWorkerName)
+}
+
+func TestCreateTask_Outbox(t *testing.T) {
+	h, repo, _, enq := newTestHandler(t, testTask("exists", "success"))
+	h.SetOutboxEnabled(true)
+
+	req := dto.CreateTaskRequest{WorkerName: "test-worker", Queue: "default", Priority: "default", TaskID: "new", Payload: json.RawMessage(`{}`)}
+	resp, code, err := h.createTask(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "accepted", resp.Status)
+	assert.Equal(t, "new", resp.AsynqTaskID)
+	assert.Equal(t, string(model.TaskStatusPending), repo.status("new"))
+	assert.Zero(t, enq.count(), "出箱模式下不直接入队")
+
+	req.TaskID = "exists"
+	_, code, err = h.createTask(context.Background(), req)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusConflict, code)
+}
+
+func batchCreateTasks(t *testing.T, h *TaskHandler, body string) dto.BatchCreateTaskResponse {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/", strings.NewReader(body))
+
+	h.BatchCreateTasks(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp dto.BatchCreateTaskResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+func TestBatchCreateTasks(t *testing.T) {
+	h, repo, _, enq := newTestHandler(t)
+
+	resp := batchCreateTasks(t, h, `{"items":[
+		{"worker_name":"test-worker","queue":"default","task_id":"t1","payload":{"n":1}},
+		{"worker_name":"test-worker","queue":"default","priority":"critical","task_id":"t2","payload":{}},
+		{"worker_name":"test-worker","queue":"default","task_id":"t1","payload":{}},
+		{"worker_name":"missing","queue":"default","task_id":"t3","payload":{}},
+		{"worker_name":"test-worker","queue":"default","priority":"urgent","task_id":"t4","payload":{}},
+		{"worker_name":"test-worker","queue":"default","payload":{}}
+	]}`)
+
+	assert.Equal(t, 6, resp.Total)
+	assert.Equal(t, 3, resp.Succeeded)
+	assert.Equal(t, 3, resp.Failed)
+	for i, item := range resp.Items {
+		assert.Equal(t, i, item.Index, "结果按请求顺序返回")
+	}
+	assert.Equal(t, "t1", resp.Items[0].TaskID)
+	assert.NotEmpty(t, resp.Items[0].AsynqTaskID)
+	assert.Empty(t, resp.Items[0].Error)
+	assert.Equal(t, "test-worker:default:critical", repo.tasks["t2"].Queue)
+	assert.Contains(t, resp.Items[2].Error, "批次中重复")
+	assert.Empty(t, resp.Items[2].AsynqTaskID)
+	assert.Contains(t, resp.Items[3].Error, "worker 不存在")
+	assert.NotEmpty(t, resp.Items[4].Error)
+	assert.NotEmpty(t, resp.Items[5].TaskID, "未提供 task_id 时自动生成")
+
+	assert.Equal(t, 3, enq.count(), "重复与无效的条目不入队")
+	assert.Len(t, repo.tasks, 3)
+	assert.Equal(t, string(model.TaskStatusPending), repo.status("t1"))
+	assert.JSONEq(t, `{"n":1}`, string(repo.tasks["t1"].Payload))
+}
+
+func TestBatchCreateTasks_SaveFailure(t *testing.T) {
+	h, repo, _, enq := newTestHandler(t)
+	repo.upsertErr = errors.New("db down")
+	insp := &fakeInspector{}
+	h.inspector = insp
+
+	resp := batchCreateTasks(t, h, `{"items":[
+		{"worker_name":"test-worker","queue":"default","task_id":"t1","payload":{}},
+		{"worker_name":"test-worker","queue":"default","task_id":"t2","payload":{}},
+		{"worker_name":"missing","queue":"default","task_id":"t3","payload":{}}
+	]}`)
+
+	assert.Equal(t, 0, resp.Succeeded)
+	assert.Equal(t, 3, resp.Failed)
+	for _, item := range resp.Items[:2] {
+		assert.Contains(t, item.Error, "保存任务失败")
+		assert.Empty(t, item.AsynqTaskID, "已撤回的任务不返回 asynq 任务 ID")
+	}
+	assert.Contains(t, resp.Items[2].Error, "worker 不存在")
+
+	assert.Equal(t, 2, enq.count())
+	assert.ElementsMatch(t, enq.enqueued, insp.deleted, "撤回所有已入队但未落库的任务")
+	assert.Empty(t, repo.tasks)
+}
+
+func TestBatchCreateTasks_Outbox(t *testing.T) {
+	h, repo, _, enq := newTestHandler(t, testTask("exists", "success"))
+	h.SetOutboxEnabled(true)
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/", strings.NewReader(`{"items":[
+		{"worker_name":"test-worker","queue":"default","task_id":"new","payload":{},"delay_seconds":60},
+		{"worker_name":"test-worker","queue":"default","task_id":"exists","payload":{}}
+	]}`))
+
+	h.BatchCreateTasks(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp dto.BatchCreateTaskResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.Succeeded)
+	assert.Equal(t, "new", resp.Items[0].AsynqTaskID)
+	assert.Contains(t, resp.Items[1].Error, "已存在")
+	assert.Equal(t, []string{"new"}, repo.outbox)
+	assert.Zero(t, enq.count(), "出箱模式下不直接入队")
+}
+
+func TestDeliverTask(t *testing.T) {
+	h, _, _, enq := newTestHandler(t)
+
+	require.NoError(t, h.DeliverTask(context.Background(), testTask("a", "pending"), nil))
+	assert.Equal(t, 1, enq.count())
+
+	require.NoError(t, h.DeliverTask(context.Background(), testTask("b", "canceled"), nil))
+	assert.Equal(t, 1, enq.count(), "投递前已取消的任务不入队")
+
+	enq.err = asynq.ErrTaskIDConflict
+	assert.NoError(t, h.DeliverTask(context.Background(), testTask("a", "pending"), nil), "任务已在 asynq 中视为投递成功")
+
+	enq.err = errors.New("redis down")
+	assert.Error(t, h.DeliverTask(context.Background(), testTask("a", "pending"), nil))
+}
+
+func cancelTask(t *testing.T, h *TaskHandler, taskID string) (int, dto.CancelTaskResponse) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/", strings.NewReader(`{"reason":"no longer needed"}`))
+	c.Params = gin.Params{{Key: "task_id", Value: taskID}}
+
+	h.CancelTask(c)
+
+	var resp dto.CancelTaskResponse
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w.Code, resp
+}
+
+func TestCancelTask(t *testing.T) {
+	cases := []struct {
+		name       string
+		status     string
+		state      asynq.TaskState // 0 表示 Redis 中不存在
+		code       int
+		redisState string
+		deleted    bool
+		canceled   bool
+	}{
+		{"等待执行", "pending", asynq.TaskStatePending, http.StatusOK, "pending", true, false},
+		{"延迟执行", "pending", asynq.TaskStateScheduled, http.StatusOK, "scheduled", true, false},
+		{"等待重试", "fail", asynq.TaskStateRetry, http.StatusOK, "retry", true, false},
+		{"执行中", "running", asynq.TaskStateActive, http.StatusOK, "active", false, true},
+		{"Redis 中不存在", "pending", 0, http.StatusOK, "not_found", false, false},
+		{"Redis 中已完成", "running", asynq.TaskStateCompleted, http.StatusConflict, "", false, false},
+		{"任务已结束", "success", asynq.TaskStateCompleted, http.StatusConflict, "", false, false},
+	}
+	for _, tc := range cases {
+		task := testTask("a", tc.status)
+		task.AsynqTaskID = "asynq-a"
+		h, repo, _, _ := newTestHandler(t, task)
+		insp := &fakeInspector{infos: map[string]*asynq.TaskInfo{}}
+		if tc.state != 0 {
+			insp.setState("asynq-a", tc.state)
+		}
+		h.inspector = insp
+
+		code, resp := cancelTask(t, h, "a")
+		require.Equal(t, tc.code, code, tc.name)
+		if tc.code != http.StatusOK {
+			assert.Equal(t, tc.status, repo.status("a"), "%s: 状态不变", tc.name)
+			assert.Empty(t, insp.deleted, tc.name)
+			assert.Empty(t, insp.canceled, tc.name)
+			continue
+		}
+		assert.Equal(t, tc.redisState, resp.RedisState, tc.name)
+		assert.Equal(t, string(model.TaskStatusCanceled), repo.status("a"), tc.name)
+		assert.Equal(t, "no longer needed", repo.tasks["a"].LastError, tc.name)
+		if tc.deleted {
+			assert.Equal(t, []string{"asynq-a"}, insp.deleted, tc.name)
+		}
+		if tc.canceled {
+			assert.Equal(t, []string{"asynq-a"}, insp.canceled, tc.name)
+			assert.Empty(t, insp.deleted, "%s: 中断后等待离开 active 再删除", tc.name)
+		}
+		h.Close()
+	}
+}
+
+func TestCancelTask_RemovesInterruptedTask(t *testing.T) {
+	task := testTask("a", "running")
+	h, _, _, _ := newTestHandler(t, task)
+	defer h.Close()
+	insp := &fakeInspector{infos: map[string]*asynq.TaskInfo{}}
+	insp.setState("a", asynq.TaskStateActive)
+	h.inspector = insp
+
+	code, _ := cancelTask(t, h, "a")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"a"}, insp.canceled, "未记录 asynq 任务 ID 时使用 task_id")
+
+	// 被中断的任务进入 retry 后删除
+	insp.setState("a", asynq.TaskStateRetry)
+	assert.Eventually(t, func() bool {
+		insp.mu.Lock()
+		defer insp.mu.Unlock()
+		return len(insp.deleted) == 1
+	}, 2*time.Second, 50*time.Millisecond)
+}
+
+func TestCancelTask_NotFound(t *testing.T) {
+	h, _, _, _ := newTestHandler(t)
+	h.inspector = &fakeInspector{}
+
+	code, _ := cancelTask(t, h, "missing")
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestReportAttempt_RunningOnCanceledTask(t *testing.T) {
+	h, repo, _, _ := newTestHandler(t, testTask("a", "canceled"))
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/", strings.NewReader(`{"attempt":2,"status":"running","worker_name":"test-worker","started_at":"2026-03-12T12:00:00Z"}`))
+	c.Params = gin.Params{{Key: "task_id", Value: "a"}}
+
+	h.ReportAttempt(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code, "worker 收到 409 后撤销本次执行")
+	assert.Equal(t, "canceled", repo.status("a"))
+}
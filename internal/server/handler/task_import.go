@@ -0,0 +1,295 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/hibiken/asynq"
+
+	"github.com/azhengyongqin/asynq-hub/internal/logger"
+	"github.com/azhengyongqin/asynq-hub/internal/middleware"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+	"github.com/azhengyongqin/asynq-hub/internal/server/dto"
+	workers "github.com/azhengyongqin/asynq-hub/internal/worker"
+)
+
+const (
+	// importChunkSize 导入时每批校验、入队与写库的行数
+	importChunkSize = 500
+
+	// maxImportLineSize 导入单行的最大长度（payload 上限之外留出其余字段的空间）
+	maxImportLineSize = middleware.MaxPayloadSize + 64*1024
+)
+
+// importLine 导入请求体中的一行
+type importLine struct {
+	no   int
+	data []byte
+}
+
+// taskImporter 一次导入请求的状态：跨批次缓存目标校验结果，并检测重复的 task_id
+type taskImporter struct {
+	h       *TaskHandler
+	targets *batchTargets
+	seen    map[string]struct{}
+}
+
+// ImportTasks godoc
+// @Summary 导入任务
+// @Description 请求体为 NDJSON，每行一个任务定义（字段与创建任务相同，task_id 必填），按与创建任务相同的规则逐行校验并分批入队。
+// @Description 响应为 NDJSON，按行返回处理结果（created / skipped / failed）。task_id 已存在的行返回 skipped 且不会重复入队，中断的导入可以原样重新提交。
+// @Description 出箱模式下每批任务记录与出箱记录在同一事务中写入，由后台投递协程入队。
+// @Tags Tasks
+// @Accept application/x-ndjson
+// @Produce application/x-ndjson
+// @Param request body string true "NDJSON 任务定义，每行一个 dto.CreateTaskRequest"
+// @Success 200 {array} dto.ImportTaskResult
+// @Failure 501 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Router /tasks/import [post]
+func (h *TaskHandler) ImportTasks(c *gin.Context) {
+	if h.asynqClient == nil {
+		c.JSON(http.StatusServiceUnavailable, dto.ErrorResponse{Error: "asynq client 未配置"})
+		return
+	}
+	// 断点续传依赖任务记录判断 task_id 是否已导入
+	if h.taskRepo == nil {
+		c.JSON(http.StatusNotImplemented, dto.ErrorResponse{Error: "Postgres 未配置"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	imp := &taskImporter{h: h, targets: newBatchTargets(h), seen: make(map[string]struct{})}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+	enc := json.NewEncoder(c.Writer)
+
+	counts := make(map[string]int, 3)
+	chunk := make([]importLine, 0, importChunkSize)
+	flush := func() error {
+		for _, r := range imp.importChunk(ctx, chunk) {
+			counts[r.Status]++
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+		c.Writer.Flush()
+		chunk = chunk[:0]
+		return nil
+	}
+
+	scanner := bufio.NewScanner(c.Request.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLineSize)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		chunk = append(chunk, importLine{no: lineNo, data: append([]byte(nil), data...)})
+		if len(chunk) == importChunkSize {
+			if err := flush(); err != nil {
+				logger.L.Error().Err(err).Int("line", lineNo).Msg("写入导入结果失败")
+				return
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		logger.L.Error().Err(err).Int("line", lineNo).Msg("写入导入结果失败")
+		return
+	}
+	if err := scanner.Err(); err != nil {
+		// 之后的行未被处理，客户端可从该行开始重新提交
+		_ = enc.Encode(dto.ImportTaskResult{Line: lineNo + 1, Status: dto.ImportStatusFailed, Error: "读取请求体失败: " + err.Error()})
+		logger.L.Warn().Err(err).Int("line", lineNo+1).Msg("读取导入请求体失败")
+	}
+
+	logger.L.Info().
+		Int("lines", lineNo).
+		Int("created", counts[dto.ImportStatusCreated]).
+		Int("skipped", counts[dto.ImportStatusSkipped]).
+		Int("failed", counts[dto.ImportStatusFailed]).
+		Msg("导入任务完成")
+}
+
+// importChunk 校验并入队一批导入行，按行返回结果
+func (imp *taskImporter) importChunk(ctx context.Context, lines []importLine) []dto.ImportTaskResult {
+	results := make([]dto.ImportTaskResult, len(lines))
+
+	type job struct {
+		index     int
+		item      dto.CreateTaskRequest
+		workerCfg workers.Config
+	}
+	jobs := make([]job, 0, len(lines))
+	taskIDs := make([]string, 0, len(lines))
+
+	for i, line := range lines {
+		results[i].Line = line.no
+		results[i].Status = dto.ImportStatusFailed
+
+		item, workerCfg, err := imp.parseLine(ctx, line.data)
+		results[i].TaskID = item.TaskID
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		jobs = append(jobs, job{index: i, item: item, workerCfg: workerCfg})
+		taskIDs = append(taskIDs, item.TaskID)
+	}
+	if len(jobs) == 0 {
+		return results
+	}
+
+	// 已有任务记录的 task_id 由此前的导入处理过，跳过
+	existing, err := imp.h.taskRepo.ExistingTaskIDs(ctx, taskIDs)
+	if err != nil {
+		logger.L.Error().Err(err).Int("count", len(taskIDs)).Msg("查询已导入任务失败")
+		for _, j := range jobs {
+			results[j.index].Error = "查询已有任务失败: " + err.Error()
+		}
+		return results
+	}
+
+	if imp.h.outbox {
+		// 出箱模式：任务记录与出箱记录在同一事务中写入，由 outbox.Relay 入队
+		now := time.Now()
+		pending := make([]job, 0, len(jobs))
+		entries := make([]repository.OutboxTask, 0, len(jobs))
+		for _, j := range jobs {
+			if _, ok := existing[j.item.TaskID]; ok {
+				results[j.index].Status = dto.ImportStatusSkipped
+				continue
+			}
+			fullQueue := j.workerCfg.FullQueueName(j.item.Queue, j.item.Priority)
+			pending = append(pending, j)
+			entries = append(entries, repository.OutboxTask{
+				Task:      newPendingTask(j.item, j.item.TaskID, fullQueue, j.item.TaskID),
+				ProcessAt: processAt(j.item, now),
+			})
+		}
+		skipped, err := imp.h.taskRepo.CreateTasksWithOutbox(ctx, entries)
+		if err != nil {
+			logger.L.Error().Err(err).Int("count", len(entries)).Msg("批量保存导入任务及出箱记录失败")
+		}
+		for _, j := range pending {
+			_, dup := skipped[j.item.TaskID]
+			switch {
+			case err != nil:
+				results[j.index].Error = "保存任务失败: " + err.Error()
+			case dup:
+				// 查询之后被并发写入
+				results[j.index].Status = dto.ImportStatusSkipped
+			default:
+				results[j.index].Status = dto.ImportStatusCreated
+				results[j.index].AsynqTaskID = j.item.TaskID
+			}
+		}
+		return results
+	}
+
+	records := make([]*repository.Task, len(lines))
+	enqueued := make([]bool, len(lines))
+	sem := make(chan struct{}, batchEnqueueConcurrency)
+	var wg sync.WaitGroup
+	for _, j := range jobs {
+		if _, ok := existing[j.item.TaskID]; ok {
+			results[j.index].Status = dto.ImportStatusSkipped
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(j job) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			fullQueue, info, err := imp.h.enqueueCreateTask(j.workerCfg, j.item, j.item.TaskID)
+			switch {
+			case err == nil:
+				results[j.index].Status = dto.ImportStatusCreated
+				results[j.index].AsynqTaskID = info.ID
+				enqueued[j.index] = true
+			case errors.Is(err, asynq.ErrTaskIDConflict) || errors.Is(err, asynq.ErrDuplicateTask):
+				// 上次导入已入队但未写库（如进程在两步之间退出）：补写任务记录，不重复入队
+				results[j.index].Status = dto.ImportStatusSkipped
+				results[j.index].AsynqTaskID = j.item.TaskID
+				fullQueue = j.workerCfg.FullQueueName(j.item.Queue, j.item.Priority)
+			default:
+				results[j.index].Error = err.Error()
+				return
+			}
+			record := newPendingTask(j.item, j.item.TaskID, fullQueue, results[j.index].AsynqTaskID)
+			records[j.index] = &record
+		}(j)
+	}
+	wg.Wait()
+
+	tasks := make([]repository.Task, 0, len(jobs))
+	for _, r := range records {
+		if r != nil {
+			tasks = append(tasks, *r)
+		}
+	}
+	if len(tasks) == 0 {
+		return results
+	}
+	if err := imp.h.taskRepo.UpsertTasks(ctx, tasks); err != nil {
+		// 撤回本批新入队的任务，使重新提交时这些行仍会被导入
+		logger.L.Error().Err(err).Int("count", len(tasks)).Msg("批量保存导入任务失败")
+		for i, r := range records {
+			if r == nil {
+				continue
+			}
+			if enqueued[i] && imp.h.inspector != nil {
+				if derr := imp.h.inspector.DeleteTask(r.Queue, r.AsynqTaskID); derr != nil && !errors.Is(derr, asynq.ErrTaskNotFound) {
+					logger.L.Warn().Err(derr).Str("task_id", r.TaskID).Msg("撤回未落库的导入任务失败")
+				}
+			}
+			results[i].Status = dto.ImportStatusFailed
+			results[i].AsynqTaskID = ""
+			results[i].Error = "保存任务失败: " + err.Error()
+		}
+	}
+	return results
+}
+
+// parseLine 解析并校验一行任务定义（与 CreateTask 的校验规则一致，另要求 task_id 用于断点续传）
+func (imp *taskImporter) parseLine(ctx context.Context, data []byte) (dto.CreateTaskRequest, workers.Config, error) {
+	var item dto.CreateTaskRequest
+	if err := json.Unmarshal(data, &item); err != nil {
+		return item, workers.Config{}, fmt.Errorf("JSON 格式无效: %w", err)
+	}
+	if err := binding.Validator.ValidateStruct(&item); err != nil {
+		return item, workers.Config{}, err
+	}
+	if item.TaskID == "" {
+		return item, workers.Config{}, errors.New("task_id 必填（用于断点续传）")
+	}
+	if err := validateCreateTaskRequest(&item); err != nil {
+		return item, workers.Config{}, err
+	}
+	if _, dup := imp.seen[item.TaskID]; dup {
+		return item, workers.Config{}, errors.New("task_id 在导入中重复")
+	}
+	imp.seen[item.TaskID] = struct{}{}
+
+	workerCfg, err := imp.targets.resolve(ctx, item)
+	if err != nil {
+		return item, workers.Config{}, err
+	}
+	return item, workerCfg, nil
+}
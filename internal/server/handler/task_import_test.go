@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/azhengyongqin/asynq-hub/internal/server/dto"
+)
+
+func importTasks(t *testing.T, h *TaskHandler, body string) []dto.ImportTaskResult {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/", strings.NewReader(body))
+
+	h.ImportTasks(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var results []dto.ImportTaskResult
+	dec := json.NewDecoder(w.Body)
+	for dec.More() {
+		var r dto.ImportTaskResult
+		require.NoError(t, dec.Decode(&r))
+		results = append(results, r)
+	}
+	return results
+}
+
+func TestImportTasks(t *testing.T) {
+	h, repo, _, _ := newTestHandler(t, testTask("old", "success"))
+	enq := &conflictEnqueuer{conflicts: map[string]bool{"t-conflict": true}}
+	h.asynqClient = enq
+
+	body := strings.Join([]string{
+		`{"worker_name":"test-worker","queue":"default","task_id":"t1","payload":{"n":1}}`,
+		``,
+		`{not json`,
+		`{"worker_name":"test-worker","queue":"default","payload":{}}`,
+		`{"worker_name":"test-worker","queue":"default","task_id":"old","payload":{}}`,
+		`{"worker_name":"test-worker","queue":"default","task_id":"t1","payload":{}}`,
+		`{"worker_name":"missing","queue":"default","task_id":"t2","payload":{}}`,
+		`{"worker_name":"test-worker","queue":"default","task_id":"t-conflict","payload":{}}`,
+	}, "\n")
+
+	results := importTasks(t, h, body)
+	require.Len(t, results, 7, "空行不返回结果")
+
+	byLine := make(map[int]dto.ImportTaskResult, len(results))
+	for _, r := range results {
+		byLine[r.Line] = r
+	}
+	assert.Equal(t, dto.ImportStatusCreated, byLine[1].Status)
+	assert.Equal(t, dto.ImportStatusFailed, byLine[3].Status)
+	assert.Contains(t, byLine[4].Error, "task_id 必填")
+	assert.Equal(t, dto.ImportStatusSkipped, byLine[5].Status, "已存在的 task_id 跳过")
+	assert.Contains(t, byLine[6].Error, "重复")
+	assert.Contains(t, byLine[7].Error, "worker 不存在")
+	assert.Equal(t, dto.ImportStatusSkipped, byLine[8].Status)
+	assert.Equal(t, 1, enq.count(), "只有 t1 真正入队")
+
+	require.Contains(t, repo.tasks, "t-conflict", "已入队但未落库的任务补写记录")
+	assert.Equal(t, "t-conflict", repo.tasks["t-conflict"].AsynqTaskID)
+
+	// 重新提交同一文件：已导入的行全部跳过，不再入队
+	results = importTasks(t, h, body)
+	for _, r := range results {
+		if r.Line == 1 || r.Line == 8 {
+			assert.Equal(t, dto.ImportStatusSkipped, r.Status)
+		}
+	}
+	assert.Equal(t, 1, enq.count())
+}
+
+func TestImportTasks_Outbox(t *testing.T) {
+	h, repo, _, enq := newTestHandler(t, testTask("old", "success"))
+	h.SetOutboxEnabled(true)
+
+	results := importTasks(t, h, strings.Join([]string{
+		`{"worker_name":"test-worker","queue":"default","task_id":"t1","payload":{}}`,
+		`{"worker_name":"test-worker","queue":"default","task_id":"old","payload":{}}`,
+	}, "\n"))
+	require.Len(t, results, 2)
+	assert.Equal(t, dto.ImportStatusCreated, results[0].Status)
+	assert.Equal(t, "t1", results[0].AsynqTaskID)
+	assert.Equal(t, dto.ImportStatusSkipped, results[1].Status)
+	assert.Equal(t, []string{"t1"}, repo.outbox)
+	assert.Zero(t, enq.count(), "出箱模式下不直接入队")
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+	"github.com/azhengyongqin/asynq-hub/internal/server/dto"
+)
+
+// AppendTaskLogs godoc
+// @Summary 上报任务日志
+// @Description Worker 分批上报任务执行过程中记录的日志（每批最多 500 行）
+// @Tags Tasks
+// @Accept json
+// @Produce json
+// @Param task_id path string true "任务 ID"
+// @Param request body dto.AppendTaskLogsRequest true "日志"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 501 {object} dto.ErrorResponse
+// @Router /tasks/{task_id}/logs [post]
+func (h *TaskHandler) AppendTaskLogs(c *gin.Context) {
+	if h.taskRepo == nil {
+		c.JSON(http.StatusNotImplemented, dto.ErrorResponse{Error: "Postgres 未配置"})
+		return
+	}
+
+	taskID := c.Param("task_id")
+	var req dto.AppendTaskLogsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if _, err := h.taskRepo.GetTask(c.Request.Context(), taskID); err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "task 不存在"})
+		return
+	}
+
+	logs := make([]repository.TaskLog, len(req.Lines))
+	for i, l := range req.Lines {
+		logs[i] = repository.TaskLog{
+			TaskID:   taskID,
+			Attempt:  req.Attempt,
+			Level:    l.Level,
+			Message:  l.Message,
+			LoggedAt: l.Time,
+		}
+	}
+	if err := h.taskRepo.InsertTaskLogs(c.Request.Context(), logs); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{Status: "ok", Message: "上报成功"})
+}
+
+// ListTaskLogs godoc
+// @Summary 查询任务日志
+// @Description 按写入顺序查询任务执行日志，可按尝试过滤，通过 after_id 翻页
+// @Tags Tasks
+// @Produce json
+// @Param task_id path string true "任务 ID"
+// @Param attempt query int false "尝试序号（为空返回所有尝试）"
+// @Param after_id query int false "只返回 id 大于该值的日志" default(0)
+// @Param limit query int false "返回数量（最多 1000）" default(500)
+// @Success 200 {object} dto.TaskLogListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 501 {object} dto.ErrorResponse
+// @Router /tasks/{task_id}/logs [get]
+func (h *TaskHandler) ListTaskLogs(c *gin.Context) {
+	if h.taskRepo == nil {
+		c.JSON(http.StatusNotImplemented, dto.ErrorResponse{Error: "Postgres 未配置"})
+		return
+	}
+
+	attempt, err := strconv.Atoi(c.DefaultQuery("attempt", "0"))
+	if err != nil || attempt < 0 {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "attempt 必须是非负整数"})
+		return
+	}
+	afterID, _ := strconv.ParseInt(c.DefaultQuery("after_id", "0"), 10, 64)
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "500"))
+
+	items, err := h.taskRepo.ListTaskLogs(c.Request.Context(), repository.ListTaskLogsFilter{
+		TaskID:  c.Param("task_id"),
+		Attempt: attempt,
+		AfterID: afterID,
+		Limit:   limit,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.TaskLogListResponse{Items: items})
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/azhengyongqin/asynq-hub/internal/model"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+	"github.com/azhengyongqin/asynq-hub/internal/server/dto"
+)
+
+// ReportProgress godoc
+// @Summary 上报任务执行进度
+// @Description Worker 在任务执行过程中上报进度，每次尝试只保存最新一条
+// @Tags Tasks
+// @Accept json
+// @Produce json
+// @Param task_id path string true "任务 ID"
+// @Param request body dto.ReportProgressRequest true "执行进度"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 501 {object} dto.ErrorResponse
+// @Router /tasks/{task_id}/progress [post]
+func (h *TaskHandler) ReportProgress(c *gin.Context) {
+	if h.taskRepo == nil {
+		c.JSON(http.StatusNotImplemented, dto.ErrorResponse{Error: "Postgres 未配置"})
+		return
+	}
+
+	taskID := c.Param("task_id")
+	var req dto.ReportProgressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	task, err := h.taskRepo.GetTask(c.Request.Context(), taskID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "task 不存在"})
+		return
+	}
+
+	// 与 running 上报一致：已取消的任务返回 409，handler 可据此提前退出
+	if task.Status == string(model.TaskStatusCanceled) {
+		c.JSON(http.StatusConflict, dto.ErrorResponse{Error: "任务已取消"})
+		return
+	}
+
+	if err := h.taskRepo.UpsertProgress(c.Request.Context(), repository.TaskProgress{
+		TaskID:  taskID,
+		Attempt: req.Attempt,
+		Percent: req.Percent,
+		Message: req.Message,
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{Status: "ok", Message: "上报成功"})
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/azhengyongqin/asynq-hub/internal/middleware"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+	workers "github.com/azhengyongqin/asynq-hub/internal/worker"
+)
+
+// 列表总数的计算方式（total 查询参数）
+const (
+	totalExact    = "exact"
+	totalEstimate = "estimate"
+	totalNone     = "none"
+)
+
+// parseCursor 解析 cursor 查询参数，未传时返回 nil
+func parseCursor(c *gin.Context) (*repository.Cursor, error) {
+	v := c.Query("cursor")
+	if v == "" {
+		return nil, nil
+	}
+	return repository.DecodeCursor(v)
+}
+
+// parseTaskFilter 从查询参数解析任务过滤条件（不含分页参数），payload_path 由 repo 校验语法
+func parseTaskFilter(c *gin.Context, repo repository.TaskRepository) (repository.ListTasksFilter, error) {
+	f := repository.ListTasksFilter{
+		WorkerName:    c.Query("worker_name"),
+		Status:        c.Query("status"),
+		Queue:         c.Query("queue"),
+		ErrorContains: c.Query("error"),
+		PayloadPath:   c.Query("payload_path"),
+		SortBy:        c.Query("sort_by"),
+		SortOrder:     c.Query("order"),
+	}
+
+	labels, err := middleware.ParseLabelSelector(c.QueryArray("label"))
+	if err != nil {
+		return f, err
+	}
+	f.Labels = labels
+
+	if p := c.Query("priority"); p != "" {
+		if p != workers.PriorityCritical && p != workers.PriorityDefault && p != workers.PriorityLow {
+			return f, errors.New("priority 必须是 critical, default 或 low")
+		}
+		f.Priority = priorityToInt(p)
+	}
+
+	for _, tr := range []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"created_after", &f.CreatedAfter},
+		{"created_before", &f.CreatedBefore},
+		{"updated_after", &f.UpdatedAfter},
+		{"updated_before", &f.UpdatedBefore},
+	} {
+		v := c.Query(tr.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return f, fmt.Errorf("%s 必须是 RFC3339 时间", tr.name)
+		}
+		*tr.dst = &t
+	}
+
+	if v := c.Query("payload"); v != "" {
+		if !json.Valid([]byte(v)) || !strings.HasPrefix(strings.TrimSpace(v), "{") {
+			return f, errors.New("payload 必须是 JSON 对象")
+		}
+		f.PayloadContains = json.RawMessage(v)
+	}
+
+	if f.SortBy != "" && !repository.TaskSortFields[f.SortBy] {
+		return f, errors.New("sort_by 必须是 created_at, updated_at 或 priority")
+	}
+	if f.SortOrder != "" && f.SortOrder != "asc" && f.SortOrder != "desc" {
+		return f, errors.New("order 必须是 asc 或 desc")
+	}
+	if err := validatePayloadPath(c.Request.Context(), repo, f.PayloadPath); err != nil {
+		return f, err
+	}
+
+	return f, nil
+}
+
+// validatePayloadPath 由 Postgres 解析 payload_path，语法错误时返回错误；
+// 其他数据库错误忽略，由随后的任务查询返回
+func validatePayloadPath(ctx context.Context, repo repository.TaskRepository, path string) error {
+	if path == "" || repo == nil {
+		return nil
+	}
+	if err := repo.ValidateJSONPath(ctx, path); errors.Is(err, repository.ErrInvalidJSONPath) {
+		return fmt.Errorf("payload_path 无效: %w", err)
+	}
+	return nil
+}
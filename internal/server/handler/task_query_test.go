@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseTaskFilterQuery(t *testing.T, query string) error {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/?"+query, nil)
+	_, err := parseTaskFilter(c, &fakeTaskRepo{invalidPaths: map[string]bool{"$.amount >": true}})
+	return err
+}
+
+func TestParseTaskFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/?status=fail&priority=critical&label=tenant=acme"+
+		"&created_after=2026-01-01T00:00:00Z&updated_before=2026-02-01T00:00:00%2B08:00"+
+		"&error=timeout&payload=%7B%22customer_id%22%3A%22c-42%22%7D&payload_path=%24.amount+%3E+100"+
+		"&sort_by=priority&order=asc", nil)
+
+	f, err := parseTaskFilter(c, &fakeTaskRepo{})
+	require.NoError(t, err)
+	assert.Equal(t, "fail", f.Status)
+	assert.Equal(t, 3, f.Priority)
+	assert.Equal(t, map[string]string{"tenant": "acme"}, f.Labels)
+	require.NotNil(t, f.CreatedAfter)
+	assert.True(t, f.CreatedAfter.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)))
+	require.NotNil(t, f.UpdatedBefore)
+	assert.True(t, f.UpdatedBefore.Equal(time.Date(2026, 1, 31, 16, 0, 0, 0, time.UTC)))
+	assert.Nil(t, f.CreatedBefore)
+	assert.Equal(t, "timeout", f.ErrorContains)
+	assert.JSONEq(t, `{"customer_id":"c-42"}`, string(f.PayloadContains))
+	assert.Equal(t, "$.amount > 100", f.PayloadPath)
+	assert.Equal(t, "priority", f.SortBy)
+	assert.Equal(t, "asc", f.SortOrder)
+}
+
+func TestParseTaskFilter_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr string
+	}{
+		{"priority", "priority=urgent", "priority"},
+		{"time", "created_after=yesterday", "created_after"},
+		{"payload not json", "payload=abc", "payload"},
+		{"payload not object", "payload=%5B1%5D", "payload"},
+		{"sort_by", "sort_by=payload", "sort_by"},
+		{"order", "order=up", "order"},
+		{"label", "label=tenant", "label"},
+		{"payload_path", "payload_path=%24.amount+%3E", "payload_path 无效"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := parseTaskFilterQuery(t, tt.query)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/azhengyongqin/asynq-hub/internal/model"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+	"github.com/azhengyongqin/asynq-hub/internal/server/dto"
+)
+
+const (
+	// resultPollInterval 长轮询等待任务结果时查询 Postgres 的间隔
+	resultPollInterval = 500 * time.Millisecond
+	// maxResultWaitSeconds 长轮询最长等待时间（秒）
+	maxResultWaitSeconds = 60
+)
+
+// GetTaskResult godoc
+// @Summary 获取任务结果
+// @Description 获取任务结果；wait>0 时长轮询，直到任务结束或等待超时（最长 60 秒）
+// @Tags Tasks
+// @Produce json
+// @Param task_id path string true "任务 ID"
+// @Param wait query int false "最长等待秒数（0-60）" default(0)
+// @Success 200 {object} dto.TaskResultResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 501 {object} dto.ErrorResponse
+// @Router /tasks/{task_id}/result [get]
+func (h *TaskHandler) GetTaskResult(c *gin.Context) {
+	if h.taskRepo == nil {
+		c.JSON(http.StatusNotImplemented, dto.ErrorResponse{Error: "Postgres 未配置"})
+		return
+	}
+
+	wait := 0
+	if v := c.Query("wait"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "wait 必须是非负整数"})
+			return
+		}
+		wait = min(n, maxResultWaitSeconds)
+	}
+
+	ctx := c.Request.Context()
+	taskID := c.Param("task_id")
+	t, err := h.taskRepo.GetTask(ctx, taskID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "task 不存在"})
+		return
+	}
+
+	if wait > 0 && !model.TaskStatus(t.Status).IsTerminal() {
+		deadline := time.NewTimer(time.Duration(wait) * time.Second)
+		defer deadline.Stop()
+		ticker := time.NewTicker(resultPollInterval)
+		defer ticker.Stop()
+
+	poll:
+		for {
+			select {
+			case <-ctx.Done():
+				// 客户端已断开
+				return
+			case <-deadline.C:
+				break poll
+			case <-ticker.C:
+				latest, err := h.taskRepo.GetTask(ctx, taskID)
+				if err != nil {
+					continue
+				}
+				t = latest
+				if model.TaskStatus(t.Status).IsTerminal() {
+					break poll
+				}
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, newTaskResultResponse(t))
+}
+
+func newTaskResultResponse(t *repository.Task) dto.TaskResultResponse {
+	resp := dto.TaskResultResponse{
+		TaskID: t.TaskID,
+		Status: t.Status,
+		Ready:  model.TaskStatus(t.Status).IsTerminal(),
+		Result: t.Result,
+	}
+	if t.Status != string(model.TaskStatusSuccess) {
+		resp.Error = t.LastError
+	}
+	return resp
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/azhengyongqin/asynq-hub/internal/server/dto"
+)
+
+func getTaskResult(t *testing.T, h *TaskHandler, taskID, wait string) (int, dto.TaskResultResponse) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/?wait="+wait, nil)
+	c.Params = gin.Params{{Key: "task_id", Value: taskID}}
+
+	h.GetTaskResult(c)
+
+	var resp dto.TaskResultResponse
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w.Code, resp
+}
+
+func TestGetTaskResult(t *testing.T) {
+	done := testTask("done", "success")
+	done.Result = json.RawMessage(`{"n":1}`)
+	failed := testTask("failed", "dead")
+	failed.LastError = "boom"
+	h, _, _, _ := newTestHandler(t, done, failed, testTask("running", "pending"))
+
+	code, resp := getTaskResult(t, h, "done", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, resp.Ready)
+	assert.JSONEq(t, `{"n":1}`, string(resp.Result))
+	assert.Empty(t, resp.Error)
+
+	code, resp = getTaskResult(t, h, "failed", "5")
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, resp.Ready)
+	assert.Equal(t, "boom", resp.Error)
+
+	code, resp = getTaskResult(t, h, "running", "0")
+	require.Equal(t, http.StatusOK, code)
+	assert.False(t, resp.Ready, "wait=0 时不等待")
+
+	code, _ = getTaskResult(t, h, "missing", "")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, _ = getTaskResult(t, h, "done", "-1")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestGetTaskResult_WaitsUntilTerminal(t *testing.T) {
+	h, repo, _, _ := newTestHandler(t, testTask("a", "pending"))
+
+	go func() {
+		time.Sleep(2 * resultPollInterval)
+		repo.mu.Lock()
+		repo.tasks["a"].Status = "success"
+		repo.tasks["a"].Result = json.RawMessage(`"ok"`)
+		repo.mu.Unlock()
+	}()
+
+	start := time.Now()
+	code, resp := getTaskResult(t, h, "a", "10")
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, resp.Ready)
+	assert.Equal(t, "success", resp.Status)
+	assert.JSONEq(t, `"ok"`, string(resp.Result))
+	assert.Less(t, time.Since(start), 5*time.Second, "任务结束后立即返回")
+}
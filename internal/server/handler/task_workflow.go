@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/azhengyongqin/asynq-hub/internal/logger"
+	"github.com/azhengyongqin/asynq-hub/internal/model"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+)
+
+// validateWorkflowGraph 校验依赖关系：依赖必须在工作流内、不能依赖自身、不能成环
+func validateWorkflowGraph(tasks []repository.Task) error {
+	indegree := make(map[string]int, len(tasks))
+	children := make(map[string][]string, len(tasks))
+	for _, t := range tasks {
+		indegree[t.TaskID] = 0
+	}
+	for _, t := range tasks {
+		for _, dep := range t.DependsOn {
+			if dep == t.TaskID {
+				return fmt.Errorf("任务 %s 不能依赖自身", t.TaskID)
+			}
+			if _, ok := indegree[dep]; !ok {
+				return fmt.Errorf("任务 %s 依赖的 %s 不在工作流中", t.TaskID, dep)
+			}
+			indegree[t.TaskID]++
+			children[dep] = append(children[dep], t.TaskID)
+		}
+	}
+
+	// Kahn 拓扑排序：无法排完说明存在环
+	queue := make([]string, 0, len(tasks))
+	for id, n := range indegree {
+		if n == 0 {
+			queue = append(queue, id)
+		}
+	}
+	visited := 0
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		visited++
+		for _, child := range children[id] {
+			indegree[child]--
+			if indegree[child] == 0 {
+				queue = append(queue, child)
+			}
+		}
+	}
+	if visited != len(tasks) {
+		return errors.New("depends_on 存在循环依赖")
+	}
+	return nil
+}
+
+// advanceWorkflow 推进工作流：
+// - 上游全部 success 的 waiting 任务入队
+// - 上游存在 dead/canceled/skipped/expired/deleted/lost 的 waiting 任务标记为 skipped
+// - 所有任务结束后刷新工作流状态
+//
+// 多个上游同时完成时可能并发推进同一工作流：状态通过 TransitionTaskStatus 条件更新，
+// 入队前先抢占 waiting→pending，保证每个任务只流转、只入队一次。
+func (h *TaskHandler) advanceWorkflow(ctx context.Context, workflowID string) error {
+	if h.workflowRepo == nil {
+		return nil
+	}
+
+	tasks, err := h.workflowRepo.ListWorkflowTasks(ctx, workflowID)
+	if err != nil {
+		return err
+	}
+
+	status := make(map[string]model.TaskStatus, len(tasks))
+	for _, t := range tasks {
+		status[t.TaskID] = model.TaskStatus(t.Status)
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for i := range tasks {
+			t := &tasks[i]
+			if status[t.TaskID] != model.TaskStatusWaiting {
+				continue
+			}
+
+			ready, failedParent := true, ""
+			for _, dep := range t.DependsOn {
+				s := status[dep]
+				if s == model.TaskStatusSuccess {
+					continue
+				}
+				ready = false
+				if s.IsTerminal() {
+					failedParent = dep
+					break
+				}
+			}
+
+			var next model.TaskStatus
+			switch {
+			case failedParent != "":
+				next, err = h.skipWorkflowTask(ctx, t, failedParent)
+			case ready:
+				next, err = h.enqueueWorkflowTask(ctx, t)
+			default:
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			// 仍为 waiting 说明由并发的推进流程处理中
+			if next == model.TaskStatusWaiting {
+				continue
+			}
+			status[t.TaskID] = next
+			changed = true
+		}
+	}
+
+	// 所有任务结束后确定工作流最终状态
+	wfStatus := model.WorkflowStatusSuccess
+	for _, s := range status {
+		if !s.IsTerminal() {
+			return nil
+		}
+		if s != model.TaskStatusSuccess {
+			wfStatus = model.WorkflowStatusFailed
+		}
+	}
+	return h.workflowRepo.UpdateWorkflowStatus(ctx, workflowID, string(wfStatus))
+}
+
+// skipWorkflowTask 上游失败时将 waiting 任务标记为 skipped，返回任务的最新状态
+func (h *TaskHandler) skipWorkflowTask(ctx context.Context, t *repository.Task, failedParent string) (model.TaskStatus, error) {
+	reason := fmt.Sprintf("上游任务 %s 未成功", failedParent)
+	ok, err := h.taskRepo.TransitionTaskStatus(ctx, t.TaskID, string(model.TaskStatusWaiting), string(model.TaskStatusSkipped), "", reason)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return h.currentTaskStatus(ctx, t.TaskID)
+	}
+	return model.TaskStatusSkipped, nil
+}
+
+// enqueueWorkflowTask 将依赖已满足的 waiting 任务入队，返回任务的最新状态。
+// 先通过 waiting→pending 抢占再入队：并发推进时只有抢占成功的流程会入队；
+// 入队失败时任务直接标记为 dead（见 failWorkflowTask），下游随之被跳过。
+// 抢占与入队不在同一事务中：进程在两步之间退出时任务停留在 pending 而 Redis 中没有对应任务，
+// 由状态对账（reconciler）修正为 deleted 并跳过下游；出箱模式下两步合并在一个事务中，没有该窗口。
+func (h *TaskHandler) enqueueWorkflowTask(ctx context.Context, t *repository.Task) (model.TaskStatus, error) {
+	workerCfg, ok := h.workerStore.Get(t.WorkerName)
+	if !ok {
+		return h.failWorkflowTask(ctx, t, model.TaskStatusWaiting, "worker 不存在")
+	}
+
+	if h.outbox {
+		// 出箱模式：抢占与出箱记录在同一事务中写入，由 outbox.Relay 入队
+		ok, err := h.taskRepo.TransitionTaskWithOutbox(ctx, t.TaskID, string(model.TaskStatusWaiting))
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return h.currentTaskStatus(ctx, t.TaskID)
+		}
+		return model.TaskStatusPending, nil
+	}
+
+	ok, err := h.taskRepo.TransitionTaskStatus(ctx, t.TaskID, string(model.TaskStatusWaiting), string(model.TaskStatusPending), "", "")
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return h.currentTaskStatus(ctx, t.TaskID)
+	}
+
+	info, err := h.enqueueRequeuedTask(workerCfg, *t, 0)
+	if err != nil {
+		// 任务已存在于 asynq（例如上次抢占后入队成功但进程在回写前退出）
+		if errors.Is(err, asynq.ErrTaskIDConflict) || errors.Is(err, asynq.ErrDuplicateTask) {
+			return model.TaskStatusPending, nil
+		}
+		return h.failWorkflowTask(ctx, t, model.TaskStatusPending, "入队失败: "+err.Error())
+	}
+
+	// 回写 asynq 任务 ID（用于取消）；期间任务已被取消时撤回刚入队的任务
+	ok, err = h.taskRepo.TransitionTaskStatus(ctx, t.TaskID, string(model.TaskStatusPending), string(model.TaskStatusPending), info.ID, "")
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		if h.inspector != nil {
+			_ = h.inspector.DeleteTask(t.Queue, info.ID)
+		}
+		return h.currentTaskStatus(ctx, t.TaskID)
+	}
+	return model.TaskStatusPending, nil
+}
+
+// failWorkflowTask 无法入队的任务（状态为 from）直接标记为 dead，下游随之被跳过
+func (h *TaskHandler) failWorkflowTask(ctx context.Context, t *repository.Task, from model.TaskStatus, reason string) (model.TaskStatus, error) {
+	logger.L.Warn().Str("task_id", t.TaskID).Str("workflow_id", t.WorkflowID).Str("reason", reason).Msg("工作流任务无法入队")
+	ok, err := h.taskRepo.TransitionTaskStatus(ctx, t.TaskID, string(from), string(model.TaskStatusDead), "", reason)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return h.currentTaskStatus(ctx, t.TaskID)
+	}
+	return model.TaskStatusDead, nil
+}
+
+// currentTaskStatus 读取任务在数据库中的最新状态
+func (h *TaskHandler) currentTaskStatus(ctx context.Context, taskID string) (model.TaskStatus, error) {
+	t, err := h.taskRepo.GetTask(ctx, taskID)
+	if err != nil {
+		return "", err
+	}
+	return model.TaskStatus(t.Status), nil
+}
+
+// onWorkflowTaskDone 工作流中的任务进入终态后推进工作流（失败只记录日志，不影响调用方）
+func (h *TaskHandler) onWorkflowTaskDone(ctx context.Context, t *repository.Task) {
+	if t.WorkflowID == "" {
+		return
+	}
+	if err := h.advanceWorkflow(ctx, t.WorkflowID); err != nil {
+		logger.L.Error().Err(err).Str("task_id", t.TaskID).Str("workflow_id", t.WorkflowID).Msg("推进工作流失败")
+	}
+}
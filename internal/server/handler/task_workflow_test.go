@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/azhengyongqin/asynq-hub/internal/model"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+)
+
+func TestValidateWorkflowGraph(t *testing.T) {
+	tests := []struct {
+		name    string
+		tasks   []repository.Task
+		wantErr string
+	}{
+		{
+			name:  "菱形依赖",
+			tasks: []repository.Task{workflowTask("a", ""), workflowTask("b", "", "a"), workflowTask("c", "", "a"), workflowTask("d", "", "b", "c")},
+		},
+		{
+			name:  "无依赖",
+			tasks: []repository.Task{workflowTask("a", ""), workflowTask("b", "")},
+		},
+		{
+			name:    "依赖自身",
+			tasks:   []repository.Task{workflowTask("a", "", "a")},
+			wantErr: "不能依赖自身",
+		},
+		{
+			name:    "依赖不在工作流中",
+			tasks:   []repository.Task{workflowTask("a", ""), workflowTask("b", "", "x")},
+			wantErr: "不在工作流中",
+		},
+		{
+			name:    "两节点环",
+			tasks:   []repository.Task{workflowTask("a", "", "b"), workflowTask("b", "", "a")},
+			wantErr: "循环依赖",
+		},
+		{
+			name:    "带入口的长环",
+			tasks:   []repository.Task{workflowTask("root", ""), workflowTask("a", "", "root", "c"), workflowTask("b", "", "a"), workflowTask("c", "", "b")},
+			wantErr: "循环依赖",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWorkflowGraph(tt.tasks)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestAdvanceWorkflow_EnqueuesReadyTasks(t *testing.T) {
+	h, repo, wf, enq := newTestHandler(t,
+		workflowTask("a", "success"),
+		workflowTask("b", "waiting", "a"),
+		workflowTask("c", "waiting", "a"),
+		workflowTask("d", "waiting", "b", "c"),
+	)
+
+	require.NoError(t, h.advanceWorkflow(context.Background(), "workflow-1"))
+
+	assert.Equal(t, string(model.TaskStatusPending), repo.status("b"))
+	assert.Equal(t, string(model.TaskStatusPending), repo.status("c"))
+	assert.Equal(t, string(model.TaskStatusWaiting), repo.status("d"), "上游未全部成功时保持 waiting")
+	assert.Equal(t, 2, enq.count())
+	assert.NotEmpty(t, repo.tasks["b"].AsynqTaskID, "入队后回写 asynq 任务 ID")
+	assert.Empty(t, wf.status, "仍有未结束的任务时不更新工作流状态")
+}
+
+func TestAdvanceWorkflow_Outbox(t *testing.T) {
+	h, repo, _, enq := newTestHandler(t,
+		workflowTask("a", "success"),
+		workflowTask("b", "waiting", "a"),
+	)
+	h.SetOutboxEnabled(true)
+
+	require.NoError(t, h.advanceWorkflow(context.Background(), "workflow-1"))
+
+	assert.Equal(t, string(model.TaskStatusPending), repo.status("b"))
+	assert.Equal(t, "b", repo.tasks["b"].AsynqTaskID)
+	assert.Equal(t, []string{"b"}, repo.outbox, "抢占与出箱记录一并写入")
+	assert.Zero(t, enq.count(), "出箱模式下不直接入队")
+}
+
+func TestAdvanceWorkflow_SkipPropagates(t *testing.T) {
+	h, repo, wf, enq := newTestHandler(t,
+		workflowTask("a", "success"),
+		workflowTask("b", "dead", "a"),
+		workflowTask("c", "waiting", "b"),
+		workflowTask("d", "waiting", "c"),
+		workflowTask("e", "waiting", "a"),
+	)
+
+	require.NoError(t, h.advanceWorkflow(context.Background(), "workflow-1"))
+
+	assert.Equal(t, string(model.TaskStatusSkipped), repo.status("c"))
+	assert.Equal(t, string(model.TaskStatusSkipped), repo.status("d"), "跳过沿依赖链向下传播")
+	assert.Equal(t, string(model.TaskStatusPending), repo.status("e"))
+	assert.Equal(t, 1, enq.count())
+	assert.Empty(t, wf.status)
+}
+
+func TestAdvanceWorkflow_FinalStatus(t *testing.T) {
+	h, _, wf, _ := newTestHandler(t,
+		workflowTask("a", "success"),
+		workflowTask("b", "success", "a"),
+	)
+	require.NoError(t, h.advanceWorkflow(context.Background(), "workflow-1"))
+	assert.Equal(t, string(model.WorkflowStatusSuccess), wf.status)
+
+	h, _, wf, _ = newTestHandler(t,
+		workflowTask("a", "canceled"),
+		workflowTask("b", "waiting", "a"),
+	)
+	require.NoError(t, h.advanceWorkflow(context.Background(), "workflow-1"))
+	assert.Equal(t, string(model.WorkflowStatusFailed), wf.status)
+}
+
+func TestAdvanceWorkflow_ClaimedByConcurrentAdvance(t *testing.T) {
+	h, repo, wf, enq := newTestHandler(t,
+		workflowTask("a", "success"),
+		workflowTask("b", "pending", "a"),
+	)
+	// 读到的快照中 b 仍为 waiting，但另一个推进流程已抢占
+	wf.snapshot = []repository.Task{workflowTask("a", "success"), workflowTask("b", "waiting", "a")}
+
+	require.NoError(t, h.advanceWorkflow(context.Background(), "workflow-1"))
+
+	assert.Equal(t, 0, enq.count(), "抢占失败时不能重复入队")
+	assert.Equal(t, string(model.TaskStatusPending), repo.status("b"))
+}
+
+func TestAdvanceWorkflow_EnqueueFailureMarksDead(t *testing.T) {
+	h, repo, wf, enq := newTestHandler(t,
+		workflowTask("a", "success"),
+		workflowTask("b", "waiting", "a"),
+		workflowTask("c", "waiting", "b"),
+	)
+	enq.err = errors.New("redis down")
+
+	require.NoError(t, h.advanceWorkflow(context.Background(), "workflow-1"))
+
+	assert.Equal(t, string(model.TaskStatusDead), repo.status("b"))
+	assert.Contains(t, repo.tasks["b"].LastError, "入队失败")
+	assert.Equal(t, string(model.TaskStatusSkipped), repo.status("c"))
+	assert.Equal(t, string(model.WorkflowStatusFailed), wf.status)
+}
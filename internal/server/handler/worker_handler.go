@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/azhengyongqin/asynq-hub/internal/middleware"
 	"github.com/azhengyongqin/asynq-hub/internal/repository"
 	"github.com/azhengyongqin/asynq-hub/internal/server/dto"
 	workers "github.com/azhengyongqin/asynq-hub/internal/worker"
@@ -66,7 +67,9 @@ func (h *WorkerHandler) GetWorker(c *gin.Context) {
 // @Tags Workers
 // @Produce json
 // @Param worker_name path string true "Worker 名称"
+// @Param label query []string false "标签选择器，格式 key=value，可重复（需同时满足）" collectionFormat(multi)
 // @Success 200 {object} dto.WorkerStatsResponse
+// @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 501 {object} dto.ErrorResponse
 // @Router /api/v1/workers/{worker_name}/stats [get]
@@ -76,8 +79,14 @@ func (h *WorkerHandler) GetWorkerStats(c *gin.Context) {
 		return
 	}
 
+	labels, err := middleware.ParseLabelSelector(c.QueryArray("label"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	workerName := c.Param("worker_name")
-	stats, err := h.taskRepo.GetWorkerStats(c.Request.Context(), workerName)
+	stats, err := h.taskRepo.GetWorkerStats(c.Request.Context(), workerName, labels)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
 		return
@@ -93,7 +102,9 @@ func (h *WorkerHandler) GetWorkerStats(c *gin.Context) {
 // @Produce json
 // @Param worker_name path string true "Worker 名称"
 // @Param hours query int false "统计小时数" default(24)
+// @Param label query []string false "标签选择器，格式 key=value，可重复（需同时满足）" collectionFormat(multi)
 // @Success 200 {object} dto.WorkerTimeSeriesResponse
+// @Failure 400 {object} dto.ErrorResponse
 // @Failure 501 {object} dto.ErrorResponse
 // @Router /api/v1/workers/{worker_name}/timeseries [get]
 func (h *WorkerHandler) GetWorkerTimeSeries(c *gin.Context) {
@@ -104,8 +115,13 @@ func (h *WorkerHandler) GetWorkerTimeSeries(c *gin.Context) {
 
 	workerName := c.Param("worker_name")
 	hours, _ := strconv.Atoi(c.DefaultQuery("hours", "24"))
+	labels, err := middleware.ParseLabelSelector(c.QueryArray("label"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
 
-	timeseries, err := h.taskRepo.GetWorkerTimeSeriesStats(c.Request.Context(), workerName, hours)
+	timeseries, err := h.taskRepo.GetWorkerTimeSeriesStats(c.Request.Context(), workerName, hours, labels)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
 		return
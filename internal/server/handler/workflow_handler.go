@@ -0,0 +1,294 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/azhengyongqin/asynq-hub/internal/logger"
+	"github.com/azhengyongqin/asynq-hub/internal/model"
+	asynqx "github.com/azhengyongqin/asynq-hub/internal/queue"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+	"github.com/azhengyongqin/asynq-hub/internal/server/dto"
+)
+
+// WorkflowHandler Workflow 相关 API Handler
+// 工作流任务的入队与推进复用 TaskHandler 的逻辑（ReportAttempt 会触发推进）
+type WorkflowHandler struct {
+	tasks *TaskHandler
+}
+
+// NewWorkflowHandler 创建 WorkflowHandler
+func NewWorkflowHandler(tasks *TaskHandler) *WorkflowHandler {
+	return &WorkflowHandler{tasks: tasks}
+}
+
+// CreateWorkflow godoc
+// @Summary 创建工作流
+// @Description 提交一组通过 depends_on 组成 DAG 的任务；无依赖的任务立即入队，其余任务在上游全部成功后入队，上游失败时跳过
+// @Tags Workflows
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateWorkflowRequest true "工作流创建请求"
+// @Success 200 {object} dto.WorkflowResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 501 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Router /workflows [post]
+func (h *WorkflowHandler) CreateWorkflow(c *gin.Context) {
+	if h.tasks.asynqClient == nil {
+		c.JSON(http.StatusServiceUnavailable, dto.ErrorResponse{Error: "asynq client 未配置"})
+		return
+	}
+	if h.tasks.taskRepo == nil || h.tasks.workflowRepo == nil {
+		c.JSON(http.StatusNotImplemented, dto.ErrorResponse{Error: "Postgres 未配置"})
+		return
+	}
+
+	var req dto.CreateWorkflowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if len(req.Tasks) > dto.MaxWorkflowTasks {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: fmt.Sprintf("单个工作流最多 %d 个任务", dto.MaxWorkflowTasks)})
+		return
+	}
+
+	tasks := make([]repository.Task, 0, len(req.Tasks))
+	seen := make(map[string]struct{}, len(req.Tasks))
+	persisted := make(map[string]struct{})
+	for i := range req.Tasks {
+		node := &req.Tasks[i]
+		if err := h.validateNode(node); err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: fmt.Sprintf("tasks[%d]: %s", i, err.Error())})
+			return
+		}
+
+		workerCfg, err := h.tasks.resolveTaskTarget(node.WorkerName, node.Queue, node.Priority)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: fmt.Sprintf("tasks[%d]: %s", i, err.Error())})
+			return
+		}
+		if err := h.tasks.resolveCallbackTargets(node.CreateTaskRequest); err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: fmt.Sprintf("tasks[%d]: %s", i, err.Error())})
+			return
+		}
+		if _, ok := persisted[workerCfg.WorkerName]; !ok {
+			if err := h.tasks.ensureWorkerPersisted(c.Request.Context(), workerCfg); err != nil {
+				logger.L.Error().Err(err).Str("worker_name", workerCfg.WorkerName).Msg("创建 worker 到数据库失败")
+				c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "创建 worker 失败: " + err.Error()})
+				return
+			}
+			persisted[workerCfg.WorkerName] = struct{}{}
+		}
+
+		taskID := node.TaskID
+		if taskID == "" {
+			taskID = asynqx.NewTaskID()
+		}
+		if _, dup := seen[taskID]; dup {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: fmt.Sprintf("tasks[%d]: task_id 重复: %s", i, taskID)})
+			return
+		}
+		seen[taskID] = struct{}{}
+
+		t := newPendingTask(node.CreateTaskRequest, taskID, workerCfg.FullQueueName(node.Queue, node.Priority), "")
+		t.Status = string(model.TaskStatusWaiting)
+		t.DependsOn = dedupStrings(node.DependsOn)
+		tasks = append(tasks, t)
+	}
+
+	if err := validateWorkflowGraph(tasks); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	h.startWorkflow(c, req.Name, tasks)
+}
+
+// validateNode 校验工作流中的单个任务
+func (h *WorkflowHandler) validateNode(node *dto.WorkflowTaskRequest) error {
+	if err := validateCreateTaskRequest(&node.CreateTaskRequest); err != nil {
+		return err
+	}
+	// 任务在上游完成后才入队，延迟参数没有明确语义
+	if node.DelaySeconds != 0 || node.RunAt != nil {
+		return fmt.Errorf("工作流任务不支持 delay_seconds/run_at")
+	}
+	return nil
+}
+
+// startWorkflow 持久化工作流及其任务，并入队无依赖的任务
+func (h *WorkflowHandler) startWorkflow(c *gin.Context, name string, tasks []repository.Task) {
+	ctx := c.Request.Context()
+	workflowID := asynqx.NewTaskID()
+
+	err := h.tasks.workflowRepo.CreateWorkflow(ctx, repository.Workflow{
+		WorkflowID: workflowID,
+		Name:       name,
+		Status:     string(model.WorkflowStatusRunning),
+	}, tasks)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := h.tasks.advanceWorkflow(ctx, workflowID); err != nil {
+		logger.L.Error().Err(err).Str("workflow_id", workflowID).Msg("启动工作流失败")
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	h.respondWorkflow(c, workflowID)
+}
+
+// ListWorkflows godoc
+// @Summary 查询工作流列表
+// @Description 分页查询工作流，可按状态过滤
+// @Tags Workflows
+// @Produce json
+// @Param status query string false "状态：running/success/failed"
+// @Param limit query int false "每页数量" default(50)
+// @Param offset query int false "偏移量" default(0)
+// @Success 200 {object} dto.WorkflowListResponse
+// @Failure 501 {object} dto.ErrorResponse
+// @Router /workflows [get]
+func (h *WorkflowHandler) ListWorkflows(c *gin.Context) {
+	if h.tasks.workflowRepo == nil {
+		c.JSON(http.StatusNotImplemented, dto.ErrorResponse{Error: "Postgres 未配置"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	status := c.DefaultQuery("status", "")
+
+	items, err := h.tasks.workflowRepo.ListWorkflows(c.Request.Context(), status, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	total, err := h.tasks.workflowRepo.CountWorkflows(c.Request.Context(), status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.WorkflowListResponse{Items: items, Total: total})
+}
+
+// GetWorkflow godoc
+// @Summary 获取工作流详情
+// @Description 返回工作流状态、全部任务（含 depends_on）及各状态任务数
+// @Tags Workflows
+// @Produce json
+// @Param workflow_id path string true "工作流 ID"
+// @Success 200 {object} dto.WorkflowResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 501 {object} dto.ErrorResponse
+// @Router /workflows/{workflow_id} [get]
+func (h *WorkflowHandler) GetWorkflow(c *gin.Context) {
+	if h.tasks.workflowRepo == nil {
+		c.JSON(http.StatusNotImplemented, dto.ErrorResponse{Error: "Postgres 未配置"})
+		return
+	}
+	h.respondWorkflow(c, c.Param("workflow_id"))
+}
+
+// ReplayWorkflow godoc
+// @Summary 重放工作流
+// @Description 以相同的任务与依赖关系创建一个新的工作流（任务 ID 重新生成），原工作流必须已结束
+// @Tags Workflows
+// @Produce json
+// @Param workflow_id path string true "工作流 ID"
+// @Success 200 {object} dto.WorkflowResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 501 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Router /workflows/{workflow_id}/replay [post]
+func (h *WorkflowHandler) ReplayWorkflow(c *gin.Context) {
+	if h.tasks.asynqClient == nil {
+		c.JSON(http.StatusServiceUnavailable, dto.ErrorResponse{Error: "asynq client 未配置"})
+		return
+	}
+	if h.tasks.taskRepo == nil || h.tasks.workflowRepo == nil {
+		c.JSON(http.StatusNotImplemented, dto.ErrorResponse{Error: "Postgres 未配置"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	wf, err := h.tasks.workflowRepo.GetWorkflow(ctx, c.Param("workflow_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "workflow 不存在"})
+		return
+	}
+	if wf.Status == string(model.WorkflowStatusRunning) {
+		c.JSON(http.StatusConflict, dto.ErrorResponse{Error: "工作流仍在运行中"})
+		return
+	}
+
+	oldTasks, err := h.tasks.workflowRepo.ListWorkflowTasks(ctx, wf.WorkflowID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// 重新生成任务 ID，并按新 ID 改写依赖关系
+	idMap := make(map[string]string, len(oldTasks))
+	for _, t := range oldTasks {
+		idMap[t.TaskID] = asynqx.NewTaskID()
+	}
+	tasks := make([]repository.Task, len(oldTasks))
+	for i, t := range oldTasks {
+		nt := newRequeuedTask(t, idMap[t.TaskID])
+		nt.Status = string(model.TaskStatusWaiting)
+		nt.DependsOn = make([]string, len(t.DependsOn))
+		for j, dep := range t.DependsOn {
+			nt.DependsOn[j] = idMap[dep]
+		}
+		tasks[i] = nt
+	}
+
+	h.startWorkflow(c, wf.Name, tasks)
+}
+
+// respondWorkflow 返回工作流详情
+func (h *WorkflowHandler) respondWorkflow(c *gin.Context, workflowID string) {
+	ctx := c.Request.Context()
+	wf, err := h.tasks.workflowRepo.GetWorkflow(ctx, workflowID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "workflow 不存在"})
+		return
+	}
+	tasks, err := h.tasks.workflowRepo.ListWorkflowTasks(ctx, workflowID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	progress := make(map[string]int)
+	for _, t := range tasks {
+		progress[t.Status]++
+	}
+	c.JSON(http.StatusOK, dto.WorkflowResponse{Item: wf, Tasks: tasks, Progress: progress})
+}
+
+// dedupStrings 去重并保持顺序
+func dedupStrings(in []string) []string {
+	if len(in) == 0 {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(in))
+	out := make([]string, 0, len(in))
+	for _, s := range in {
+		if _, ok := seen[s]; ok {
+			continue
+		}
+		seen[s] = struct{}{}
+		out = append(out, s)
+	}
+	return out
+}
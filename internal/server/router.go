@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hibiken/asynq"
@@ -27,9 +28,22 @@ type Deps struct {
 	// AsynqClient 用于入队
 	AsynqClient *asynq.Client
 
+	// AsynqInspector 用于查询/删除/取消 Redis 中的任务
+	AsynqInspector *asynq.Inspector
+
 	// 可选：若提供则会把数据落到 Postgres（用于列表/详情/报表）
-	WorkerRepo repository.WorkerRepository
-	TaskRepo   repository.TaskRepository
+	WorkerRepo   repository.WorkerRepository
+	TaskRepo     repository.TaskRepository
+	WorkflowRepo repository.WorkflowRepository
+	ScheduleRepo repository.ScheduleRepository
+	BulkJobRepo  repository.BulkJobRepository
+
+	// 可选：幂等记录存储，提供后创建任务接口支持 Idempotency-Key
+	IdempotencyStore middleware.IdempotencyStore
+	IdempotencyTTL   time.Duration
+
+	// 可选：由调用方创建的 TaskHandler（与周期任务调度器共用，关闭时调用 Close），为空时自动创建
+	TaskHandler *handler.TaskHandler
 
 	// HealthChecker 健康检查器
 	HealthChecker *healthcheck.HealthChecker
@@ -58,8 +72,15 @@ func NewRouter(deps Deps) http.Handler {
 	// 创建各个 handler 实例
 	healthHandler := handler.NewHealthHandler(deps.HealthChecker)
 	workerHandler := handler.NewWorkerHandler(deps.WorkerStore, deps.WorkerRepo, deps.TaskRepo)
-	taskHandler := handler.NewTaskHandler(deps.AsynqClient, deps.TaskRepo, deps.WorkerRepo, deps.WorkerStore)
-	queueHandler := handler.NewQueueHandler(deps.AsynqClient, deps.WorkerStore)
+	taskHandler := deps.TaskHandler
+	if taskHandler == nil {
+		taskHandler = handler.NewTaskHandler(deps.AsynqClient, deps.AsynqInspector, deps.TaskRepo, deps.WorkerRepo, deps.WorkflowRepo, deps.WorkerStore)
+	}
+	workflowHandler := handler.NewWorkflowHandler(taskHandler)
+	scheduleHandler := handler.NewScheduleHandler(deps.ScheduleRepo, taskHandler)
+	bulkJobHandler := handler.NewBulkJobHandler(deps.BulkJobRepo, taskHandler)
+	queueHandler := handler.NewQueueHandler(deps.AsynqClient, deps.AsynqInspector, deps.TaskRepo, deps.WorkerStore)
+	idempotency := middleware.Idempotency(deps.IdempotencyStore, deps.IdempotencyTTL)
 
 	// 健康检查路由
 	r.GET("/healthz", healthHandler.Liveness)
@@ -84,15 +105,47 @@ func NewRouter(deps Deps) http.Handler {
 		api.POST("/workers/register", workerHandler.RegisterWorker)
 
 		// Task 相关路由
-		api.POST("/tasks", taskHandler.CreateTask)
+		api.POST("/tasks", idempotency, taskHandler.CreateTask)
+		api.POST("/tasks/batch", idempotency, taskHandler.BatchCreateTasks)
+		api.POST("/tasks/import", taskHandler.ImportTasks)
 		api.GET("/tasks", taskHandler.ListTasks)
+		api.GET("/tasks/export", taskHandler.ExportTasks)
 		api.GET("/tasks/:task_id", middleware.ValidateTaskIDParam(), taskHandler.GetTask)
 		api.POST("/tasks/:task_id/replay", middleware.ValidateTaskIDParam(), taskHandler.ReplayTask)
+		api.POST("/tasks/:task_id/cancel", middleware.ValidateTaskIDParam(), taskHandler.CancelTask)
+		api.GET("/tasks/:task_id/attempts", middleware.ValidateTaskIDParam(), taskHandler.ListAttempts)
+		api.GET("/tasks/:task_id/result", middleware.ValidateTaskIDParam(), taskHandler.GetTaskResult)
 		api.POST("/tasks/:task_id/report-attempt", middleware.ValidateTaskIDParam(), taskHandler.ReportAttempt)
+		api.POST("/tasks/:task_id/progress", middleware.ValidateTaskIDParam(), taskHandler.ReportProgress)
+		api.POST("/tasks/:task_id/logs", middleware.ValidateTaskIDParam(), taskHandler.AppendTaskLogs)
+		api.GET("/tasks/:task_id/logs", middleware.ValidateTaskIDParam(), taskHandler.ListTaskLogs)
 		api.POST("/tasks/batch-retry", taskHandler.BatchRetry)
 
+		// Workflow 相关路由
+		api.POST("/workflows", workflowHandler.CreateWorkflow)
+		api.GET("/workflows", workflowHandler.ListWorkflows)
+		api.GET("/workflows/:workflow_id", middleware.ValidateWorkflowIDParam(), workflowHandler.GetWorkflow)
+		api.POST("/workflows/:workflow_id/replay", middleware.ValidateWorkflowIDParam(), workflowHandler.ReplayWorkflow)
+
+		// Schedule 相关路由
+		api.POST("/schedules", scheduleHandler.CreateSchedule)
+		api.GET("/schedules", scheduleHandler.ListSchedules)
+		api.GET("/schedules/:schedule_id", middleware.ValidateScheduleIDParam(), scheduleHandler.GetSchedule)
+		api.PUT("/schedules/:schedule_id", middleware.ValidateScheduleIDParam(), scheduleHandler.UpdateSchedule)
+		api.DELETE("/schedules/:schedule_id", middleware.ValidateScheduleIDParam(), scheduleHandler.DeleteSchedule)
+		api.GET("/schedules/:schedule_id/runs", middleware.ValidateScheduleIDParam(), scheduleHandler.ListScheduleRuns)
+		api.POST("/schedules/:schedule_id/trigger", middleware.ValidateScheduleIDParam(), scheduleHandler.TriggerSchedule)
+
+		// 批量操作相关路由
+		api.POST("/bulk-jobs", bulkJobHandler.CreateBulkJob)
+		api.GET("/bulk-jobs", bulkJobHandler.ListBulkJobs)
+		api.GET("/bulk-jobs/:job_id", middleware.ValidateBulkJobIDParam(), bulkJobHandler.GetBulkJob)
+		api.DELETE("/bulk-jobs/:job_id", middleware.ValidateBulkJobIDParam(), bulkJobHandler.CancelBulkJob)
+		api.GET("/bulk-jobs/:job_id/items", middleware.ValidateBulkJobIDParam(), bulkJobHandler.ListBulkJobItems)
+
 		// Queue 相关路由
 		api.GET("/queues/stats", queueHandler.GetQueueStats)
+		api.GET("/queues/:full_queue/tasks", queueHandler.ListQueueTasks)
 		api.POST("/queues/clear", queueHandler.ClearQueue)
 		api.POST("/queues/clear-dead", queueHandler.ClearDeadQueue)
 	}
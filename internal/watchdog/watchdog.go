@@ -0,0 +1,311 @@
+package watchdog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/azhengyongqin/asynq-hub/internal/config"
+	"github.com/azhengyongqin/asynq-hub/internal/logger"
+	"github.com/azhengyongqin/asynq-hub/internal/metrics"
+	"github.com/azhengyongqin/asynq-hub/internal/model"
+	"github.com/azhengyongqin/asynq-hub/internal/periodic"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+)
+
+const (
+	// batchPause 两批检查之间的停顿，避免集中访问 Redis 与数据库
+	batchPause = 100 * time.Millisecond
+	// activePageSize 查询 asynq active 列表的分页大小
+	activePageSize = 500
+)
+
+// Store 失联检测用到的任务存储操作（repository.TaskRepository 满足该接口）
+type Store interface {
+	ListOpenAttempts(ctx context.Context, startedBefore time.Time, afterID int64, limit int) ([]repository.OpenAttempt, error)
+	CloseAttempt(ctx context.Context, taskID string, attempt int, status, errMsg string, finishedAt time.Time) (bool, error)
+	TransitionTaskStatus(ctx context.Context, taskID, from, to, asynqTaskID, lastError string) (bool, error)
+	InsertTaskLogs(ctx context.Context, logs []repository.TaskLog) error
+}
+
+// Inspector 查询任务在 Redis 中的实际状态（*asynq.Inspector 满足该接口）
+type Inspector interface {
+	GetTaskInfo(queue, id string) (*asynq.TaskInfo, error)
+	ListActiveTasks(queue string, opts ...asynq.ListOption) ([]*asynq.TaskInfo, error)
+}
+
+// Handler 遗弃执行的后续处理，由 handler.TaskHandler 实现
+type Handler interface {
+	// FinishTask 任务进入终态后入队回调任务、推进工作流
+	FinishTask(ctx context.Context, task *repository.Task)
+	// RequeueTask 按原 task_id 重新入队，返回新的 asynq 任务 ID
+	RequeueTask(ctx context.Context, task *repository.Task) (string, error)
+}
+
+// Result 一轮检测的结果
+type Result struct {
+	Checked   int // 检查的执行尝试数
+	Abandoned int // 判定为遗弃并处理的执行尝试数
+}
+
+// verdict 对一次执行的判定：执行尝试标记为 lost 或 timeout
+type verdict struct {
+	status model.TaskStatus
+	reason string
+}
+
+// outcome 遗弃执行所属任务的后续状态
+type outcome struct {
+	to        model.TaskStatus
+	requeue   bool   // 重新入队（to 为 pending）
+	lastError string // 为空时使用判定原因（to 为 success 时清空）
+}
+
+// activeSet 一个队列在 asynq 中正在执行的任务（按 asynq 任务 ID 索引）
+type activeSet struct {
+	tasks map[string]*asynq.TaskInfo
+	err   error
+}
+
+// Job 失联执行检测后台任务：遍历状态为 running 且未收到最终上报的执行尝试，
+// 结合任务超时时间、asynq 的 active 列表与 worker 心跳判断执行是否已被遗弃，
+// 将执行尝试标记为 lost/timeout，并按任务在 Redis 中的状态修正任务（asynq 会重新执行的置为 fail）。
+type Job struct {
+	store     Store
+	inspector Inspector
+	handler   Handler
+	cfg       config.WatchdogConfig
+	now       func() time.Time
+
+	loop periodic.Loop
+}
+
+// New 创建失联检测任务，handler 可以为 nil（此时不触发回调、不重新入队）
+func New(store Store, inspector Inspector, handler Handler, cfg config.WatchdogConfig) *Job {
+	return &Job{store: store, inspector: inspector, handler: handler, cfg: cfg, now: time.Now}
+}
+
+// Start 启动后台检测（立即执行一次，之后按 Interval 执行）
+func (j *Job) Start() {
+	j.loop.Start(j.cfg.Interval, func(ctx context.Context) { _, _ = j.RunOnce(ctx) })
+}
+
+// Stop 停止后台检测并等待当前批次结束
+func (j *Job) Stop() {
+	j.loop.Stop()
+}
+
+// RunOnce 执行一轮检测。单个任务查询 Redis 失败时跳过该任务，数据库错误时结束本轮。
+func (j *Job) RunOnce(ctx context.Context) (Result, error) {
+	var res Result
+	now := j.now()
+	// 开始不足宽限时长的执行还可能在 active 列表登记之前或最终上报途中，不检查
+	before := now.Add(-j.cfg.Grace)
+	active := make(map[string]*activeSet)
+
+	var afterID int64
+	for {
+		attempts, err := j.store.ListOpenAttempts(ctx, before, afterID, j.cfg.BatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				metrics.RecordError("watchdog", "list")
+				logger.L.Error().Err(err).Msg("查询执行中的任务失败")
+			}
+			return res, err
+		}
+
+		for _, a := range attempts {
+			afterID = a.Task.ID
+			res.Checked++
+
+			set := j.activeTasks(active, a.Task.Queue)
+			if set.err != nil {
+				metrics.RecordError("watchdog", "inspect")
+				logger.L.Warn().Err(set.err).Str("queue", a.Task.Queue).Msg("查询 asynq 执行中任务失败")
+				continue
+			}
+			v, ok := j.judge(a, set.tasks[a.Task.AsynqID()], now)
+			if !ok {
+				continue
+			}
+			o, err := j.outcome(a.Task)
+			if err != nil {
+				metrics.RecordError("watchdog", "inspect")
+				logger.L.Warn().Err(err).Str("task_id", a.Task.TaskID).Msg("查询任务 Redis 状态失败")
+				continue
+			}
+
+			handled, err := j.apply(ctx, a, v, o, now)
+			if err != nil {
+				if ctx.Err() == nil {
+					metrics.RecordError("watchdog", "update")
+					logger.L.Error().Err(err).Str("task_id", a.Task.TaskID).Msg("处理失联执行失败")
+				}
+				return res, err
+			}
+			if handled {
+				res.Abandoned++
+			}
+		}
+
+		if len(attempts) < j.cfg.BatchSize {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return res, ctx.Err()
+		case <-time.After(batchPause):
+		}
+	}
+
+	metrics.WatchdogLastSuccess.Set(float64(j.now().Unix()))
+	if res.Abandoned > 0 {
+		logger.L.Info().Int("checked", res.Checked).Int("abandoned", res.Abandoned).Msg("失联执行检测完成")
+	}
+	return res, nil
+}
+
+// activeTasks 查询队列在 asynq 中正在执行的任务（每轮每个队列只查询一次）
+func (j *Job) activeTasks(cache map[string]*activeSet, queue string) *activeSet {
+	if set, ok := cache[queue]; ok {
+		return set
+	}
+	set := &activeSet{tasks: make(map[string]*asynq.TaskInfo)}
+	cache[queue] = set
+	for page := 1; ; page++ {
+		infos, err := j.inspector.ListActiveTasks(queue, asynq.Page(page), asynq.PageSize(activePageSize))
+		if errors.Is(err, asynq.ErrQueueNotFound) {
+			return set
+		}
+		if err != nil {
+			set.err = err
+			return set
+		}
+		for _, info := range infos {
+			set.tasks[info.ID] = info
+		}
+		if len(infos) < activePageSize {
+			return set
+		}
+	}
+}
+
+// judge 判断执行是否已被遗弃：超过超时时间（另加宽限）仍未结束判定为 timeout；
+// 不在 asynq 的 active 列表中、被 asynq 标记为孤儿（租约过期）或 worker 心跳超时判定为 lost
+func (j *Job) judge(a repository.OpenAttempt, active *asynq.TaskInfo, now time.Time) (verdict, bool) {
+	if a.TimeoutSeconds > 0 {
+		deadline := a.StartedAt.Add(time.Duration(a.TimeoutSeconds)*time.Second + j.cfg.Grace)
+		if now.After(deadline) {
+			return verdict{status: model.TaskStatusTimeout, reason: fmt.Sprintf("执行超过超时时间 %ds 仍未上报结果", a.TimeoutSeconds)}, true
+		}
+	}
+
+	switch {
+	case active == nil:
+		return verdict{status: model.TaskStatusLost, reason: "asynq 中已没有该任务的执行，且未收到最终上报"}, true
+	case active.IsOrphaned:
+		return verdict{status: model.TaskStatusLost, reason: "asynq 将该执行标记为孤儿（执行它的 worker 已退出）"}, true
+	case j.cfg.HeartbeatTimeout > 0 && a.LastHeartbeatAt != nil && now.Sub(*a.LastHeartbeatAt) > j.cfg.HeartbeatTimeout:
+		return verdict{status: model.TaskStatusLost, reason: fmt.Sprintf("worker 心跳已超时（最近一次心跳 %s）", a.LastHeartbeatAt.Format(time.RFC3339))}, true
+	}
+	return verdict{}, false
+}
+
+// outcome 根据任务在 Redis 中的状态决定任务的后续状态：
+// asynq 仍会执行的（等待、延迟、重试或仍在执行）置为 fail，已归档置为 dead，已完成置为 success；
+// 已不在 Redis 中的按配置重新入队，否则置为 lost
+func (j *Job) outcome(t repository.Task) (outcome, error) {
+	info, err := j.inspector.GetTaskInfo(t.Queue, t.AsynqID())
+	switch {
+	case errors.Is(err, asynq.ErrTaskNotFound), errors.Is(err, asynq.ErrQueueNotFound):
+		if j.cfg.Requeue && j.handler != nil {
+			return outcome{to: model.TaskStatusPending, requeue: true}, nil
+		}
+		return outcome{to: model.TaskStatusLost}, nil
+	case err != nil:
+		return outcome{}, err
+	}
+
+	switch info.State {
+	case asynq.TaskStateArchived:
+		return outcome{to: model.TaskStatusDead, lastError: info.LastErr}, nil
+	case asynq.TaskStateCompleted:
+		return outcome{to: model.TaskStatusSuccess}, nil
+	default:
+		return outcome{to: model.TaskStatusFail}, nil
+	}
+}
+
+// apply 结束执行尝试并按条件更新任务状态，返回是否处理成功（已收到最终上报或状态已被并发修改时返回 false）
+func (j *Job) apply(ctx context.Context, a repository.OpenAttempt, v verdict, o outcome, now time.Time) (bool, error) {
+	t := a.Task
+	ok, err := j.store.CloseAttempt(ctx, t.TaskID, a.Attempt, string(v.status), v.reason, now)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	lastError := o.lastError
+	if lastError == "" && o.to != model.TaskStatusSuccess {
+		lastError = v.reason
+	}
+	ok, err = j.store.TransitionTaskStatus(ctx, t.TaskID, string(model.TaskStatusRunning), string(o.to), "", lastError)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	action := string(o.to)
+	if o.requeue {
+		action = "requeued"
+	}
+	metrics.WatchdogAbandonedTotal.WithLabelValues(string(v.status), action).Inc()
+	message := fmt.Sprintf("第 %d 次执行判定为 %s：%s，任务状态 running → %s", a.Attempt, v.status, v.reason, o.to)
+	logger.L.Warn().Str("task_id", t.TaskID).Int("attempt", a.Attempt).Str("worker_name", a.WorkerName).Str("to", string(o.to)).Msg(message)
+	j.log(ctx, t.TaskID, a.Attempt, message)
+
+	t.Status = string(o.to)
+	t.LastError = lastError
+	if o.requeue {
+		return true, j.requeue(ctx, &t)
+	}
+	if o.to.IsTerminal() && j.handler != nil {
+		j.handler.FinishTask(ctx, &t)
+	}
+	return true, nil
+}
+
+// requeue 重新入队已置为 pending 的任务并回写 asynq 任务 ID；入队失败时任务置为 lost
+func (j *Job) requeue(ctx context.Context, t *repository.Task) error {
+	asynqID, err := j.handler.RequeueTask(ctx, t)
+	if err != nil {
+		reason := "重新入队失败: " + err.Error()
+		logger.L.Warn().Err(err).Str("task_id", t.TaskID).Msg("失联任务重新入队失败")
+		ok, terr := j.store.TransitionTaskStatus(ctx, t.TaskID, string(model.TaskStatusPending), string(model.TaskStatusLost), "", reason)
+		if terr != nil || !ok {
+			return terr
+		}
+		j.log(ctx, t.TaskID, t.LastAttempt, reason)
+		t.Status = string(model.TaskStatusLost)
+		t.LastError = reason
+		j.handler.FinishTask(ctx, t)
+		return nil
+	}
+
+	_, err = j.store.TransitionTaskStatus(ctx, t.TaskID, string(model.TaskStatusPending), string(model.TaskStatusPending), asynqID, t.LastError)
+	return err
+}
+
+// log 写入一条任务日志，失败只记录到服务日志
+func (j *Job) log(ctx context.Context, taskID string, attempt int, message string) {
+	if err := j.store.InsertTaskLogs(ctx, []repository.TaskLog{{
+		TaskID:   taskID,
+		Attempt:  attempt,
+		Level:    "warn",
+		Message:  message,
+		LoggedAt: j.now(),
+	}}); err != nil {
+		logger.L.Warn().Err(err).Str("task_id", taskID).Msg("记录失联检测日志失败")
+	}
+}
@@ -0,0 +1,258 @@
+package watchdog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/azhengyongqin/asynq-hub/internal/config"
+	"github.com/azhengyongqin/asynq-hub/internal/model"
+	"github.com/azhengyongqin/asynq-hub/internal/repository"
+)
+
+// fakeStore 内存存储：open 为未结束的执行，closed 记录被结束的执行尝试状态
+type fakeStore struct {
+	open   []repository.OpenAttempt
+	status map[string]string
+	closed map[string]string
+	asynq  map[string]string // 回写的 asynq 任务 ID
+	logs   []repository.TaskLog
+	final  map[string]bool // 模拟最终上报已到达，结束执行尝试不命中
+}
+
+func newFakeStore(open ...repository.OpenAttempt) *fakeStore {
+	s := &fakeStore{
+		open:   open,
+		status: make(map[string]string),
+		closed: make(map[string]string),
+		asynq:  make(map[string]string),
+		final:  make(map[string]bool),
+	}
+	for _, a := range open {
+		s.status[a.Task.TaskID] = a.Task.Status
+	}
+	return s
+}
+
+func (s *fakeStore) ListOpenAttempts(_ context.Context, _ time.Time, afterID int64, limit int) ([]repository.OpenAttempt, error) {
+	var out []repository.OpenAttempt
+	for _, a := range s.open {
+		if a.Task.ID > afterID && len(out) < limit {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func (s *fakeStore) CloseAttempt(_ context.Context, taskID string, _ int, status, _ string, _ time.Time) (bool, error) {
+	if s.final[taskID] {
+		return false, nil
+	}
+	s.closed[taskID] = status
+	return true, nil
+}
+
+func (s *fakeStore) TransitionTaskStatus(_ context.Context, taskID, from, to, asynqTaskID, _ string) (bool, error) {
+	if s.status[taskID] != from {
+		return false, nil
+	}
+	s.status[taskID] = to
+	if asynqTaskID != "" {
+		s.asynq[taskID] = asynqTaskID
+	}
+	return true, nil
+}
+
+func (s *fakeStore) InsertTaskLogs(_ context.Context, logs []repository.TaskLog) error {
+	s.logs = append(s.logs, logs...)
+	return nil
+}
+
+// fakeInspector active 为各队列正在执行的任务，infos 为 GetTaskInfo 的结果（未配置的返回 ErrTaskNotFound）
+type fakeInspector struct {
+	active    map[string][]*asynq.TaskInfo
+	activeErr error
+	infos     map[string]*asynq.TaskInfo
+}
+
+func (i *fakeInspector) GetTaskInfo(_, id string) (*asynq.TaskInfo, error) {
+	if info, ok := i.infos[id]; ok {
+		return info, nil
+	}
+	return nil, asynq.ErrTaskNotFound
+}
+
+func (i *fakeInspector) ListActiveTasks(queue string, _ ...asynq.ListOption) ([]*asynq.TaskInfo, error) {
+	if i.activeErr != nil {
+		return nil, i.activeErr
+	}
+	return i.active[queue], nil
+}
+
+type fakeHandler struct {
+	finished   []string
+	requeued   []string
+	requeueErr error
+}
+
+func (h *fakeHandler) FinishTask(_ context.Context, t *repository.Task) {
+	h.finished = append(h.finished, t.TaskID+":"+t.Status)
+}
+
+func (h *fakeHandler) RequeueTask(_ context.Context, t *repository.Task) (string, error) {
+	if h.requeueErr != nil {
+		return "", h.requeueErr
+	}
+	h.requeued = append(h.requeued, t.TaskID)
+	return t.TaskID + "-new", nil
+}
+
+var now = time.Date(2026, 3, 12, 12, 0, 0, 0, time.UTC)
+
+func openAttempt(id int64, taskID string, started time.Duration, timeout int32) repository.OpenAttempt {
+	return repository.OpenAttempt{
+		Task:           repository.Task{ID: id, TaskID: taskID, Queue: "w:default:default", Status: "running", LastAttempt: 1},
+		Attempt:        1,
+		StartedAt:      now.Add(-started),
+		TimeoutSeconds: timeout,
+	}
+}
+
+func newTestJob(store Store, inspector Inspector, handler Handler, cfg config.WatchdogConfig) *Job {
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = 2
+	}
+	if cfg.Grace == 0 {
+		cfg.Grace = time.Minute
+	}
+	job := New(store, inspector, handler, cfg)
+	job.now = func() time.Time { return now }
+	return job
+}
+
+func TestJudge(t *testing.T) {
+	hb := func(ago time.Duration) *time.Time { t := now.Add(-ago); return &t }
+	running := &asynq.TaskInfo{State: asynq.TaskStateActive}
+
+	tests := []struct {
+		name    string
+		attempt repository.OpenAttempt
+		active  *asynq.TaskInfo
+		want    model.TaskStatus
+	}{
+		{name: "正常执行", attempt: openAttempt(1, "a", 5*time.Minute, 600), active: running},
+		{name: "超过超时加宽限", attempt: openAttempt(1, "a", 12*time.Minute, 600), active: running, want: model.TaskStatusTimeout},
+		{name: "超时但仍在宽限内", attempt: openAttempt(1, "a", 10*time.Minute+30*time.Second, 600), active: running},
+		{name: "不在 active 列表", attempt: openAttempt(1, "a", 5*time.Minute, 600), want: model.TaskStatusLost},
+		{name: "孤儿", attempt: openAttempt(1, "a", 5*time.Minute, 600), active: &asynq.TaskInfo{State: asynq.TaskStateActive, IsOrphaned: true}, want: model.TaskStatusLost},
+		{
+			name: "心跳超时",
+			attempt: func() repository.OpenAttempt {
+				a := openAttempt(1, "a", 5*time.Minute, 600)
+				a.LastHeartbeatAt = hb(10 * time.Minute)
+				return a
+			}(),
+			active: running,
+			want:   model.TaskStatusLost,
+		},
+		{
+			name: "心跳正常",
+			attempt: func() repository.OpenAttempt {
+				a := openAttempt(1, "a", 5*time.Minute, 600)
+				a.LastHeartbeatAt = hb(time.Minute)
+				return a
+			}(),
+			active: running,
+		},
+	}
+
+	job := newTestJob(nil, nil, nil, config.WatchdogConfig{HeartbeatTimeout: 2 * time.Minute})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, ok := job.judge(tt.attempt, tt.active, now)
+			assert.Equal(t, tt.want != "", ok)
+			assert.Equal(t, tt.want, v.status)
+		})
+	}
+
+	// 未开启心跳判断时忽略心跳时间
+	job = newTestJob(nil, nil, nil, config.WatchdogConfig{})
+	a := openAttempt(1, "a", 5*time.Minute, 600)
+	a.LastHeartbeatAt = hb(time.Hour)
+	_, ok := job.judge(a, running, now)
+	assert.False(t, ok)
+}
+
+func TestRunOnce(t *testing.T) {
+	store := newFakeStore(
+		openAttempt(1, "alive", 5*time.Minute, 600),
+		openAttempt(2, "will-retry", 5*time.Minute, 600),
+		openAttempt(3, "archived", 20*time.Minute, 600),
+		openAttempt(4, "gone", 5*time.Minute, 600),
+		openAttempt(5, "reported", 5*time.Minute, 600),
+	)
+	inspector := &fakeInspector{
+		active: map[string][]*asynq.TaskInfo{
+			"w:default:default": {{ID: "alive", State: asynq.TaskStateActive}, {ID: "archived", State: asynq.TaskStateActive}},
+		},
+		infos: map[string]*asynq.TaskInfo{
+			"alive":      {State: asynq.TaskStateActive},
+			"will-retry": {State: asynq.TaskStateRetry},
+			"archived":   {State: asynq.TaskStateArchived, LastErr: "context deadline exceeded"},
+		},
+	}
+	store.final["reported"] = true
+	handler := &fakeHandler{}
+
+	res, err := newTestJob(store, inspector, handler, config.WatchdogConfig{}).RunOnce(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, res.Checked)
+	assert.Equal(t, 3, res.Abandoned)
+	assert.Equal(t, "running", store.status["alive"])
+	assert.Equal(t, map[string]string{"will-retry": "lost", "archived": "timeout", "gone": "lost"}, store.closed)
+	assert.Equal(t, "fail", store.status["will-retry"], "asynq 会重新执行的任务置为 fail")
+	assert.Equal(t, "dead", store.status["archived"])
+	assert.Equal(t, "lost", store.status["gone"])
+	assert.Equal(t, "running", store.status["reported"], "最终上报已到达时不处理")
+	assert.Equal(t, []string{"archived:dead", "gone:lost"}, handler.finished)
+	assert.Empty(t, handler.requeued, "未开启重新入队")
+	assert.Len(t, store.logs, 3)
+}
+
+func TestRunOnce_Requeue(t *testing.T) {
+	store := newFakeStore(openAttempt(1, "gone", 5*time.Minute, 600))
+	handler := &fakeHandler{}
+	job := newTestJob(store, &fakeInspector{}, handler, config.WatchdogConfig{Requeue: true})
+
+	res, err := job.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Abandoned)
+	assert.Equal(t, "pending", store.status["gone"])
+	assert.Equal(t, "gone-new", store.asynq["gone"], "回写新的 asynq 任务 ID")
+	assert.Equal(t, []string{"gone"}, handler.requeued)
+	assert.Empty(t, handler.finished)
+
+	// 重新入队失败时置为 lost
+	store = newFakeStore(openAttempt(1, "broken", 5*time.Minute, 600))
+	handler = &fakeHandler{requeueErr: errors.New("redis down")}
+	_, err = newTestJob(store, &fakeInspector{}, handler, config.WatchdogConfig{Requeue: true}).RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "lost", store.status["broken"])
+	assert.Equal(t, []string{"broken:lost"}, handler.finished)
+}
+
+func TestRunOnce_InspectorError(t *testing.T) {
+	store := newFakeStore(openAttempt(1, "a", 5*time.Minute, 600))
+	job := newTestJob(store, &fakeInspector{activeErr: errors.New("connection refused")}, nil, config.WatchdogConfig{})
+
+	res, err := job.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, res.Abandoned, "无法查询 active 列表时不判定为失联")
+	assert.Equal(t, "running", store.status["a"])
+}
@@ -73,6 +73,15 @@ func (c *Config) FullQueueName(queueGroupName, priority string) string {
 	return fmt.Sprintf("%s:%s:%s", c.WorkerName, queueGroupName, priority)
 }
 
+// ParseFullQueueName 将完整队列名 workerName:queueGroupName:priority 拆分为三部分
+func ParseFullQueueName(fullQueue string) (workerName, queueGroupName, priority string, ok bool) {
+	parts := strings.Split(fullQueue, ":")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
+
 // QueueGroupNames 返回所有队列组名称
 func (c *Config) QueueGroupNames() []string {
 	names := make([]string, len(c.QueueGroups))
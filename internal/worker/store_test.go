@@ -176,6 +176,19 @@ func TestConfig_FullQueueName(t *testing.T) {
 	assert.Equal(t, "worker-1:web_crawl:low", cfg.FullQueueName("web_crawl", PriorityLow))
 }
 
+func TestParseFullQueueName(t *testing.T) {
+	workerName, group, priority, ok := ParseFullQueueName("worker-1:web_crawl:critical")
+	assert.True(t, ok)
+	assert.Equal(t, "worker-1", workerName)
+	assert.Equal(t, "web_crawl", group)
+	assert.Equal(t, PriorityCritical, priority)
+
+	for _, q := range []string{"", "worker-1", "worker-1:web_crawl", "worker-1::low", "a:b:c:d"} {
+		_, _, _, ok := ParseFullQueueName(q)
+		assert.False(t, ok, q)
+	}
+}
+
 func TestConfig_QueueGroupNames(t *testing.T) {
 	cfg := Config{
 		WorkerName: "worker-1",
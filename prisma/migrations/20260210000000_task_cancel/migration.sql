@@ -0,0 +1,6 @@
+-- 迁移：支持任务取消
+-- 1. 记录 asynq 侧的任务 ID，用于通过 Inspector 删除/取消任务
+ALTER TABLE "task" ADD COLUMN "asynq_task_id" TEXT;
+
+-- 2. status 新增终态 canceled（TEXT 字段，无需变更类型）
+COMMENT ON COLUMN "task"."status" IS 'pending/running/success/fail/dead/canceled';
@@ -0,0 +1,6 @@
+-- 迁移：支持任务级重试/超时/截止时间/保留时长覆盖
+-- 为空时使用 worker 默认配置；重放与批量重试时沿用
+ALTER TABLE "task" ADD COLUMN "max_retry" INTEGER;
+ALTER TABLE "task" ADD COLUMN "timeout_seconds" INTEGER;
+ALTER TABLE "task" ADD COLUMN "deadline" TIMESTAMPTZ(6);
+ALTER TABLE "task" ADD COLUMN "retention_seconds" INTEGER;
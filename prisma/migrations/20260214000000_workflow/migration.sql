@@ -0,0 +1,28 @@
+-- 迁移：支持任务依赖图（DAG 工作流）
+-- 1. 工作流表
+CREATE TABLE "workflow" (
+    "id" BIGSERIAL NOT NULL,
+    "workflow_id" TEXT NOT NULL,
+    "name" TEXT NOT NULL,
+    "status" TEXT NOT NULL,
+    "created_at" TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "workflow_pkey" PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX "workflow_workflow_id_key" ON "workflow"("workflow_id");
+CREATE INDEX "idx_workflow_status_created_at" ON "workflow"("status", "created_at" DESC);
+
+COMMENT ON COLUMN "workflow"."status" IS 'running/success/failed';
+
+-- 2. 任务所属工作流及依赖的上游任务
+ALTER TABLE "task" ADD COLUMN "workflow_id" TEXT;
+ALTER TABLE "task" ADD COLUMN "depends_on" JSONB;
+
+CREATE INDEX "idx_task_workflow_id" ON "task"("workflow_id");
+
+ALTER TABLE "task" ADD CONSTRAINT "task_workflow_id_fkey" FOREIGN KEY ("workflow_id") REFERENCES "workflow"("workflow_id") ON DELETE SET NULL ON UPDATE CASCADE;
+
+-- 3. status 新增 waiting（等待上游）与 skipped（上游失败后跳过）
+COMMENT ON COLUMN "task"."status" IS 'waiting/pending/running/success/fail/dead/canceled/skipped';
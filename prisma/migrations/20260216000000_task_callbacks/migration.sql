@@ -0,0 +1,9 @@
+-- 迁移：支持任务成功/失败回调
+-- 1. 回调任务定义（worker、队列组、优先级、payload 模板）
+ALTER TABLE "task" ADD COLUMN "on_success" JSONB;
+ALTER TABLE "task" ADD COLUMN "on_failure" JSONB;
+
+-- 2. 回调任务记录触发它的父任务
+ALTER TABLE "task" ADD COLUMN "parent_task_id" TEXT;
+
+CREATE INDEX "idx_task_parent_task_id" ON "task"("parent_task_id");
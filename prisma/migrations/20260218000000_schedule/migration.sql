@@ -0,0 +1,44 @@
+-- 迁移：持久化周期任务（cron）调度
+-- 1. 周期任务定义
+CREATE TABLE "schedule" (
+    "id" BIGSERIAL NOT NULL,
+    "schedule_id" TEXT NOT NULL,
+    "name" TEXT NOT NULL,
+    "cron_spec" TEXT NOT NULL,
+    "timezone" TEXT NOT NULL DEFAULT 'UTC',
+    "worker_name" TEXT NOT NULL,
+    "queue" TEXT NOT NULL,
+    "priority" TEXT NOT NULL,
+    "payload" JSONB NOT NULL,
+    "enabled" BOOLEAN NOT NULL DEFAULT true,
+    "last_run_at" TIMESTAMPTZ(6),
+    "next_run_at" TIMESTAMPTZ(6),
+    "last_task_id" TEXT,
+    "last_error" TEXT,
+    "created_at" TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "schedule_pkey" PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX "schedule_schedule_id_key" ON "schedule"("schedule_id");
+CREATE INDEX "idx_schedule_enabled" ON "schedule"("enabled");
+
+COMMENT ON COLUMN "schedule"."queue" IS '队列组名称';
+COMMENT ON COLUMN "schedule"."priority" IS 'critical/default/low';
+
+-- 2. 触发历史
+CREATE TABLE "schedule_run" (
+    "id" BIGSERIAL NOT NULL,
+    "schedule_id" TEXT NOT NULL,
+    "fired_at" TIMESTAMPTZ(6) NOT NULL,
+    "task_id" TEXT,
+    "status" TEXT NOT NULL,
+    "error" TEXT,
+
+    CONSTRAINT "schedule_run_pkey" PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_schedule_run_schedule_fired_at" ON "schedule_run"("schedule_id", "fired_at" DESC);
+
+COMMENT ON COLUMN "schedule_run"."status" IS 'enqueued/failed';
@@ -0,0 +1,3 @@
+-- 迁移：支持任务结果存储
+-- worker 在 success 上报中携带的结果，通过 GET /tasks/:task_id/result 获取
+ALTER TABLE "task" ADD COLUMN "result" JSONB;
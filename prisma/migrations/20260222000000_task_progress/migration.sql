@@ -0,0 +1,15 @@
+-- 迁移：任务执行进度
+-- 每个任务的每次尝试只保存最新一条进度（worker 通过 sdk.ReportProgress 上报）
+CREATE TABLE "task_progress" (
+    "task_id" TEXT NOT NULL,
+    "attempt" INTEGER NOT NULL,
+    "percent" INTEGER NOT NULL,
+    "message" TEXT,
+    "updated_at" TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "task_progress_pkey" PRIMARY KEY ("task_id", "attempt")
+);
+
+ALTER TABLE "task_progress" ADD CONSTRAINT "task_progress_task_id_fkey" FOREIGN KEY ("task_id") REFERENCES "task"("task_id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+COMMENT ON COLUMN "task_progress"."percent" IS '0-100';
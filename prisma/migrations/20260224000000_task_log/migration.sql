@@ -0,0 +1,18 @@
+-- 迁移：任务执行日志
+-- worker 通过 sdk.Logger(ctx) 记录的日志，按尝试分批上报
+CREATE TABLE "task_log" (
+    "id" BIGSERIAL NOT NULL,
+    "task_id" TEXT NOT NULL,
+    "attempt" INTEGER NOT NULL,
+    "level" TEXT NOT NULL,
+    "message" TEXT NOT NULL,
+    "logged_at" TIMESTAMPTZ(6) NOT NULL,
+
+    CONSTRAINT "task_log_pkey" PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_task_log_task_attempt_id" ON "task_log"("task_id", "attempt", "id");
+
+ALTER TABLE "task_log" ADD CONSTRAINT "task_log_task_id_fkey" FOREIGN KEY ("task_id") REFERENCES "task"("task_id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+COMMENT ON COLUMN "task_log"."level" IS 'debug/info/warn/error';
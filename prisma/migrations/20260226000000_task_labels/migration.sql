@@ -0,0 +1,6 @@
+-- 迁移：任务标签
+-- 创建任务时携带的 key/value 标签（如 tenant、source、batch_id），用于按标签过滤任务与统计
+ALTER TABLE "task" ADD COLUMN "labels" JSONB NOT NULL DEFAULT '{}';
+
+-- 支持 labels @> '{"tenant":"acme"}' 包含查询
+CREATE INDEX "idx_task_labels" ON "task" USING GIN ("labels" jsonb_path_ops);
@@ -0,0 +1,11 @@
+-- 迁移：任务检索
+-- 1. last_error 子串搜索（ILIKE '%...%'）使用 trigram 索引
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX "idx_task_last_error_trgm" ON "task" USING GIN ("last_error" gin_trgm_ops);
+
+-- 2. payload JSONB 包含（@>）与 jsonpath 谓词（@@）查询
+CREATE INDEX "idx_task_payload" ON "task" USING GIN ("payload" jsonb_path_ops);
+
+-- 3. 时间范围 / 优先级过滤与排序
+CREATE INDEX "idx_task_created_at" ON "task"("created_at" DESC);
+CREATE INDEX "idx_task_priority_created_at" ON "task"("priority", "created_at" DESC);
@@ -0,0 +1,7 @@
+-- 迁移：keyset（游标）分页
+-- 任务按 (created_at, id)、执行尝试按 (task_id, started_at, id) 翻页，索引包含 id 以便 (a, id) < (?, ?) 直接走索引
+DROP INDEX IF EXISTS "idx_task_created_at";
+CREATE INDEX "idx_task_created_at_id" ON "task"("created_at" DESC, "id" DESC);
+
+DROP INDEX IF EXISTS "idx_attempt_task_key_started_at";
+CREATE INDEX "idx_attempt_task_started_at_id" ON "task_attempt"("task_id", "started_at" DESC, "id" DESC);
@@ -0,0 +1,5 @@
+-- 迁移：记录任务重放链路
+-- 重放产生的新任务指向被重放的原任务，可沿该列双向查询重放链
+ALTER TABLE "task" ADD COLUMN "replayed_from" TEXT;
+
+CREATE INDEX "idx_task_replayed_from" ON "task"("replayed_from");
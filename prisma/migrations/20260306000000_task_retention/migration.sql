@@ -0,0 +1,20 @@
+-- 迁移：任务历史保留策略
+-- 1. 归档表：RETENTION_MODE=archive 时，过期任务连同执行尝试以 JSONB 快照写入该表后再删除
+--    （使用快照而非复制列，task 表后续增加列时无需同步修改归档表）
+CREATE TABLE "task_archive" (
+    "task_id" TEXT NOT NULL,
+    "worker_name" TEXT NOT NULL,
+    "status" TEXT NOT NULL,
+    "created_at" TIMESTAMPTZ(6) NOT NULL,
+    "updated_at" TIMESTAMPTZ(6) NOT NULL,
+    "archived_at" TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "task" JSONB NOT NULL,
+    "attempts" JSONB NOT NULL DEFAULT '[]',
+
+    CONSTRAINT "task_archive_pkey" PRIMARY KEY ("task_id")
+);
+
+CREATE INDEX "idx_task_archive_worker_archived_at" ON "task_archive"("worker_name", "archived_at" DESC);
+
+-- 2. 按 worker 清理时按 (worker_name, status, updated_at) 查找过期任务
+CREATE INDEX "idx_task_worker_status_updated_at" ON "task"("worker_name", "status", "updated_at");
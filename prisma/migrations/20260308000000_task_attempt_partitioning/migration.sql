@@ -0,0 +1,64 @@
+-- 迁移：task_attempt 按 started_at 月份分区（UTC）
+-- 过期的执行尝试按整个分区删除，不再逐行 DELETE，避免表膨胀；按 started_at 范围过滤的统计查询只扫描相关月份的分区。
+-- 分区表的主键必须包含分区键，因此主键改为 (id, started_at)；id 仍由原序列生成，保持全局唯一。
+-- task 表不分区：task_id 的唯一约束（被执行尝试、日志等表引用）无法包含分区键。
+
+-- 1. 旧表改名，释放约束与索引名
+ALTER TABLE "task_attempt" RENAME TO "task_attempt_old";
+ALTER TABLE "task_attempt_old" RENAME CONSTRAINT "task_attempt_pkey" TO "task_attempt_old_pkey";
+ALTER TABLE "task_attempt_old" DROP CONSTRAINT "task_attempt_task_id_fkey";
+DROP INDEX "idx_attempt_task_started_at_id";
+DROP INDEX "idx_attempt_status_started_at";
+
+-- 2. 分区表
+CREATE TABLE "task_attempt" (
+    "id" BIGINT NOT NULL DEFAULT nextval('task_attempt_id_seq'),
+    "task_id" TEXT NOT NULL,
+    "asynq_task_id" TEXT,
+    "attempt" INTEGER NOT NULL,
+    "status" TEXT NOT NULL,
+    "started_at" TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "finished_at" TIMESTAMPTZ(6),
+    "duration_ms" INTEGER,
+    "error" TEXT,
+    "worker_name" TEXT,
+    "trace_id" TEXT,
+    "span_id" TEXT,
+
+    CONSTRAINT "task_attempt_pkey" PRIMARY KEY ("id", "started_at")
+) PARTITION BY RANGE ("started_at");
+
+-- 序列改归新表所有，删除旧表时不会一并删除
+ALTER SEQUENCE "task_attempt_id_seq" OWNED BY "task_attempt"."id";
+
+-- 默认分区兜底尚未创建分区的月份；后台任务创建分区时会把对应月份的行移入新分区
+CREATE TABLE "task_attempt_default" PARTITION OF "task_attempt" DEFAULT;
+
+-- 3. 月分区 task_attempt_pYYYYMM：从已有数据的最早月份到当前月之后 3 个月（之后由后台任务提前创建）
+DO $$
+DECLARE
+    m DATE;
+    last_month DATE := date_trunc('month', now() AT TIME ZONE 'UTC') + INTERVAL '3 months';
+BEGIN
+    SELECT date_trunc('month', COALESCE(min("started_at"), now()) AT TIME ZONE 'UTC')
+      INTO m FROM "task_attempt_old";
+    WHILE m <= last_month LOOP
+        EXECUTE format(
+            'CREATE TABLE %I PARTITION OF "task_attempt" FOR VALUES FROM (%L) TO (%L)',
+            'task_attempt_p' || to_char(m, 'YYYYMM'),
+            m::timestamp AT TIME ZONE 'UTC',
+            (m + INTERVAL '1 month') AT TIME ZONE 'UTC'
+        );
+        m := m + INTERVAL '1 month';
+    END LOOP;
+END $$;
+
+-- 4. 迁移数据
+INSERT INTO "task_attempt" SELECT * FROM "task_attempt_old";
+DROP TABLE "task_attempt_old";
+
+-- 5. 索引与外键建在分区表上，自动应用到所有现有及后续分区
+CREATE INDEX "idx_attempt_task_started_at_id" ON "task_attempt"("task_id", "started_at" DESC, "id" DESC);
+CREATE INDEX "idx_attempt_status_started_at" ON "task_attempt"("status", "started_at" DESC);
+
+ALTER TABLE "task_attempt" ADD CONSTRAINT "task_attempt_task_id_fkey" FOREIGN KEY ("task_id") REFERENCES "task"("task_id") ON DELETE RESTRICT ON UPDATE CASCADE;
@@ -0,0 +1,3 @@
+-- 迁移：任务过期时间
+-- 到 expires_at 仍未开始执行的任务由 worker 丢弃而不执行，控制面将其标记为 expired（终态）
+ALTER TABLE "task" ADD COLUMN "expires_at" TIMESTAMPTZ(6);
@@ -0,0 +1,25 @@
+-- 迁移：任务出箱（transactional outbox）
+-- 出箱模式下任务记录与出箱记录在同一事务中写入，由投递协程入队到 asynq 后标记 delivered_at，
+-- 保证每个被执行的任务都有对应的任务记录
+CREATE TABLE "task_outbox" (
+    "id" BIGSERIAL NOT NULL,
+    "task_id" TEXT NOT NULL,
+    "process_at" TIMESTAMPTZ(6),
+    "attempts" INTEGER NOT NULL DEFAULT 0,
+    "last_error" TEXT,
+    "next_attempt_at" TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "created_at" TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "delivered_at" TIMESTAMPTZ(6),
+
+    CONSTRAINT "task_outbox_pkey" PRIMARY KEY ("id")
+);
+
+-- 投递协程按 next_attempt_at 扫描未投递的记录
+CREATE INDEX "idx_task_outbox_pending" ON "task_outbox"("next_attempt_at", "id") WHERE "delivered_at" IS NULL;
+CREATE INDEX "idx_task_outbox_task_id" ON "task_outbox"("task_id");
+CREATE INDEX "idx_task_outbox_delivered_at" ON "task_outbox"("delivered_at") WHERE "delivered_at" IS NOT NULL;
+
+ALTER TABLE "task_outbox" ADD CONSTRAINT "task_outbox_task_id_fkey" FOREIGN KEY ("task_id") REFERENCES "task"("task_id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+COMMENT ON COLUMN "task_outbox"."process_at" IS '计划执行时间（延迟/定时任务），为空表示立即执行';
+COMMENT ON COLUMN "task_outbox"."next_attempt_at" IS '下次投递时间，投递失败后按退避推迟';
@@ -0,0 +1,56 @@
+-- 迁移：异步批量操作任务
+-- 1. 批量操作任务：按过滤条件分批处理任务（重试/取消/删除/调整优先级），记录进度与游标以便中断后续跑
+CREATE TABLE "bulk_job" (
+    "id" BIGSERIAL NOT NULL,
+    "job_id" TEXT NOT NULL,
+    "operation" TEXT NOT NULL,
+    "filter" JSONB NOT NULL,
+    "dry_run" BOOLEAN NOT NULL DEFAULT false,
+    "priority" TEXT,
+    "reason" TEXT,
+    "status" TEXT NOT NULL DEFAULT 'pending',
+    "total" INTEGER NOT NULL DEFAULT 0,
+    "processed" INTEGER NOT NULL DEFAULT 0,
+    "succeeded" INTEGER NOT NULL DEFAULT 0,
+    "skipped" INTEGER NOT NULL DEFAULT 0,
+    "failed" INTEGER NOT NULL DEFAULT 0,
+    "cursor_id" BIGINT NOT NULL DEFAULT 0,
+    "max_task_id" BIGINT NOT NULL DEFAULT 0,
+    "error" TEXT,
+    "created_at" TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "started_at" TIMESTAMPTZ(6),
+    "finished_at" TIMESTAMPTZ(6),
+    "updated_at" TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "bulk_job_pkey" PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX "bulk_job_job_id_key" ON "bulk_job"("job_id");
+-- 后台执行器按状态抢占待执行或心跳超时的任务
+CREATE INDEX "idx_bulk_job_status_updated_at" ON "bulk_job"("status", "updated_at");
+
+COMMENT ON COLUMN "bulk_job"."operation" IS 'retry/cancel/delete/change_priority';
+COMMENT ON COLUMN "bulk_job"."filter" IS '任务过滤条件（与任务列表相同，另支持 task_ids）';
+COMMENT ON COLUMN "bulk_job"."priority" IS 'change_priority 的目标优先级：critical/default/low';
+COMMENT ON COLUMN "bulk_job"."reason" IS 'cancel 的取消原因';
+COMMENT ON COLUMN "bulk_job"."status" IS 'pending/running/completed/failed/canceled';
+COMMENT ON COLUMN "bulk_job"."cursor_id" IS '已处理到的 task.id（按 id 升序遍历）';
+COMMENT ON COLUMN "bulk_job"."max_task_id" IS '开始执行时 task.id 的最大值，之后创建的任务（如重试生成的新任务）不参与';
+
+-- 2. 批量操作明细：被跳过或处理失败的任务及原因
+CREATE TABLE "bulk_job_item" (
+    "id" BIGSERIAL NOT NULL,
+    "job_id" TEXT NOT NULL,
+    "task_id" TEXT NOT NULL,
+    "outcome" TEXT NOT NULL,
+    "message" TEXT NOT NULL,
+    "created_at" TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "bulk_job_item_pkey" PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_bulk_job_item_job_id" ON "bulk_job_item"("job_id", "id");
+
+ALTER TABLE "bulk_job_item" ADD CONSTRAINT "bulk_job_item_job_id_fkey" FOREIGN KEY ("job_id") REFERENCES "bulk_job"("job_id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+COMMENT ON COLUMN "bulk_job_item"."outcome" IS 'skipped/failed';
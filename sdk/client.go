@@ -102,6 +102,9 @@ func (c *Client) EnqueueTask(ctx context.Context, req EnqueueTaskRequest) (*Enqu
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
+	if req.IdempotencyKey != "" {
+		httpReq.Header.Set("Idempotency-Key", req.IdempotencyKey)
+	}
 
 	resp, err := c.HTTPClient.Do(httpReq)
 	if err != nil {
@@ -122,6 +125,166 @@ func (c *Client) EnqueueTask(ctx context.Context, req EnqueueTaskRequest) (*Enqu
 	return &result, nil
 }
 
+// EnqueueTasks 批量提交任务（单次 HTTP 请求），逐条返回结果
+func (c *Client) EnqueueTasks(ctx context.Context, reqs []EnqueueTaskRequest) (*BatchEnqueueTaskResponse, error) {
+	return c.EnqueueTasksWithIdempotencyKey(ctx, "", reqs)
+}
+
+// EnqueueTasksWithIdempotencyKey 批量提交任务并携带 Idempotency-Key：
+// 网络失败后使用相同 key 重试整批请求是安全的，控制面会返回首次响应。
+// 批量请求以请求级的 key 为准，单条 EnqueueTaskRequest 上的 IdempotencyKey 会被忽略。
+func (c *Client) EnqueueTasksWithIdempotencyKey(ctx context.Context, idempotencyKey string, reqs []EnqueueTaskRequest) (*BatchEnqueueTaskResponse, error) {
+	url := fmt.Sprintf("%s/api/v1/tasks/batch", c.BaseURL)
+
+	body, err := json.Marshal(map[string]any{"items": reqs})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if idempotencyKey != "" {
+		httpReq.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var result BatchEnqueueTaskResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// ImportTasks 以 NDJSON 流（每行一个任务定义，task_id 必填）导入任务，控制面逐行返回处理结果，每行结果调用一次 fn。
+// task_id 已存在的行返回 skipped 而不会重复入队，因此中断后可以原样重新提交整个文件。
+// 导入耗时与数据量相关，不受 HTTPClient.Timeout 限制，请通过 ctx 控制超时。
+func (c *Client) ImportTasks(ctx context.Context, r io.Reader, fn func(ImportTaskResult) error) error {
+	url := fmt.Sprintf("%s/api/v1/tasks/import", c.BaseURL)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, r)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/x-ndjson")
+
+	httpClient := *c.HTTPClient
+	httpClient.Timeout = 0
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var result ImportTaskResult
+		if err := dec.Decode(&result); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("decode response: %w", err)
+		}
+		if err := fn(result); err != nil {
+			return err
+		}
+	}
+}
+
+// CancelTask 取消任务（删除未执行的任务或中断正在执行的任务）
+func (c *Client) CancelTask(ctx context.Context, taskID, reason string) error {
+	url := fmt.Sprintf("%s/api/v1/tasks/%s/cancel", c.BaseURL, taskID)
+
+	body, err := json.Marshal(map[string]string{"reason": reason})
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	return nil
+}
+
+// GetTaskResult 获取任务结果。wait > 0 时长轮询：任务未结束时服务端最多等待 wait（上限 60s）再返回，
+// 返回值的 Ready 为 false 表示等待期内任务仍未结束。
+func (c *Client) GetTaskResult(ctx context.Context, taskID string, wait time.Duration) (*TaskResult, error) {
+	url := fmt.Sprintf("%s/api/v1/tasks/%s/result", c.BaseURL, taskID)
+	if wait > 0 {
+		url = fmt.Sprintf("%s?wait=%d", url, int(wait.Seconds()))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	// 长轮询期间服务端不会响应，单次请求超时需覆盖等待时长
+	httpClient := *c.HTTPClient
+	if wait > 0 && httpClient.Timeout > 0 {
+		httpClient.Timeout += wait
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result TaskResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// TaskResult 任务结果
+type TaskResult struct {
+	TaskID string          `json:"task_id"`
+	Status string          `json:"status"`
+	Ready  bool            `json:"ready"` // 任务已结束（success/dead/canceled/skipped/expired/deleted/lost）
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
 // EnqueueTaskRequest 任务入队请求
 type EnqueueTaskRequest struct {
 	WorkerName   string          `json:"worker_name"`
@@ -130,6 +293,36 @@ type EnqueueTaskRequest struct {
 	TaskID       string          `json:"task_id,omitempty"`
 	Payload      json.RawMessage `json:"payload"`
 	DelaySeconds int             `json:"delay_seconds,omitempty"`
+
+	// 任务级覆盖参数（不设置则使用 worker 默认配置）
+	MaxRetry         *int       `json:"max_retry,omitempty"` // 0 表示不重试
+	TimeoutSeconds   int        `json:"timeout_seconds,omitempty"`
+	Deadline         *time.Time `json:"deadline,omitempty"`
+	RetentionSeconds int        `json:"retention_seconds,omitempty"`
+
+	// 过期时间（二选一）：到期仍未开始执行的任务不再执行，状态为 expired
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	TTLSeconds int        `json:"ttl_seconds,omitempty"` // 从创建时起的存活时长（秒）
+
+	// 回调任务：本任务成功 / 最终失败（dead/expired）后由控制面自动入队
+	OnSuccess *CallbackTask `json:"on_success,omitempty"`
+	OnFailure *CallbackTask `json:"on_failure,omitempty"`
+
+	// Labels 任务标签（如 tenant、source、batch_id），可在任务列表、统计与批量重试中按标签过滤
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// IdempotencyKey 幂等键（通过 Idempotency-Key 请求头发送）。
+	// 网络失败后使用相同 key 重试是安全的：控制面会返回首次响应而不会重复创建任务。
+	IdempotencyKey string `json:"-"`
+}
+
+// CallbackTask 回调任务定义。
+// Payload 为模板，字符串中的 {{parent_task_id}}、{{parent_status}}、{{parent_error}} 会在入队时被替换。
+type CallbackTask struct {
+	WorkerName string          `json:"worker_name"`
+	Queue      string          `json:"queue"`
+	Priority   string          `json:"priority,omitempty"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
 }
 
 // EnqueueTaskResponse 任务入队响应
@@ -141,3 +334,35 @@ type EnqueueTaskResponse struct {
 	AsynqTaskID string `json:"asynq_task_id"`
 	Status      string `json:"status"`
 }
+
+// BatchEnqueueTaskResult 批量入队中单条任务的结果
+type BatchEnqueueTaskResult struct {
+	Index       int    `json:"index"` // 对应请求中的下标
+	TaskID      string `json:"task_id,omitempty"`
+	AsynqTaskID string `json:"asynq_task_id,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// BatchEnqueueTaskResponse 批量入队响应
+type BatchEnqueueTaskResponse struct {
+	Total     int                      `json:"total"`
+	Succeeded int                      `json:"succeeded"`
+	Failed    int                      `json:"failed"`
+	Items     []BatchEnqueueTaskResult `json:"items"`
+}
+
+// 导入结果状态
+const (
+	ImportStatusCreated = "created" // 已入队
+	ImportStatusSkipped = "skipped" // task_id 已存在，未重复入队
+	ImportStatusFailed  = "failed"  // 校验或入队失败
+)
+
+// ImportTaskResult 导入中单行的处理结果
+type ImportTaskResult struct {
+	Line        int    `json:"line"` // 行号（从 1 开始）
+	TaskID      string `json:"task_id,omitempty"`
+	Status      string `json:"status"` // created, skipped, failed
+	AsynqTaskID string `json:"asynq_task_id,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
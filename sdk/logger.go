@@ -0,0 +1,182 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// logBatchSize 缓冲达到该行数时立即上报
+	logBatchSize = 100
+	// logFlushInterval 长任务定期上报缓冲日志的间隔
+	logFlushInterval = 2 * time.Second
+	// maxLogMessageBytes 单行日志最大长度（与控制面限制一致）
+	maxLogMessageBytes = 8192
+)
+
+// LogLine 单条任务日志
+type LogLine struct {
+	Level   string    `json:"level"` // debug/info/warn/error
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+type ReportLogsRequest struct {
+	Attempt int       `json:"attempt"`
+	Lines   []LogLine `json:"lines"`
+}
+
+// ReportLogs 批量上报任务日志到控制面
+func (r Reporter) ReportLogs(ctx context.Context, taskID string, req ReportLogsRequest) error {
+	if !r.enabled() || len(req.Lines) == 0 {
+		return nil
+	}
+
+	b, _ := json.Marshal(req)
+	u := fmt.Sprintf("%s/api/v1/tasks/%s/logs", r.ControlPlaneURL, taskID)
+	httpReq, _ := http.NewRequestWithContext(ctx, "POST", u, bytes.NewReader(b))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client().Do(httpReq)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("report logs failed: status=%d", resp.StatusCode)
+	}
+	return nil
+}
+
+type loggerCtxKey struct{}
+
+// TaskLogger 任务日志记录器：日志同时输出到本地并缓冲，
+// 按批（满 100 行或每 2 秒）上报到控制面，任务结束时上报剩余日志。
+// 通过 GET /api/v1/tasks/:task_id/logs?attempt= 查看。
+type TaskLogger struct {
+	reporter Reporter
+	taskID   string
+	attempt  int
+
+	sendMu  sync.Mutex // 串行上报，保证批次顺序
+	mu      sync.Mutex
+	buf     []LogLine
+	started bool
+	closed  bool
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+}
+
+func newTaskLogger(reporter Reporter, taskID string, attempt int) *TaskLogger {
+	return &TaskLogger{
+		reporter: reporter,
+		taskID:   taskID,
+		attempt:  attempt,
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+}
+
+func withTaskLogger(ctx context.Context, l *TaskLogger) context.Context {
+	return context.WithValue(ctx, loggerCtxKey{}, l)
+}
+
+// Logger 返回当前任务的日志记录器。
+// ctx 不是由 Worker 传入时返回的记录器只输出到本地，不上报。
+func Logger(ctx context.Context) *TaskLogger {
+	if l, ok := ctx.Value(loggerCtxKey{}).(*TaskLogger); ok {
+		return l
+	}
+	return &TaskLogger{closed: true}
+}
+
+func (l *TaskLogger) Debugf(format string, args ...any) { l.log("debug", format, args...) }
+func (l *TaskLogger) Infof(format string, args ...any)  { l.log("info", format, args...) }
+func (l *TaskLogger) Warnf(format string, args ...any)  { l.log("warn", format, args...) }
+func (l *TaskLogger) Errorf(format string, args ...any) { l.log("error", format, args...) }
+
+func (l *TaskLogger) log(level, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	log.Printf("[%s] task_id=%s attempt=%d %s", level, l.taskID, l.attempt, msg)
+
+	if len(msg) > maxLogMessageBytes {
+		msg = strings.ToValidUTF8(msg[:maxLogMessageBytes], "")
+	}
+
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return
+	}
+	l.buf = append(l.buf, LogLine{Level: level, Message: msg, Time: time.Now()})
+	if !l.started {
+		// 首次写日志时才启动定期上报
+		l.started = true
+		go l.flushLoop()
+	}
+	full := len(l.buf) >= logBatchSize
+	l.mu.Unlock()
+
+	if full {
+		l.flush()
+	}
+}
+
+// flush 上报缓冲中的日志
+func (l *TaskLogger) flush() {
+	l.sendMu.Lock()
+	defer l.sendMu.Unlock()
+
+	l.mu.Lock()
+	batch := l.buf
+	l.buf = nil
+	l.mu.Unlock()
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := l.reporter.ReportLogs(ctx, l.taskID, ReportLogsRequest{Attempt: l.attempt, Lines: batch}); err != nil {
+		log.Printf("上报任务日志失败（丢弃 %d 行）: task_id=%s err=%v", len(batch), l.taskID, err)
+	}
+}
+
+func (l *TaskLogger) flushLoop() {
+	defer close(l.doneCh)
+	ticker := time.NewTicker(logFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stopCh:
+			return
+		case <-ticker.C:
+			l.flush()
+		}
+	}
+}
+
+// close 停止定期上报并上报剩余日志（任务执行结束时调用）
+func (l *TaskLogger) close() {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return
+	}
+	l.closed = true
+	started := l.started
+	l.mu.Unlock()
+
+	if started {
+		close(l.stopCh)
+		<-l.doneCh
+	}
+	l.flush()
+}
@@ -0,0 +1,83 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskLogger_ShipsBatches(t *testing.T) {
+	var mu sync.Mutex
+	var batches []ReportLogsRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/tasks/t1/logs", r.URL.Path)
+		var req ReportLogsRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		mu.Lock()
+		batches = append(batches, req)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	l := newTaskLogger(Reporter{ControlPlaneURL: srv.URL}, "t1", 3)
+	ctx := withTaskLogger(context.Background(), l)
+
+	for i := 0; i < logBatchSize; i++ {
+		Logger(ctx).Infof("line %d", i)
+	}
+	Logger(ctx).Errorf("boom")
+	l.close()
+	Logger(ctx).Infof("关闭后不再上报")
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, batches, 2, "满一批立即上报，剩余的在结束时上报")
+	assert.Len(t, batches[0].Lines, logBatchSize)
+	assert.Equal(t, 3, batches[0].Attempt)
+	assert.Equal(t, "line 0", batches[0].Lines[0].Message)
+	require.Len(t, batches[1].Lines, 1)
+	assert.Equal(t, LogLine{Level: "error", Message: "boom", Time: batches[1].Lines[0].Time}, batches[1].Lines[0])
+}
+
+func TestLogger_OutsideHandler(t *testing.T) {
+	assert.NotPanics(t, func() { Logger(context.Background()).Warnf("local only") })
+}
+
+func TestRegisterHandler_FlushesLogsOnPanic(t *testing.T) {
+	var mu sync.Mutex
+	var lines []LogLine
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/logs") {
+			return
+		}
+		var req ReportLogsRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		mu.Lock()
+		lines = append(lines, req.Lines...)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	w := &Worker{workerName: "w", reporter: Reporter{ControlPlaneURL: srv.URL}}
+	qg := &QueueGroup{Mux: asynq.NewServeMux(), handlers: make(map[string]struct{})}
+	w.registerHandler(qg, "default", "default", func(ctx context.Context, _ *asynq.Task) ([]byte, error) {
+		Logger(ctx).Infof("before panic")
+		panic("boom")
+	})
+
+	task := asynq.NewTask("w:default:default", []byte(`{"task_id":"t1","payload":{}}`))
+	assert.Panics(t, func() { _ = qg.Mux.ProcessTask(context.Background(), task) })
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, lines, 1, "处理器 panic 时仍上报已缓冲的日志")
+	assert.Equal(t, "before panic", lines[0].Message)
+}
@@ -0,0 +1,152 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultProgressInterval 两次进度上报的最小间隔
+const DefaultProgressInterval = time.Second
+
+type ReportProgressRequest struct {
+	Attempt int    `json:"attempt"`
+	Percent int    `json:"percent"` // 0-100
+	Message string `json:"message,omitempty"`
+}
+
+// ReportProgress 上报任务执行进度到控制面
+func (r Reporter) ReportProgress(ctx context.Context, taskID string, req ReportProgressRequest) error {
+	if !r.enabled() {
+		return nil
+	}
+
+	b, _ := json.Marshal(req)
+	u := fmt.Sprintf("%s/api/v1/tasks/%s/progress", r.ControlPlaneURL, taskID)
+	httpReq, _ := http.NewRequestWithContext(ctx, "POST", u, bytes.NewReader(b))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client().Do(httpReq)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode == http.StatusConflict {
+		return ErrTaskCanceled
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("report progress failed: status=%d", resp.StatusCode)
+	}
+	return nil
+}
+
+type progressCtxKey struct{}
+
+// progressTracker 单次执行的进度上报器（由 registerHandler 放入 handler 的 ctx）。
+// 间隔内的更新只保留最新一条，在间隔到期或任务结束（close）时补发，保证控制面看到最后的进度。
+type progressTracker struct {
+	reporter Reporter
+	taskID   string
+	attempt  int
+	interval time.Duration
+
+	sendMu   sync.Mutex // 串行上报，避免补发的旧进度覆盖新进度
+	mu       sync.Mutex
+	lastSent time.Time
+	pending  *ReportProgressRequest
+	timer    *time.Timer
+	canceled bool // 补发时控制面返回任务已取消
+	closed   bool
+}
+
+func withProgressTracker(ctx context.Context, p *progressTracker) context.Context {
+	return context.WithValue(ctx, progressCtxKey{}, p)
+}
+
+// ReportProgress 在 handler 内上报当前任务的执行进度（percent 取值 0-100）。
+// 距上次上报不足间隔（默认 1 秒，见 WithProgressInterval）的更新只保留最新一条，
+// 在间隔到期或 handler 返回时补发；percent 为 100 时总是立即上报。
+// 控制面已取消任务时返回 ErrTaskCanceled，handler 可据此提前退出；ctx 不是由 Worker 传入时为空操作。
+func ReportProgress(ctx context.Context, percent int, message string) error {
+	p, ok := ctx.Value(progressCtxKey{}).(*progressTracker)
+	if !ok {
+		return nil
+	}
+	req := ReportProgressRequest{
+		Attempt: p.attempt,
+		Percent: max(0, min(percent, 100)),
+		Message: message,
+	}
+
+	p.mu.Lock()
+	if p.canceled {
+		p.mu.Unlock()
+		return ErrTaskCanceled
+	}
+	if wait := p.interval - time.Since(p.lastSent); req.Percent < 100 && wait > 0 {
+		if !p.closed {
+			p.pending = &req
+			if p.timer == nil {
+				p.timer = time.AfterFunc(wait, p.flush)
+			}
+		}
+		p.mu.Unlock()
+		return nil
+	}
+	p.mu.Unlock()
+
+	p.sendMu.Lock()
+	defer p.sendMu.Unlock()
+	p.mu.Lock()
+	p.lastSent = time.Now()
+	p.pending = nil // 已被本次更新取代
+	p.mu.Unlock()
+	return p.reporter.ReportProgress(ctx, p.taskID, req)
+}
+
+// flush 补发间隔内被暂缓的最新进度
+func (p *progressTracker) flush() {
+	p.sendMu.Lock()
+	defer p.sendMu.Unlock()
+
+	p.mu.Lock()
+	req := p.pending
+	p.pending = nil
+	p.timer = nil
+	if req != nil {
+		p.lastSent = time.Now()
+	}
+	p.mu.Unlock()
+	if req == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := p.reporter.ReportProgress(ctx, p.taskID, *req)
+	if errors.Is(err, ErrTaskCanceled) {
+		// 补发时无法直接返回给 handler，在下一次 ReportProgress 时返回
+		p.mu.Lock()
+		p.canceled = true
+		p.mu.Unlock()
+	} else if err != nil {
+		log.Printf("上报任务进度失败: task_id=%s err=%v", p.taskID, err)
+	}
+}
+
+// close 停止补发定时器并上报暂缓的最新进度（任务执行结束时调用）
+func (p *progressTracker) close() {
+	p.mu.Lock()
+	p.closed = true
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.mu.Unlock()
+	p.flush()
+}
@@ -0,0 +1,137 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportProgress_RateLimited(t *testing.T) {
+	var mu sync.Mutex
+	var got []ReportProgressRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/tasks/t1/progress", r.URL.Path)
+		var req ReportProgressRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		mu.Lock()
+		got = append(got, req)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	ctx := withProgressTracker(context.Background(), &progressTracker{
+		reporter: Reporter{ControlPlaneURL: srv.URL},
+		taskID:   "t1",
+		attempt:  2,
+		interval: time.Hour,
+	})
+
+	require.NoError(t, ReportProgress(ctx, 10, "a"))
+	require.NoError(t, ReportProgress(ctx, 20, "b"))  // 间隔内丢弃
+	require.NoError(t, ReportProgress(ctx, 120, "c")) // 截断为 100，总是上报
+
+	require.Len(t, got, 2)
+	assert.Equal(t, ReportProgressRequest{Attempt: 2, Percent: 10, Message: "a"}, got[0])
+	assert.Equal(t, ReportProgressRequest{Attempt: 2, Percent: 100, Message: "c"}, got[1])
+}
+
+// progressServer 记录收到的进度上报
+func progressServer(t *testing.T) (*httptest.Server, func() []ReportProgressRequest) {
+	var mu sync.Mutex
+	var got []ReportProgressRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ReportProgressRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		mu.Lock()
+		got = append(got, req)
+		mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []ReportProgressRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]ReportProgressRequest(nil), got...)
+	}
+}
+
+func TestReportProgress_TrailingUpdate(t *testing.T) {
+	srv, got := progressServer(t)
+	p := &progressTracker{reporter: Reporter{ControlPlaneURL: srv.URL}, taskID: "t1", attempt: 1, interval: 50 * time.Millisecond}
+	ctx := withProgressTracker(context.Background(), p)
+	defer p.close()
+
+	require.NoError(t, ReportProgress(ctx, 10, "a"))
+	require.NoError(t, ReportProgress(ctx, 20, "b"))
+	require.NoError(t, ReportProgress(ctx, 30, "c")) // 只保留最新一条
+
+	require.Eventually(t, func() bool { return len(got()) == 2 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, ReportProgressRequest{Attempt: 1, Percent: 30, Message: "c"}, got()[1], "间隔到期后补发最新进度")
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, got(), 2, "没有新的更新时不再上报")
+}
+
+func TestReportProgress_FlushOnClose(t *testing.T) {
+	srv, got := progressServer(t)
+	p := &progressTracker{reporter: Reporter{ControlPlaneURL: srv.URL}, taskID: "t1", attempt: 1, interval: time.Hour}
+	ctx := withProgressTracker(context.Background(), p)
+
+	require.NoError(t, ReportProgress(ctx, 10, "a"))
+	require.NoError(t, ReportProgress(ctx, 90, "b"))
+	assert.Len(t, got(), 1)
+
+	p.close()
+	assert.Equal(t, []ReportProgressRequest{
+		{Attempt: 1, Percent: 10, Message: "a"},
+		{Attempt: 1, Percent: 90, Message: "b"},
+	}, got(), "任务结束时补发暂缓的进度")
+
+	p.close()
+	assert.Len(t, got(), 2, "重复 close 不重复上报")
+}
+
+func TestReportProgress_Canceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	}))
+	defer srv.Close()
+
+	ctx := withProgressTracker(context.Background(), &progressTracker{
+		reporter: Reporter{ControlPlaneURL: srv.URL},
+		taskID:   "t1",
+		attempt:  1,
+	})
+	assert.ErrorIs(t, ReportProgress(ctx, 50, ""), ErrTaskCanceled)
+}
+
+func TestReportProgress_OutsideHandler(t *testing.T) {
+	assert.NoError(t, ReportProgress(context.Background(), 50, ""))
+}
+
+func TestReportProgress_CanceledOnTrailingUpdate(t *testing.T) {
+	var calls int
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if calls++; calls > 1 {
+			w.WriteHeader(http.StatusConflict)
+		}
+	}))
+	defer srv.Close()
+
+	p := &progressTracker{reporter: Reporter{ControlPlaneURL: srv.URL}, taskID: "t1", attempt: 1, interval: time.Hour}
+	ctx := withProgressTracker(context.Background(), p)
+
+	require.NoError(t, ReportProgress(ctx, 10, ""))
+	require.NoError(t, ReportProgress(ctx, 20, ""))
+	p.flush()
+	assert.ErrorIs(t, ReportProgress(ctx, 30, ""), ErrTaskCanceled, "补发时发现的取消在下一次调用时返回")
+}
@@ -4,11 +4,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 )
 
+// ErrTaskCanceled 控制面拒绝上报（409）：任务已被取消，不应再执行
+var ErrTaskCanceled = errors.New("task canceled by control plane")
+
 type Reporter struct {
 	ControlPlaneURL string
 	HTTPClient      *http.Client
@@ -28,7 +32,7 @@ func (r Reporter) client() *http.Client {
 
 type ReportAttemptRequest struct {
 	Attempt     int        `json:"attempt"`
-	Status      string     `json:"status"` // running/success/fail/dead
+	Status      string     `json:"status"` // running/success/fail/dead/expired
 	AsynqTaskID string     `json:"asynq_task_id,omitempty"`
 	Error       string     `json:"error,omitempty"`
 	WorkerName  string     `json:"worker_name,omitempty"`
@@ -37,6 +41,9 @@ type ReportAttemptRequest struct {
 	DurationMs  *int       `json:"duration_ms,omitempty"`
 	TraceID     string     `json:"trace_id,omitempty"`
 	SpanID      string     `json:"span_id,omitempty"`
+
+	// Result 任务结果（仅 success 时上报，由 ResultHandlerFunc 返回）
+	Result json.RawMessage `json:"result,omitempty"`
 }
 
 func (r Reporter) ReportAttempt(ctx context.Context, taskID string, req ReportAttemptRequest) error {
@@ -57,6 +64,9 @@ func (r Reporter) ReportAttempt(ctx context.Context, taskID string, req ReportAt
 		return err
 	}
 	_ = resp.Body.Close()
+	if resp.StatusCode == http.StatusConflict {
+		return ErrTaskCanceled
+	}
 	if resp.StatusCode >= 400 {
 		return fmt.Errorf("report attempt failed: status=%d", resp.StatusCode)
 	}
@@ -8,4 +8,21 @@ const (
 	TaskStatusSuccess TaskStatus = "success"
 	TaskStatusFail    TaskStatus = "fail"
 	TaskStatusDead    TaskStatus = "dead"
+	// TaskStatusExpired 任务到过期时间仍未开始执行，worker 丢弃而不执行
+	TaskStatusExpired TaskStatus = "expired"
+
+	// 以下状态仅由控制面写入，worker 不需要上报
+	// TaskStatusCanceled 任务被取消
+	TaskStatusCanceled TaskStatus = "canceled"
+	// TaskStatusWaiting 工作流任务等待上游完成
+	TaskStatusWaiting TaskStatus = "waiting"
+	// TaskStatusSkipped 工作流上游失败，任务被跳过
+	TaskStatusSkipped TaskStatus = "skipped"
+	// TaskStatusDeleted 对账发现任务已不在 Redis 中且从未执行
+	TaskStatusDeleted TaskStatus = "deleted"
+	// TaskStatusLost 对账发现任务已执行但不在 Redis 中，且未收到最终执行结果；
+	// 也用于失联检测判定 worker 已失联的执行尝试
+	TaskStatusLost TaskStatus = "lost"
+	// TaskStatusTimeout 执行尝试超过超时时间仍未上报结果（仅用于执行尝试）
+	TaskStatusTimeout TaskStatus = "timeout"
 )
@@ -2,14 +2,22 @@ package sdk
 
 import (
 	"encoding/json"
+	"time"
 )
 
 // Task：SDK 任务结构体
 // - TaskID 是任务的业务唯一标识（对应数据库 task_id 字段）
 // - Payload 是业务 payload（JSON 原文）
+// - ExpiresAt 过期时间（可选），到期仍未开始执行的任务会被丢弃而不执行处理器
 type Task struct {
-	TaskID  string          `json:"task_id,omitempty"`
-	Payload json.RawMessage `json:"payload"`
+	TaskID    string          `json:"task_id,omitempty"`
+	Payload   json.RawMessage `json:"payload"`
+	ExpiresAt *time.Time      `json:"expires_at,omitempty"`
+}
+
+// expired 任务在 now 时是否已过期
+func (t *Task) expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
 }
 
 func (t *Task) UnmarshalPayload(v any) error {
@@ -139,6 +139,8 @@ type Worker struct {
 	autoRegister bool
 	overwriteReg bool
 
+	progressInterval time.Duration
+
 	client *asynq.Client
 
 	reporter  Reporter
@@ -161,6 +163,9 @@ func WithDefaultDelay(d time.Duration) Option   { return func(w *Worker) { w.def
 func WithoutAutoRegister() Option               { return func(w *Worker) { w.autoRegister = false } }
 func WithRegisterOverwrite() Option             { return func(w *Worker) { w.overwriteReg = true } }
 
+// WithProgressInterval 设置 ReportProgress 两次上报的最小间隔（默认 1 秒）
+func WithProgressInterval(d time.Duration) Option { return func(w *Worker) { w.progressInterval = d } }
+
 // WithQueueGroups 配置队列组
 func WithQueueGroups(groups []QueueGroupConfig) Option {
 	return func(w *Worker) {
@@ -199,6 +204,7 @@ func New(workerName string, opts ...Option) (*Worker, error) {
 		defaultDelay:      0,
 		autoRegister:      true,
 		overwriteReg:      false,
+		progressInterval:  DefaultProgressInterval,
 	}
 	for _, o := range opts {
 		o(w)
@@ -282,9 +288,24 @@ func (w *Worker) parseQueueName(queueGroup string) (string, string) {
 	return queueGroup, PriorityDefault
 }
 
+// ResultHandlerFunc 返回结果的处理器：成功时返回的结果随 success 上报存入控制面，
+// 调用方通过 GET /api/v1/tasks/:task_id/result 获取。
+// 结果不是合法 JSON 时按字符串保存。
+type ResultHandlerFunc func(ctx context.Context, t *asynq.Task) ([]byte, error)
+
 // HandleFunc 为队列组注册处理器（处理所有优先级）
 // SDK 会在外层自动上报 attempt/状态。
 func (w *Worker) HandleFunc(queueGroup string, fn func(ctx context.Context, t *asynq.Task) error) {
+	w.HandleResultFunc(queueGroup, withoutResult(fn))
+}
+
+// HandleFuncWithPriority 为队列组的特定优先级注册处理器
+func (w *Worker) HandleFuncWithPriority(queueGroup, priority string, fn func(ctx context.Context, t *asynq.Task) error) {
+	w.HandleResultFuncWithPriority(queueGroup, priority, withoutResult(fn))
+}
+
+// HandleResultFunc 为队列组注册返回结果的处理器（处理所有优先级）
+func (w *Worker) HandleResultFunc(queueGroup string, fn ResultHandlerFunc) {
 	qg, ok := w.queueGroups[queueGroup]
 	if !ok {
 		log.Printf("警告: 队列组 %s 不存在，忽略注册", queueGroup)
@@ -297,8 +318,8 @@ func (w *Worker) HandleFunc(queueGroup string, fn func(ctx context.Context, t *a
 	}
 }
 
-// HandleFuncWithPriority 为队列组的特定优先级注册处理器
-func (w *Worker) HandleFuncWithPriority(queueGroup, priority string, fn func(ctx context.Context, t *asynq.Task) error) {
+// HandleResultFuncWithPriority 为队列组的特定优先级注册返回结果的处理器
+func (w *Worker) HandleResultFuncWithPriority(queueGroup, priority string, fn ResultHandlerFunc) {
 	qg, ok := w.queueGroups[queueGroup]
 	if !ok {
 		log.Printf("警告: 队列组 %s 不存在，忽略注册", queueGroup)
@@ -313,8 +334,15 @@ func (w *Worker) HandleFuncWithPriority(queueGroup, priority string, fn func(ctx
 	w.registerHandler(qg, queueGroup, priority, fn)
 }
 
+// withoutResult 将只返回 error 的处理器适配为 ResultHandlerFunc
+func withoutResult(fn func(ctx context.Context, t *asynq.Task) error) ResultHandlerFunc {
+	return func(ctx context.Context, t *asynq.Task) ([]byte, error) {
+		return nil, fn(ctx, t)
+	}
+}
+
 // registerHandler 内部方法：注册处理器
-func (w *Worker) registerHandler(qg *QueueGroup, queueGroup, priority string, fn func(ctx context.Context, t *asynq.Task) error) {
+func (w *Worker) registerHandler(qg *QueueGroup, queueGroup, priority string, fn ResultHandlerFunc) {
 	fullQueueName := w.fullQueueName(queueGroup, priority)
 
 	// 检查是否已注册
@@ -341,39 +369,125 @@ func (w *Worker) registerHandler(qg *QueueGroup, queueGroup, priority string, fn
 		attemptNo := retryCount + 1
 		start := time.Now()
 
-		_ = w.reporter.ReportAttempt(ctx, taskID, ReportAttemptRequest{
+		// 到过期时间仍未开始执行（含等待重试的任务）：不执行处理器，上报 expired 后撤销（不再重试、不归档）
+		if task.expired(start) {
+			dur := 0
+			_ = w.reporter.ReportAttempt(ctx, taskID, ReportAttemptRequest{
+				Attempt:     attemptNo,
+				Status:      string(TaskStatusExpired),
+				AsynqTaskID: asynqID,
+				Error:       fmt.Sprintf("任务已于 %s 过期", task.ExpiresAt.Format(time.RFC3339)),
+				WorkerName:  w.workerName,
+				StartedAt:   &start,
+				FinishedAt:  &start,
+				DurationMs:  &dur,
+			})
+			log.Printf("任务 %s 已过期，跳过执行", taskID)
+			return fmt.Errorf("task %s expired: %w", taskID, asynq.RevokeTask)
+		}
+
+		err := w.reporter.ReportAttempt(ctx, taskID, ReportAttemptRequest{
 			Attempt:     attemptNo,
 			Status:      string(TaskStatusRunning),
 			AsynqTaskID: asynqID,
 			WorkerName:  w.workerName,
 			StartedAt:   &start,
 		})
+		if errors.Is(err, ErrTaskCanceled) {
+			// 任务已在控制面取消（例如取消时正在执行而被 asynq 放入重试），撤销而不再执行
+			log.Printf("任务 %s 已取消，跳过执行", taskID)
+			return fmt.Errorf("task %s: %w", taskID, asynq.RevokeTask)
+		}
 
-		err := fn(ctx, t)
+		progress := &progressTracker{
+			reporter: w.reporter,
+			taskID:   taskID,
+			attempt:  attemptNo,
+			interval: w.progressInterval,
+		}
+		ctx = withProgressTracker(ctx, progress)
+		taskLogger := newTaskLogger(w.reporter, taskID, attemptNo)
+		ctx = withTaskLogger(ctx, taskLogger)
+		result, err := func() ([]byte, error) {
+			// 处理器 panic 时同样停止后台刷新并上报已缓冲的日志与暂缓的进度；正常返回时在最终上报前完成
+			defer taskLogger.close()
+			defer progress.close()
+			return fn(ctx, t)
+		}()
+		if err == nil && result != nil {
+			// 同时写入 asynq（配合 retention_seconds 可在 asynq 侧查看）
+			if rw := t.ResultWriter(); rw != nil {
+				if _, werr := rw.Write(result); werr != nil {
+					log.Printf("写入任务结果到 asynq 失败: task_id=%s err=%v", taskID, werr)
+				}
+			}
+		}
 
 		finished := time.Now()
 		dur := int(finished.Sub(start).Milliseconds())
-		status := string(TaskStatusSuccess)
+		status := attemptStatus(ctx, err)
 		errMsg := ""
 		if err != nil {
-			status = string(TaskStatusFail)
 			errMsg = err.Error()
 		}
 		_ = w.reporter.ReportAttempt(ctx, taskID, ReportAttemptRequest{
 			Attempt:     attemptNo,
-			Status:      status,
+			Status:      string(status),
 			AsynqTaskID: asynqID,
 			Error:       errMsg,
 			WorkerName:  w.workerName,
 			StartedAt:   &start,
 			FinishedAt:  &finished,
 			DurationMs:  &dur,
+			Result:      resultJSON(result),
 		})
 
 		return err
 	})
 }
 
+// resultJSON 将处理器返回的结果转换为 JSON：合法 JSON 原样使用，否则按字符串编码
+func resultJSON(result []byte) json.RawMessage {
+	if result == nil {
+		return nil
+	}
+	if json.Valid(result) {
+		return json.RawMessage(result)
+	}
+	b, _ := json.Marshal(string(result))
+	return b
+}
+
+// attemptStatus 根据处理结果与 asynq 上下文中的重试信息判断本次尝试的最终状态
+func attemptStatus(ctx context.Context, err error) TaskStatus {
+	retried, hasRetry := asynq.GetRetryCount(ctx)
+	maxRetry, hasMax := asynq.GetMaxRetry(ctx)
+	if !hasMax {
+		maxRetry = -1
+	}
+	if !hasRetry {
+		retried = -1
+	}
+	return resolveAttemptStatus(err, retried, maxRetry)
+}
+
+// resolveAttemptStatus 判断本次尝试的最终状态：
+// 重试次数已耗尽，或 handler 返回 asynq.SkipRetry/asynq.RevokeTask 时，
+// asynq 不会再执行该任务，返回 dead；否则失败返回 fail（等待重试）。
+// retried/maxRetry 为负数表示上下文中没有对应信息，此时按可重试处理。
+func resolveAttemptStatus(err error, retried, maxRetry int) TaskStatus {
+	if err == nil {
+		return TaskStatusSuccess
+	}
+	if errors.Is(err, asynq.SkipRetry) || errors.Is(err, asynq.RevokeTask) {
+		return TaskStatusDead
+	}
+	if retried >= 0 && maxRetry >= 0 && retried >= maxRetry {
+		return TaskStatusDead
+	}
+	return TaskStatusFail
+}
+
 // Enqueue 入队到默认优先级（default）
 func (w *Worker) Enqueue(queueGroup string, task *Task) {
 	w.EnqueueWithPriority(queueGroup, PriorityDefault, task)
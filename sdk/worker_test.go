@@ -0,0 +1,53 @@
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveAttemptStatus(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	tests := []struct {
+		name     string
+		err      error
+		retried  int
+		maxRetry int
+		want     TaskStatus
+	}{
+		{name: "成功", err: nil, retried: 3, maxRetry: 3, want: TaskStatusSuccess},
+		{name: "首次失败可重试", err: errBoom, retried: 0, maxRetry: 3, want: TaskStatusFail},
+		{name: "最后一次重试前失败", err: errBoom, retried: 2, maxRetry: 3, want: TaskStatusFail},
+		{name: "重试次数耗尽", err: errBoom, retried: 3, maxRetry: 3, want: TaskStatusDead},
+		{name: "不重试的任务失败", err: errBoom, retried: 0, maxRetry: 0, want: TaskStatusDead},
+		{name: "SkipRetry", err: fmt.Errorf("bad payload: %w", asynq.SkipRetry), retried: 0, maxRetry: 3, want: TaskStatusDead},
+		{name: "RevokeTask", err: asynq.RevokeTask, retried: 0, maxRetry: 3, want: TaskStatusDead},
+		{name: "缺少重试次数", err: errBoom, retried: -1, maxRetry: 3, want: TaskStatusFail},
+		{name: "缺少最大重试次数", err: errBoom, retried: 5, maxRetry: -1, want: TaskStatusFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, resolveAttemptStatus(tt.err, tt.retried, tt.maxRetry))
+		})
+	}
+}
+
+func TestTaskExpired(t *testing.T) {
+	var task Task
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"t1","task_id":"t1","payload":{},"expires_at":"2026-03-10T12:00:00Z"}`), &task))
+	expiresAt := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	assert.False(t, task.expired(expiresAt.Add(-time.Second)))
+	assert.True(t, task.expired(expiresAt), "到达过期时间即视为过期")
+	assert.True(t, task.expired(expiresAt.Add(time.Hour)))
+
+	assert.False(t, (&Task{TaskID: "t2"}).expired(expiresAt), "未设置过期时间的任务永不过期")
+}
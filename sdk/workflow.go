@@ -0,0 +1,100 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// WorkflowTask 工作流中的单个任务
+// DependsOn 填写同一工作流内上游任务的 TaskID，上游全部成功后该任务才会入队。
+type WorkflowTask struct {
+	EnqueueTaskRequest
+	DependsOn []string `json:"depends_on,omitempty"`
+}
+
+// SubmitWorkflowRequest 提交工作流请求
+type SubmitWorkflowRequest struct {
+	Name  string         `json:"name"`
+	Tasks []WorkflowTask `json:"tasks"`
+}
+
+// WorkflowInfo 工作流信息
+type WorkflowInfo struct {
+	WorkflowID string    `json:"workflow_id"`
+	Name       string    `json:"name"`
+	Status     string    `json:"status"` // running/success/failed
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// WorkflowTaskInfo 工作流中任务的当前状态
+type WorkflowTaskInfo struct {
+	TaskID     string   `json:"task_id"`
+	WorkerName string   `json:"worker_name"`
+	Queue      string   `json:"queue"`
+	Status     string   `json:"status"`
+	DependsOn  []string `json:"depends_on,omitempty"`
+	LastError  string   `json:"last_error,omitempty"`
+}
+
+// WorkflowResponse 工作流详情
+type WorkflowResponse struct {
+	Item     WorkflowInfo       `json:"item"`
+	Tasks    []WorkflowTaskInfo `json:"tasks"`
+	Progress map[string]int     `json:"progress"`
+}
+
+// SubmitWorkflow 提交工作流（一组通过 DependsOn 组成 DAG 的任务）
+func (c *Client) SubmitWorkflow(ctx context.Context, req SubmitWorkflowRequest) (*WorkflowResponse, error) {
+	url := fmt.Sprintf("%s/api/v1/workflows", c.BaseURL)
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	return c.doWorkflowRequest(httpReq)
+}
+
+// GetWorkflow 查询工作流及其任务状态
+func (c *Client) GetWorkflow(ctx context.Context, workflowID string) (*WorkflowResponse, error) {
+	url := fmt.Sprintf("%s/api/v1/workflows/%s", c.BaseURL, workflowID)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	return c.doWorkflowRequest(httpReq)
+}
+
+func (c *Client) doWorkflowRequest(httpReq *http.Request) (*WorkflowResponse, error) {
+	resp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var result WorkflowResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return &result, nil
+}